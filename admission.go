@@ -0,0 +1,80 @@
+package bicache
+
+// admissionSamples is the number of entries cost-benefit admission compares a new key with.
+const admissionSamples = 16
+
+// Admission selects how new keys are admitted when the cache is full.
+type Admission int
+
+const (
+	// AdmitAll stores every new key.
+	AdmitAll Admission = iota
+	// CostBenefitAdmission admits a new key only if its miss penalty per byte is at least
+	// as high as that of the cheapest of a few randomly sampled entries, which is then removed.
+	CostBenefitAdmission
+	// TinyLFUAdmission lets new keys into a full cache through a small window and keeps
+	// whichever of the window's oldest key and the eviction victim is accessed more often,
+	// so keys read only once don't push out frequently read entries. It enables the frequency sketch.
+	TinyLFUAdmission
+)
+
+func (c *BiCache) SetAdmission(admission Admission) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.admission = admission
+	c.measure()
+	c.window = newLRUList()
+	if admission == TinyLFUAdmission && c.sketch == nil {
+		c.sketch = newCountMinSketch(c.capacity, c.hasher)
+	}
+}
+
+// admit decides whether a new entry may be stored and makes room for it if needed.
+func (c *BiCache) admit(key interface{}, entry CacheEntry) bool {
+	if c.admission == AdmitAll {
+		return true
+	}
+
+	// Updates and inserts into a cache with free space are always admitted
+	if _, exists := c.cacheMap[c.mapKey(key)]; exists || c.fits(entry) {
+		return true
+	}
+
+	if c.admission == TinyLFUAdmission {
+		return c.admitTinyLFU(key)
+	}
+
+	// Find the sampled entry that is cheapest to recompute per byte, relying on the random start
+	// of map iteration like sampled eviction
+	var victimKey interface{}
+	var victimScore float64
+	found := false
+	samples := 0
+	for k, e := range c.cacheMap {
+		if samples >= admissionSamples {
+			break
+		}
+		samples++
+		score := costPerByte(e)
+		if !found || score < victimScore {
+			victimKey, victimScore, found = k, score, true
+		}
+	}
+
+	if !found || costPerByte(entry) < victimScore {
+		return false
+	}
+
+	c.evictEntry(victimKey)
+	return true
+}
+
+// costPerByte returns the miss penalty of an entry divided by its estimated memory.
+func costPerByte(entry CacheEntry) float64 {
+	size := entry.memory
+	if size < 1 {
+		size = 1
+	}
+	return float64(entry.cost) / float64(size)
+}
@@ -0,0 +1,74 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_CostBenefitAdmission(t *testing.T) {
+	cache := NewBiCache(2, time.Second)
+	cache.SetAdmission(CostBenefitAdmission)
+
+	// Fill the cache with one cheap and one expensive entry
+	cache.Set("cheap", "value", time.Second*20)
+	cache.Get("expensive")
+	time.Sleep(time.Millisecond * 20)
+	cache.Set("expensive", "value", time.Second*20)
+
+	// A new entry without a measured cost only replaces the cheap entry
+	cache.Set("new", "value", time.Second*20)
+
+	if _, found := cache.Get("cheap"); found {
+		t.Errorf("CostBenefitAdmission test failed. Expected: 'cheap' evicted")
+	}
+	if _, found := cache.Get("expensive"); !found {
+		t.Errorf("CostBenefitAdmission test failed. Expected: 'expensive' kept")
+	}
+
+	// A large entry with no cost is not worth more than the cheapest remaining entry
+	cache.Get("costly")
+	time.Sleep(time.Millisecond * 20)
+	cache.Set("costly", "value", time.Second*20)
+	cache.Set("big", make([]byte, 1024), time.Second*20)
+
+	if _, found := cache.Get("big"); found {
+		t.Errorf("CostBenefitAdmission test failed. Expected: 'big' rejected")
+	}
+	if metrics := cache.GetMetrics(); metrics.AdmissionRejected != 1 {
+		t.Errorf("CostBenefitAdmission test failed. Expected: AdmissionRejected=1, Got: %v", metrics.AdmissionRejected)
+	}
+}
+
+func TestBiCache_TinyLFUAdmission(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.EnableFrequencySketch(1024)
+	cache.SetAdmission(TinyLFUAdmission)
+
+	for i := 0; i < 10; i++ {
+		cache.Set(i, "value", 0)
+		for j := 0; j < 5; j++ {
+			cache.Get(i)
+		}
+	}
+
+	// A scan of keys read once only cycles through the admission window
+	for i := 100; i < 120; i++ {
+		cache.Set(i, "value", 0)
+	}
+
+	kept := 0
+	for i := 0; i < 10; i++ {
+		if _, found := cache.Get(i); found {
+			kept++
+		}
+	}
+	if kept != 9 {
+		t.Errorf("TinyLFUAdmission test failed. Expected: 9 frequent keys kept, Got: %v", kept)
+	}
+	if _, found := cache.Get(119); !found {
+		t.Errorf("TinyLFUAdmission test failed. Expected: newest key in the admission window")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 10 {
+		t.Errorf("TinyLFUAdmission test failed. Expected: 10 entries, Got: %v", metrics.EntriesCount)
+	}
+}
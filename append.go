@@ -0,0 +1,76 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotAppendable is returned by Append and Prepend when the stored value is neither a []byte nor a string.
+var ErrNotAppendable = errors.New("bicache: value is not a byte slice or string")
+
+// Append adds data to the end of the []byte or string value stored under key, keeping its
+// expiration, tags and metadata. Like memcached's append it doesn't create missing entries:
+// it returns ErrNotFound if key holds no live entry. The read and the write happen under one
+// lock, so concurrent appends are not lost.
+func (c *BiCache) Append(key interface{}, data []byte) error {
+	return c.extend(key, func(value []byte) []byte {
+		return append(value, data...)
+	})
+}
+
+// Prepend is like Append, adding data to the start of the value.
+func (c *BiCache) Prepend(key interface{}, data []byte) error {
+	return c.extend(key, func(value []byte) []byte {
+		return append(append([]byte(nil), data...), value...)
+	})
+}
+
+// extend replaces the []byte or string value stored under key with the result of extend.
+func (c *BiCache) extend(key interface{}, extend func(value []byte) []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, now) {
+		return ErrNotFound
+	}
+	current, err := c.decodeStored(entry.Value)
+	if err != nil {
+		c.metrics.DecodeError.Add(1)
+		return err
+	}
+
+	var value interface{}
+	switch v := current.(type) {
+	case []byte:
+		// Copy the value, readers may still hold the stored slice
+		value = extend(append([]byte(nil), v...))
+	case string:
+		value = string(extend([]byte(v)))
+	default:
+		return ErrNotAppendable
+	}
+
+	var expiration time.Duration
+	if !entry.Expiration.IsZero() {
+		expiration = entry.Expiration.Sub(now)
+	}
+	options := newSetOptions([]SetOption{WithMetadata(entry.Metadata), WithTags(entry.Tags...), WithToken(entry.Token), WithWriter(entry.Writer)})
+	return c.store(context.Background(), key, value, expiration, options)
+}
+
+// Append adds data to the end of the value stored under key.
+func (c *ShardedBiCache) Append(key interface{}, data []byte) error {
+	return c.Shard(key).Append(key, data)
+}
+
+// Prepend adds data to the start of the value stored under key.
+func (c *ShardedBiCache) Prepend(key interface{}, data []byte) error {
+	return c.Shard(key).Prepend(key, data)
+}
@@ -0,0 +1,58 @@
+package bicache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_AppendPrepend(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	if err := cache.Append("missing", []byte("data")); err != ErrNotFound || cache.Has("missing") {
+		t.Errorf("Append test failed. Expected: %v, Got: %v", ErrNotFound, err)
+	}
+
+	cache.SetWithOptions("log", "b", time.Hour, WithTags("tag1"))
+	cache.Append("log", []byte("c"))
+	cache.Prepend("log", []byte("a"))
+	if value, _ := cache.Get("log"); value != "abc" {
+		t.Errorf("Append test failed. Expected: abc, Got: %v", value)
+	}
+	if info, _ := cache.GetEntryInfo("log"); len(info.Tags) != 1 || info.Expiration.IsZero() {
+		t.Errorf("Append test failed. Expected: tags and expiration kept, Got: %+v", info)
+	}
+
+	cache.Set("bytes", []byte("12"), 0)
+	cache.Append("bytes", []byte("3"))
+	if value, _ := cache.Get("bytes"); string(value.([]byte)) != "123" {
+		t.Errorf("Append test failed. Expected: 123, Got: %s", value)
+	}
+
+	cache.Set("number", 1, 0)
+	if err := cache.Append("number", []byte("2")); err != ErrNotAppendable {
+		t.Errorf("Append test failed. Expected: %v, Got: %v", ErrNotAppendable, err)
+	}
+	cache.SetWithOptions("locked", "a", 0, WithReadOnly())
+	var readOnlyErr *ReadOnlyError
+	if err := cache.Append("locked", []byte("b")); !errors.As(err, &readOnlyErr) {
+		t.Errorf("Append test failed. Expected: a ReadOnlyError, Got: %v", err)
+	}
+
+	// Concurrent appends are not lost
+	cache.Set("counter", "", 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Append("counter", []byte("x"))
+		}()
+	}
+	wg.Wait()
+
+	if value, _ := cache.Get("counter"); len(value.(string)) != 50 {
+		t.Errorf("Append test failed. Expected: 50 bytes, Got: %d", len(value.(string)))
+	}
+}
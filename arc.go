@@ -0,0 +1,150 @@
+package bicache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// ARC segments: recently used once, used more than once, and the ghosts of keys evicted from each.
+const (
+	arcT1 = iota
+	arcT2
+	arcB1
+	arcB2
+)
+
+// arc is an Adaptive Replacement Cache. Keys seen once live in T1 and keys seen again in T2.
+// Removed keys are remembered in the ghost lists B1 and B2; a new key found in a ghost list
+// shifts the target size p of T1 towards recency (B1) or frequency (B2).
+type arc struct {
+	mu       sync.Mutex
+	lists    [4]*list.List
+	elements map[interface{}]*list.Element
+	capacity int
+	p        int
+}
+
+type arcItem struct {
+	key     interface{}
+	segment int
+}
+
+func newARC(capacity int) *arc {
+	a := &arc{elements: make(map[interface{}]*list.Element)}
+	for i := range a.lists {
+		a.lists[i] = list.New()
+	}
+	a.setCapacity(capacity)
+	return a
+}
+
+func (a *arc) setCapacity(capacity int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.capacity = capacity
+	if a.p > capacity {
+		a.p = capacity
+	}
+	a.trimGhosts()
+}
+
+func (a *arc) add(key interface{}) {
+	a.touch(key)
+}
+
+// touch moves cached keys to T2, adapts p for keys found in a ghost list and puts new keys in T1.
+func (a *arc) touch(key interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	element, exists := a.elements[key]
+	if !exists {
+		a.move(key, arcT1)
+		return
+	}
+
+	b1, b2 := a.lists[arcB1].Len(), a.lists[arcB2].Len()
+	switch element.Value.(*arcItem).segment {
+	case arcB1:
+		a.p = minInt(a.capacity, a.p+maxInt(b2/maxInt(b1, 1), 1))
+	case arcB2:
+		a.p = maxInt(0, a.p-maxInt(b1/maxInt(b2, 1), 1))
+	}
+	a.move(key, arcT2)
+}
+
+// remove turns a cached key into a ghost.
+func (a *arc) remove(key interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	element, exists := a.elements[key]
+	if !exists {
+		return
+	}
+	switch element.Value.(*arcItem).segment {
+	case arcT1:
+		a.move(key, arcB1)
+	case arcT2:
+		a.move(key, arcB2)
+	}
+	a.trimGhosts()
+}
+
+// victims returns keys from the back of T1 while it is larger than its target size p, otherwise from T2 first.
+func (a *arc) victims(n int) []interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	order := []int{arcT2, arcT1}
+	if t1 := a.lists[arcT1].Len(); t1 > 0 && t1 > a.p {
+		order = []int{arcT1, arcT2}
+	}
+
+	keys := make([]interface{}, 0, n)
+	for _, segment := range order {
+		for element := a.lists[segment].Back(); element != nil && len(keys) < n; element = element.Prev() {
+			keys = append(keys, element.Value.(*arcItem).key)
+		}
+	}
+	return keys
+}
+
+// move puts key at the front of segment. The caller must hold the lock.
+func (a *arc) move(key interface{}, segment int) {
+	if element, exists := a.elements[key]; exists {
+		a.lists[element.Value.(*arcItem).segment].Remove(element)
+	}
+	a.elements[key] = a.lists[segment].PushFront(&arcItem{key: key, segment: segment})
+}
+
+// trimGhosts bounds the ghost lists so T1 and B1 together hold at most the capacity
+// and all lists together at most twice the capacity. The caller must hold the lock.
+func (a *arc) trimGhosts() {
+	for a.lists[arcB1].Len() > 0 && a.lists[arcT1].Len()+a.lists[arcB1].Len() > a.capacity {
+		a.dropGhost(arcB1)
+	}
+	for a.lists[arcB2].Len() > 0 && len(a.elements) > 2*a.capacity {
+		a.dropGhost(arcB2)
+	}
+}
+
+func (a *arc) dropGhost(segment int) {
+	item := a.lists[segment].Remove(a.lists[segment].Back()).(*arcItem)
+	delete(a.elements, item.key)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
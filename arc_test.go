@@ -0,0 +1,51 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_ARCEviction(t *testing.T) {
+	cache := NewBiCache(4, time.Minute)
+	cache.SetEvictionPolicy(ARCEviction)
+
+	// Entries read again move to the frequency list
+	cache.Set("hot1", "value", 0)
+	cache.Set("hot2", "value", 0)
+	cache.Get("hot1")
+	cache.Get("hot2")
+
+	// A scan only replaces entries seen once
+	for i := 0; i < 10; i++ {
+		cache.Set(i, "value", 0)
+	}
+	for _, key := range []string{"hot1", "hot2"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("ARCEviction test failed. Expected: %s kept", key)
+		}
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 4 {
+		t.Errorf("ARCEviction test failed. Expected: 4 entries, Got: %v", metrics.EntriesCount)
+	}
+}
+
+func TestARC_Adapts(t *testing.T) {
+	a := newARC(4)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		a.add(key)
+	}
+
+	// Evicting a key seen once and seeing it again favors recency
+	a.remove("a")
+	a.add("a")
+	if a.p != 1 {
+		t.Errorf("ARC adapt test failed. Expected: p=1, Got: %v", a.p)
+	}
+
+	// Evicting a frequent key and seeing it again favors frequency
+	a.remove("a")
+	a.add("a")
+	if a.p != 0 {
+		t.Errorf("ARC adapt test failed. Expected: p=0, Got: %v", a.p)
+	}
+}
@@ -0,0 +1,72 @@
+package bicache
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditType names a security-relevant event.
+type AuditType string
+
+const (
+	// AuditPolicyRejected is a write refused by the cache policy.
+	AuditPolicyRejected AuditType = "policy_rejected"
+	// AuditValidationFailed is a write refused by the validator.
+	AuditValidationFailed AuditType = "validation_failed"
+	// AuditReadOnlyViolation is an unforced write to a read-only entry.
+	AuditReadOnlyViolation AuditType = "read_only_violation"
+	// AuditValueTooLarge is a write of a value larger than the maximum value size.
+	AuditValueTooLarge AuditType = "value_too_large"
+	// AuditAuthFailed is a request to a Sidecar without a valid token.
+	AuditAuthFailed AuditType = "auth_failed"
+)
+
+// AuditEvent describes a refused write or request, for example to forward it to a SIEM.
+type AuditEvent struct {
+	Type AuditType `json:"type"`
+	Key  string    `json:"key,omitempty"`
+	// Reason tells why the write or request was refused.
+	Reason string `json:"reason,omitempty"`
+	// Source is the remote address of the request, for events of a Sidecar.
+	Source string    `json:"source,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// AuditHandlerFunc receives audit events.
+type AuditHandlerFunc func(event AuditEvent)
+
+// SetAuditHandler registers a function that is called asynchronously with security-relevant
+// events, such as writes refused by the cache policy or validator, writes to read-only entries
+// and oversized values. Like cache event handlers, it is not called once the cache is closed, Close
+// waits for the running calls and panics are passed to the error handler. A SIEMSink can be
+// registered with its Handle method.
+func (c *BiCache) SetAuditHandler(handler AuditHandlerFunc) {
+	c.auditHandler.Store(handler)
+}
+
+// audit passes an event about key to the audit handler, if one is registered.
+// It may be called with or without the lock.
+func (c *BiCache) audit(auditType AuditType, key interface{}, reason, source string) {
+	handler, _ := c.auditHandler.Load().(AuditHandlerFunc)
+	if handler == nil {
+		return
+	}
+
+	event := AuditEvent{Type: auditType, Reason: reason, Source: source, Time: time.Now()}
+	if key != nil {
+		event.Key = fmt.Sprint(key)
+	}
+
+	// Like cache events, audit events are dropped once the cache is closed, and Close waits for the rest
+	c.dispatchMu.Lock()
+	defer c.dispatchMu.Unlock()
+	if c.closed.Load() {
+		return
+	}
+	c.pendingEvents.Add(1)
+	go func() {
+		defer c.pendingEvents.Done()
+		defer c.recoverHandler(key)
+		handler(event)
+	}()
+}
@@ -0,0 +1,84 @@
+package bicache
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchLoaderFunc loads several missing keys in one call.
+// Keys missing from the returned map are treated as not found.
+type BatchLoaderFunc func(keys []interface{}) (map[interface{}]LoaderResult, error)
+
+// loadBatch collects the keys that missed during one coalescing window.
+type loadBatch struct {
+	keys    []interface{}
+	seen    map[interface{}]struct{}
+	done    chan struct{}
+	results map[interface{}]LoaderResult
+	err     error
+}
+
+// batcher coalesces loads for different keys into one call of a BatchLoaderFunc.
+type batcher struct {
+	mu      sync.Mutex
+	loader  BatchLoaderFunc
+	window  time.Duration
+	pending *loadBatch
+}
+
+// SetBatchLoader makes misses without a registered loader wait up to window for other misses,
+// so they are loaded together with a single call to loader. A nil loader disables batching.
+func (c *BiCache) SetBatchLoader(loader BatchLoaderFunc, window time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if loader == nil {
+		c.batcher = nil
+		return
+	}
+	c.batcher = &batcher{loader: loader, window: window}
+}
+
+// load adds key to the pending batch and waits for the batch to be loaded.
+func (b *batcher) load(key interface{}) (LoaderResult, error) {
+	b.mu.Lock()
+	batch := b.pending
+	if batch == nil {
+		batch = &loadBatch{seen: make(map[interface{}]struct{}), done: make(chan struct{})}
+		b.pending = batch
+		time.AfterFunc(b.window, b.flush)
+	}
+	if _, exists := batch.seen[key]; !exists {
+		batch.seen[key] = struct{}{}
+		batch.keys = append(batch.keys, key)
+	}
+	b.mu.Unlock()
+
+	<-batch.done
+
+	if batch.err != nil {
+		return LoaderResult{}, batch.err
+	}
+	result, exists := batch.results[key]
+	if !exists {
+		return LoaderResult{}, ErrNotFound
+	}
+	return result, nil
+}
+
+// flush loads the pending batch and wakes up its waiters. A panic of the loader is passed to
+// every waiter as a *PanicError instead of leaving them blocked.
+func (b *batcher) flush() {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	defer close(batch.done)
+	defer func() {
+		if r := recover(); r != nil {
+			batch.results, batch.err = nil, &PanicError{Value: r}
+		}
+	}()
+	batch.results, batch.err = b.loader(batch.keys)
+}
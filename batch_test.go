@@ -0,0 +1,80 @@
+package bicache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_BatchLoader(t *testing.T) {
+	cache := NewBiCache(10, time.Second)
+
+	var mu sync.Mutex
+	var calls [][]interface{}
+	cache.SetBatchLoader(func(keys []interface{}) (map[interface{}]LoaderResult, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		mu.Unlock()
+
+		results := make(map[interface{}]LoaderResult)
+		for _, key := range keys {
+			if key != "missing" {
+				results[key] = LoaderResult{Value: "value_" + key.(string), TTL: time.Minute}
+			}
+		}
+		return results, nil
+	}, time.Millisecond*20)
+
+	// Misses for different keys within the window are loaded together
+	var wg sync.WaitGroup
+	results := make([]interface{}, 3)
+	for i, key := range []string{"a", "b", "missing"} {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			results[i], _ = cache.Get(key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	if len(calls) != 1 || len(calls[0]) != 3 {
+		t.Errorf("BatchLoader test failed. Expected: 1 call with 3 keys, Got: %v", calls)
+	}
+	if results[0] != "value_a" || results[1] != "value_b" || results[2] != nil {
+		t.Errorf("BatchLoader test failed. Expected: [value_a value_b <nil>], Got: %v", results)
+	}
+
+	// Loaded values are cached
+	if result, found := cache.Get("a"); !found || result != "value_a" || len(calls) != 1 {
+		t.Errorf("BatchLoader test (cached) failed. Expected: 'value_a' without a new call, Got: '%v' after %v calls", result, len(calls))
+	}
+}
+
+func TestBiCache_BatchLoaderPanic(t *testing.T) {
+	cache := NewBiCache(10, time.Second)
+	cache.SetBatchLoader(func(keys []interface{}) (map[interface{}]LoaderResult, error) {
+		panic("loader failed")
+	}, time.Millisecond*10)
+
+	// Every waiter of the batch gets the panic as an error
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, key := range []string{"key1", "key2"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			_, err := cache.GetWithError(key)
+			errs <- err
+		}(key)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Errorf("BatchLoader panic test failed. Expected: a PanicError, Got: %v", err)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package bicache
 
 import (
+	"context"
 	"encoding/gob"
+	"fmt"
+	"math"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,20 +16,134 @@ type CacheEvent int
 const (
 	CacheEventSet CacheEvent = iota
 	CacheEventDelete
+	CacheEventEvict
+	CacheEventClear
 )
 
 type CacheEntry struct {
 	Value      interface{}
 	Expiration time.Time
 	Accessed   time.Time
+	ReadOnly   bool
+	Metadata   map[string]string
+	Tags       []string
+	Token      string
+	Writer     string
+	cost       time.Duration
+	ttl        time.Duration
+	created    time.Time
+	generation uint64
+	lastAccess *int64
+	weight     int64
+	memory     int64
+
+	fencingToken uint64
+
+	softExpiration time.Time
+}
+
+// touch records an access of a stored entry. It is safe under the read lock.
+func (e CacheEntry) touch(now time.Time) {
+	if e.lastAccess != nil {
+		atomic.StoreInt64(e.lastAccess, now.UnixNano())
+	}
+}
+
+// lastAccessed returns when a stored entry was last written or read.
+func (e CacheEntry) lastAccessed() time.Time {
+	if e.lastAccess == nil {
+		return e.Accessed
+	}
+	return time.Unix(0, atomic.LoadInt64(e.lastAccess))
+}
+
+// EntryInfo describes a cache entry without exposing its value.
+type EntryInfo struct {
+	Key            interface{}
+	Expiration     time.Time
+	SoftExpiration time.Time
+	Accessed       time.Time
+	ReadOnly       bool
+	Metadata       map[string]string
+	Tags           []string
+	Token          string
+	Writer         string
+	Age            time.Duration
+	Stale          bool
+	Source         Tier
+}
+
+// Tier identifies where a value was served from.
+type Tier int
+
+const (
+	// TierMemory serves values stored in the cache.
+	TierMemory Tier = iota
+	// TierLoader serves values just loaded by a loader after a miss.
+	TierLoader
+)
+
+func (t Tier) String() string {
+	switch t {
+	case TierMemory:
+		return "memory"
+	case TierLoader:
+		return "loader"
+	default:
+		return "unknown"
+	}
 }
 
 type CacheMetrics struct {
-	Hits         int64
-	Misses       int64
-	SetSuccess   int64
-	SetError     int64
-	EntriesCount int64
+	Hits              int64
+	Misses            int64
+	SetSuccess        int64
+	SetError          int64
+	ValidationError   int64
+	EntriesCount      int64
+	HitLatency        time.Duration
+	MissPenalty       time.Duration
+	ResolvedMisses    int64
+	AdmissionRejected int64
+	LoadSuccess       int64
+	LoadError         int64
+	LoadSuppressed    int64
+	PredictionsLoaded int64
+	PredictionHits    int64
+	Revalidations     int64
+	RevalidationsSame int64
+	EarlyRefreshes    int64
+	LoadLatency       time.Duration
+	HotHits           int64
+	DecodeError       int64
+	TotalWeight       int64
+	// MemoryBytes is only tracked while a memory limit or cost-benefit admission is set.
+	MemoryBytes       int64
+	Evictions         int64
+	Expirations       int64
+	ShadowReads       int64
+	ShadowMismatches  int64
+	DualWrites        int64
+	DualWriteErrors   int64
+	DualWriteLag      time.Duration
+	DualWritePending  int64
+	WriteBehinds      int64
+	WriteBehindErrors int64
+	WriteBehindQueued int64
+	Inserts           int64
+	Overwrites        int64
+	Deletions         int64
+	EvictedLifetime   time.Duration
+	ExpiredLifetime   time.Duration
+	DeletedLifetime   time.Duration
+	OverwriteLifetime time.Duration
+	LoadShed          int64
+	RefreshesDropped  int64
+	LoadsDeduplicated int64
+	RefreshesAhead    int64
+	StaleServed       int64
+	NegativeHits      int64
+	Passthrough       bool
 }
 
 type CachePolicyFunc func(key interface{}, entry CacheEntry) bool
@@ -34,38 +152,130 @@ type CacheEventHandlerFunc func(event CacheEvent, key interface{}, entry CacheEn
 
 type UpdateStrategyFunc func(key interface{}, oldValue interface{}) interface{}
 
+type ValidatorFunc func(key interface{}, value interface{}) error
+
 type CompressionFunc func(data []byte) ([]byte, error)
 type DecompressionFunc func(data []byte) ([]byte, error)
 
 type BiCache struct {
 	mu                sync.RWMutex
 	capacity          int
+	zeroCapacity      ZeroCapacity
+	passthrough       atomic.Bool
 	cacheMap          map[interface{}]CacheEntry
-	metrics           CacheMetrics
+	eviction          evictor
+	evictionPolicy    EvictionPolicy
+	protectedRatio    float64
+	evictionSamples   int
+	weigher           WeigherFunc
+	maxWeight         int64
+	maxMemory         int64
+	thresholds        []*thresholdWatch
+	thresholdBaseline thresholdBaseline
+	warmthBaseline    thresholdBaseline
+	window            *lruList
+	reads             readBuffer
+	metrics           metricCounters
 	cleanupTicker     *time.Ticker
+	stopCleanup       chan struct{}
+	closed            atomic.Bool
+	pendingEvents     sync.WaitGroup
+	// dispatchMu orders the audit events and errors dispatched without the lock with Close
+	dispatchMu        sync.Mutex
+	dispatchStopped   bool
+	cleanupInterval   time.Duration
 	serializer        *gob.Encoder
 	deserializer      *gob.Decoder
 	cachePolicy       CachePolicyFunc
 	globalExpiration  time.Duration
 	cacheEventHandler CacheEventHandlerFunc
+	eventHandlers     []ContextEventHandlerFunc
 	updateStrategy    UpdateStrategyFunc
+	validator         ValidatorFunc
+	admission         Admission
 	compression       CompressionFunc
 	decompression     DecompressionFunc
+	loaders           map[string]ContextLoaderFunc
+	loadFailures      map[interface{}]loadFailure
+	failureBackoff    time.Duration
+	negatives         map[interface{}]negativeEntry
+	leases            map[interface{}]leaseRecord
+	fencingTokens     uint64
+	notFoundTTL       time.Duration
+	errorTTL          time.Duration
+	maxFailureBackoff time.Duration
+	batcher           *batcher
+	predictNext       PredictNextFunc
+	predictSem        chan struct{}
+	predictMu         sync.Mutex
+	predicted         map[interface{}]struct{}
+	revalidate        RevalidateFunc
+	shadowRead        ShadowReadFunc
+	shadowMismatch    ShadowMismatchFunc
+	shadowSem         chan struct{}
+	dualWriter        *dualWriter
+	writeBehind       *writeBehind
+	history           *history
+	earlyBeta         float64
+	refreshAhead      atomic.Int64
+	maxStaleness      atomic.Int64
+	maxStale          atomic.Int64
+	refreshMu         sync.Mutex
+	refreshing        map[interface{}]struct{}
+	refreshQueue      refreshQueue
+	flights           flightGroup
+	refreshLimit      int
+	refreshWorkers    int
+	hasher            keyHasher
+	sketch            *countMinSketch
+	tracer            TraceFunc
+	errorHandler      atomic.Value
+	shedder           atomic.Value
+	auditHandler      atomic.Value
+	writer            atomic.Value
+	maxValueSize      int64
+	keyHashing        atomic.Pointer[keyHashing]
+	decodeFailure     DecodeFailure
+	hot               atomic.Pointer[hotStore]
+	generation        uint64
+	invalidations     map[string]uint64
+	missMu            sync.Mutex
+	pendingMisses     map[interface{}]time.Time
 }
 
+// NewBiCache creates a cache holding up to capacity entries and starts cleaning it up every
+// cleanupInterval. A capacity of 0 doesn't limit the number of entries, unless SetZeroCapacity
+// disables the cache instead. A negative capacity is treated as 0.
 func NewBiCache(capacity int, cleanupInterval time.Duration) *BiCache {
+	capacity = clampCapacity(capacity)
 	cache := &BiCache{
 		capacity:          capacity,
 		cacheMap:          make(map[interface{}]CacheEntry),
+		eviction:          newLRUList(),
+		protectedRatio:    defaultProtectedRatio,
+		evictionSamples:   defaultEvictionSamples,
+		window:            newLRUList(),
 		cleanupTicker:     time.NewTicker(cleanupInterval),
+		stopCleanup:       make(chan struct{}),
+		cleanupInterval:   cleanupInterval,
 		serializer:        nil,
 		deserializer:      nil,
 		cachePolicy:       nil, // Cache policy can be set using SetCachePolicy method
 		globalExpiration:  0,   // Global expiration can be set using SetGlobalExpiration method
 		cacheEventHandler: nil, // Cache event handler can be set using SetCacheEventHandler method
 		updateStrategy:    nil, // Update strategy can be set using SetUpdateStrategy method
+		validator:         nil, // Validator can be set using SetValidator method
 		compression:       nil, // Compression can be set using SetCompression method
 		decompression:     nil, // Decompression can be set using SetDecompression method
+		loaders:           make(map[string]ContextLoaderFunc),
+		loadFailures:      make(map[interface{}]loadFailure),
+		predictSem:        make(chan struct{}, predictionConcurrency),
+		predicted:         make(map[interface{}]struct{}),
+		shadowSem:         make(chan struct{}, shadowConcurrency),
+		refreshing:        make(map[interface{}]struct{}),
+		hasher:            newKeyHasher(),
+		pendingMisses:     make(map[interface{}]time.Time),
+		invalidations:     make(map[string]uint64),
 	}
 
 	go cache.periodicCleanup()
@@ -74,104 +284,415 @@ func NewBiCache(capacity int, cleanupInterval time.Duration) *BiCache {
 }
 
 func (c *BiCache) Get(key interface{}) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	entry, found := c.GetEntry(key)
+	return entry.Value, found
+}
 
-	entry, exists := c.cacheMap[key]
-	if exists {
-		entry.Accessed = time.Now()
+// GetEntry returns the decoded entry stored under key, loading it on a miss if a loader is registered.
+// A cached nil value is reported as found with a nil Value, unlike a miss.
+func (c *BiCache) GetEntry(key interface{}) (CacheEntry, bool) {
+	entry, _, err := c.lookup(context.Background(), key)
+	return entry, err == nil
+}
 
-		if c.decompression != nil {
-			byteValue, ok := entry.Value.([]byte)
-			if !ok {
-				c.metrics.SetError++
-				return nil, false
-			}
+// GetWithError is like Get but reports why a value could not be returned: ErrNotFound on a miss,
+// the loader's error if loading failed, and a *DecodeError if the stored value could not be
+// decoded and SetDecodeFailure(DecodeFailureError) is set, and ErrClosed once the cache is closed.
+func (c *BiCache) GetWithError(key interface{}) (interface{}, error) {
+	entry, _, err := c.lookup(context.Background(), key)
+	return entry.Value, err
+}
 
-			// Decompress the value
-			decompressedValue, err := c.decompression(byteValue)
-			if err != nil {
-				c.metrics.SetError++
-				return nil, false
-			}
-			entry.Value = decompressedValue
+// GetWithInfo returns the value stored under key together with its age, staleness and source.
+func (c *BiCache) GetWithInfo(key interface{}) (interface{}, EntryInfo, bool) {
+	entry, source, err := c.lookup(context.Background(), key)
+	if err != nil {
+		return nil, EntryInfo{}, false
+	}
+
+	info := newEntryInfo(key, entry, time.Now())
+	info.Source = source
+	return entry.Value, info, true
+}
+
+// lookup returns the entry stored under key, loading it on a miss, and the tier that served it.
+// The context is passed on to loaders, event handlers and the tracer.
+func (c *BiCache) lookup(ctx context.Context, key interface{}) (CacheEntry, Tier, error) {
+	return c.lookupWith(ctx, key, nil)
+}
+
+// lookupWith is like lookup, but loads misses with loader instead of the registered loader if it
+// is not nil. Concurrent misses of key loaded with an explicit loader share one load.
+func (c *BiCache) lookupWith(ctx context.Context, key interface{}, loader ContextLoaderFunc) (CacheEntry, Tier, error) {
+	if c.closed.Load() {
+		return CacheEntry{}, TierMemory, ErrClosed
+	}
+
+	// Hot entries are served without taking the lock
+	if entry, found := c.getHot(key); found {
+		c.touchHot(key, &entry)
+		c.refreshAheadOf(ctx, key, entry)
+		c.shadow(ctx, key, entry.Value, true)
+		return entry, TierMemory, nil
+	}
+
+	start := time.Now()
+	c.recordAccess(key)
+
+	entry, err := c.getEntry(key)
+	if err == nil {
+		c.promoteHot(key, entry)
+		c.predictedHit(key)
+		c.predict(ctx, key)
+		if expired(entry, time.Now()) {
+			c.refreshAsync(ctx, key, entry)
+		} else {
+			c.refreshEarly(ctx, key, entry)
 		}
+		c.trace(ctx, key, TierMemory, true, start)
+		c.shadow(ctx, key, entry.Value, true)
+		return entry, TierMemory, nil
+	}
 
-		if c.deserializer != nil {
-			// Decode the value
-			decodedValue, err := c.decodeValue(entry.Value)
-			if err != nil {
-				c.metrics.SetError++
-				return nil, false
+	if err == ErrNotFound {
+		explicit := loader != nil
+		if !explicit {
+			loader = c.loaderFor(key)
+		}
+		if loader != nil {
+			if err := c.shed(ctx, key); err != nil {
+				c.trace(ctx, key, TierLoader, false, start)
+				c.shadow(ctx, key, nil, false)
+				return CacheEntry{}, TierLoader, err
+			}
+			var entry CacheEntry
+			var err error
+			if explicit {
+				entry, err = c.flights.do(ctx, c, key, func() (CacheEntry, error) {
+					return c.load(ctx, key, loader)
+				})
+			} else {
+				entry, err = c.load(ctx, key, loader)
 			}
-			entry.Value = decodedValue
+			c.trace(ctx, key, TierLoader, err == nil, start)
+			c.shadow(ctx, key, entry.Value, err == nil)
+			return entry, TierLoader, err
+		}
+	}
+
+	c.trace(ctx, key, TierMemory, false, start)
+	c.shadow(ctx, key, nil, false)
+	return CacheEntry{}, TierMemory, err
+}
+
+// getEntry returns the decoded entry stored under key. It returns ErrNotFound on a miss,
+// which includes values that fail to decode unless DecodeFailureError is set.
+func (c *BiCache) getEntry(key interface{}) (CacheEntry, error) {
+	start := time.Now()
+
+	c.mu.RLock()
+	read := c.readEntry(key, start)
+	c.mu.RUnlock()
+
+	return c.finishRead(key, read)
+}
+
+// entryRead is the outcome of reading an entry under the read lock, together with the work
+// that is left to do once the lock is released.
+type entryRead struct {
+	entry     CacheEntry
+	err       error
+	stored    CacheEntry
+	remove    bool
+	reason    removalReason
+	decodeErr error
+}
+
+// readEntry reads the entry stored under key for a Get that started at start.
+// The caller must hold the read lock and pass the result to finishRead after releasing it.
+func (c *BiCache) readEntry(key interface{}, start time.Time) entryRead {
+	mapped := c.mapKey(key)
+	stored, exists := c.cacheMap[mapped]
+	if !exists {
+		c.metrics.Misses.Add(1)
+		c.recordMiss(key)
+		return entryRead{err: ErrNotFound}
+	}
+
+	now := time.Now()
+	live := c.live(key, stored, now)
+	stale := !live && c.servesStale(key, stored, now)
+	if !live && !stale {
+		c.metrics.Misses.Add(1)
+		c.recordMiss(key)
+
+		reason := removalDeleted
+		if expired(stored, now) || c.tooStale(stored, now) {
+			reason = removalExpired
 		}
+		return entryRead{err: ErrNotFound, stored: stored, remove: true, reason: reason}
+	}
+
+	entry := stored
+	entry.Accessed = time.Now()
+	stored.touch(entry.Accessed)
+	c.recordRead(mapped, entry.Accessed)
+
+	value, err := c.decodeStored(entry.Value)
+	if err != nil {
+		c.metrics.DecodeError.Add(1)
+		c.metrics.Misses.Add(1)
+		c.recordMiss(key)
 
-		if entry.Expiration.IsZero() || time.Now().Before(entry.Expiration) {
-			c.metrics.Hits++
-			return entry.Value, true
+		read := entryRead{err: ErrNotFound, stored: stored, decodeErr: err}
+		switch c.decodeFailure {
+		case DecodeFailureDelete:
+			read.remove, read.reason = true, removalDeleted
+		case DecodeFailureError:
+			read.err = &DecodeError{Key: key, Err: err}
 		}
+		return read
+	}
+	entry.Value = value
+	if stale {
+		// Report the expired value as stale, like one past its soft TTL
+		entry.softExpiration = entry.Expiration
+		c.metrics.StaleServed.Add(1)
+	}
 
-		delete(c.cacheMap, key)
-		c.metrics.EntriesCount = int64(len(c.cacheMap))
+	c.metrics.Hits.Add(1)
+	c.metrics.HitLatency.Add(int64(time.Since(start)))
+	return entryRead{entry: entry}
+}
+
+// finishRead completes a read by readEntry once the lock is released.
+func (c *BiCache) finishRead(key interface{}, read entryRead) (CacheEntry, error) {
+	if read.decodeErr != nil {
+		c.reportError("get", key, read.decodeErr)
+	}
+	if read.remove {
+		c.removeUnchanged(key, read.stored, read.reason)
+	}
+	if read.err != nil {
+		return CacheEntry{}, read.err
 	}
 
-	c.metrics.Misses++
-	return nil, false
+	entry := read.entry
+	entry.Metadata = copyMetadata(entry.Metadata)
+	entry.Tags = copyTags(entry.Tags)
+	return entry, nil
+}
+
+// removeUnchanged removes the entry stored under key unless it was replaced since it was read as stored.
+// It reports whether the entry was removed.
+func (c *BiCache) removeUnchanged(key interface{}, stored CacheEntry, reason removalReason) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, exists := c.cacheMap[c.mapKey(key)]; exists && current.created.Equal(stored.created) && current.Expiration.Equal(stored.Expiration) {
+		c.removeEntry(key, reason)
+		return true
+	}
+	return false
 }
 
 func (c *BiCache) Set(key interface{}, value interface{}, expiration time.Duration) {
+	if err := c.SetWithOptions(key, value, expiration); err != nil {
+		c.reportError("set", key, err)
+	}
+}
+
+// SetWithOptions stores a value like Set and applies the given per-entry options.
+// It returns an error when the value could not be stored.
+func (c *BiCache) SetWithOptions(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) error {
+	return c.setThrough(context.Background(), key, value, expiration, opts...)
+}
+
+// set stores a value and passes ctx on to the event handlers.
+func (c *BiCache) set(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) error {
+	options := newSetOptions(opts)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	entry := CacheEntry{Accessed: time.Now()}
+	if c.closed.Load() {
+		return ErrClosed
+	}
+	return c.store(ctx, key, value, expiration, options)
+}
+
+// store stores a value with the given options. The caller must hold the write lock.
+func (c *BiCache) store(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, options setOptions) error {
+	_, err := c.tryStore(ctx, key, value, expiration, options)
+	return err
+}
+
+// tryStore is like store and also reports whether the entry was stored, which it is not if the
+// cache policy or admission drops it, or the cache stores nothing. The caller must hold the write lock.
+func (c *BiCache) tryStore(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, options setOptions) (bool, error) {
+	entry, ok, err := c.prepare(ctx, key, value, expiration, options)
+	if err != nil || !ok {
+		return false, err
+	}
+	now := entry.created
+
+	// Queue writes of callers for the write-behind store, the cache is not changed if that fails
+	if options.through {
+		if err := c.queueWriteBehind(Mutation{Key: key, Value: value, Expiration: expiration}); err != nil {
+			c.metrics.SetError.Add(1)
+			return false, err
+		}
+	}
+
+	if c.updateStrategy != nil {
+		oldValue, exists := c.cacheMap[c.mapKey(key)]
+		if exists {
+			entry.Value = c.updateStrategy(key, oldValue.Value)
+		}
+	}
+
+	// Measure how long it took the caller to resolve a previous miss
+	if penalty, missed := c.resolveMiss(key); missed {
+		c.recordMissPenalty(penalty)
+		entry.cost = penalty
+	} else if current, exists := c.cacheMap[c.mapKey(key)]; exists {
+		entry.cost = current.cost
+	}
+
+	// A disabled cache drops the entry after it was written through
+	if c.storesNothing() {
+		return false, nil
+	}
+
+	if c.measuresMemory() {
+		entry.memory = entryMemory(c.mapKey(key), entry)
+	}
+	if !c.admit(key, entry) {
+		c.metrics.AdmissionRejected.Add(1)
+		return false, nil
+	}
 
-	// Encode the value
-	if c.serializer != nil {
+	c.generation++
+	entry.generation = c.generation
+	if !options.uncounted {
+		c.recordWrite(key, now)
+	}
+	delete(c.negatives, key)
+	c.storeEntry(key, entry)
+	c.recordVersion(key, value, false, now)
+	if !options.uncounted {
+		c.metrics.SetSuccess.Add(1)
+	}
+	if !options.restored {
+		c.queueDualWrite(ctx, dualWrite{key: key, value: value, expiration: expiration})
+	}
+
+	if c.overCapacity() {
+		c.cleanup()
+		c.evict(key)
+	}
+
+	c.emitContext(ctx, CacheEventSet, key, entry)
+
+	return true, nil
+}
+
+// prepare builds the entry that store would store for value and runs the checks that may refuse
+// it: read-only entries, fencing tokens, the maximum value size, the cache policy and the validator.
+// It reports false without an error if the cache policy drops the entry. Nothing is stored.
+// The caller must hold the lock.
+func (c *BiCache) prepare(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, options setOptions) (CacheEntry, bool, error) {
+	// Refuse to overwrite read-only entries unless the write is forced
+	if current, exists := c.cacheMap[c.mapKey(key)]; exists && current.ReadOnly && !options.force && c.live(key, current, time.Now()) {
+		c.audit(AuditReadOnlyViolation, key, "entry is read-only", "")
+		return CacheEntry{}, false, &ReadOnlyError{Key: key}
+	}
+	if err := c.checkFencingToken(key, options.fencingToken); err != nil {
+		c.metrics.SetError.Add(1)
+		return CacheEntry{}, false, err
+	}
+
+	now := time.Now()
+	entry := CacheEntry{
+		Value:    value,
+		Accessed: now,
+		ReadOnly: options.readOnly,
+		Metadata: options.metadata,
+		Tags:     options.tags,
+		Token:    options.token,
+		Writer:   options.writer,
+		created:  now,
+	}
+	if entry.Writer == "" {
+		entry.Writer = writerFromContext(ctx)
+	}
+	entry.fencingToken = options.fencingToken
+	if current, exists := c.cacheMap[c.mapKey(key)]; exists && current.fencingToken > entry.fencingToken {
+		entry.fencingToken = current.fencingToken
+	}
+	entry.lastAccess = new(int64)
+	entry.touch(now)
+	entry.weight = 1
+	if c.weigher != nil {
+		entry.weight = c.weigher(key, value)
+	}
+
+	// Encode the value, nil values are stored as is
+	if c.serializer != nil && value != nil {
 		encodedValue, err := c.encodeValue(value)
 		if err != nil {
-			c.metrics.SetError++
-			return
+			c.metrics.SetError.Add(1)
+			return CacheEntry{}, false, err
 		}
 		entry.Value = encodedValue
 	}
 
 	// Compress the value
-	if c.compression != nil {
+	if c.compression != nil && entry.Value != nil {
 		compressedValue, err := c.compressValue(entry.Value)
 		if err != nil {
-			c.metrics.SetError++
-			return
+			c.metrics.SetError.Add(1)
+			return CacheEntry{}, false, err
 		}
 		entry.Value = compressedValue
 	}
 
+	if c.maxValueSize > 0 {
+		if size := estimateSize(entry.Value); size > c.maxValueSize {
+			c.metrics.SetError.Add(1)
+			c.audit(AuditValueTooLarge, key, fmt.Sprintf("value of %d bytes exceeds %d bytes", size, c.maxValueSize), "")
+			return CacheEntry{}, false, ErrValueTooLarge
+		}
+	}
+
+	// A negative expiration stores the entry as already expired, zero never expires
+	if expiration != 0 {
+		entry.Expiration = now.Add(expiration)
+	}
 	if expiration > 0 {
-		entry.Expiration = time.Now().Add(expiration)
+		entry.ttl = expiration
+	}
+	if options.softTTL > 0 {
+		entry.softExpiration = now.Add(options.softTTL)
+	} else if !options.softExpiration.IsZero() {
+		entry.softExpiration = options.softExpiration
 	}
 
 	if c.cachePolicy != nil && !c.cachePolicy(key, entry) {
-		return
+		c.audit(AuditPolicyRejected, key, "rejected by the cache policy", "")
+		return CacheEntry{}, false, nil
 	}
 
-	if c.updateStrategy != nil {
-		oldValue, exists := c.cacheMap[key]
-		if exists {
-			entry.Value = c.updateStrategy(key, oldValue.Value)
+	// Validate the original value before storing it
+	if c.validator != nil {
+		if err := c.validator(key, value); err != nil {
+			c.metrics.ValidationError.Add(1)
+			c.audit(AuditValidationFailed, key, err.Error(), "")
+			return CacheEntry{}, false, err
 		}
 	}
 
-	c.cacheMap[key] = entry
-	c.metrics.SetSuccess++
-	c.metrics.EntriesCount = int64(len(c.cacheMap))
-
-	if len(c.cacheMap) > c.capacity {
-		c.cleanup()
-	}
-
-	if c.cacheEventHandler != nil {
-		go c.cacheEventHandler(CacheEventSet, key, entry)
-	}
+	return entry, true, nil
 }
 
 func (c *BiCache) compressValue(value interface{}) (interface{}, error) {
@@ -188,12 +709,51 @@ func (c *BiCache) compressValue(value interface{}) (interface{}, error) {
 			return nil, err
 		}
 		return compressedValue, nil
+	case string:
+		// If the value is a string, compress its bytes
+		compressedValue, err := c.compression([]byte(val))
+		if err != nil {
+			return nil, err
+		}
+		return compressedValue, nil
 	default:
 		// If the value is not a byte slice, return the original value
 		return value, nil
 	}
 }
 
+// decodeStored reverses the compression and encoding applied to a stored value.
+func (c *BiCache) decodeStored(value interface{}) (interface{}, error) {
+	// Cached nil values are stored as is and need no decoding
+	if value == nil {
+		return nil, nil
+	}
+
+	if c.decompression != nil {
+		byteValue, ok := value.([]byte)
+		if !ok {
+			return nil, errNotCompressed
+		}
+
+		// Decompress the value
+		decompressedValue, err := c.decompression(byteValue)
+		if err != nil {
+			return nil, err
+		}
+		value = decompressedValue
+	}
+
+	if c.deserializer != nil {
+		// Decode the value
+		decodedValue, err := c.decodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		value = decodedValue
+	}
+	return value, nil
+}
+
 func (c *BiCache) decodeValue(encodedValue interface{}) (interface{}, error) {
 	value := reflect.New(reflect.TypeOf(encodedValue))
 	if err := c.deserializer.DecodeValue(value); err != nil {
@@ -211,14 +771,110 @@ func (c *BiCache) encodeValue(value interface{}) (interface{}, error) {
 }
 
 func (c *BiCache) Delete(key interface{}) {
+	c.DeleteContext(context.Background(), key)
+}
+
+// DeleteAfter marks the entry stored under key for deletion after grace. Until then it is still
+// served, but reported as stale, so readers that were not updated yet keep working. Writing the
+// key again cancels the deletion. A grace of 0 or less deletes the entry right away.
+// It reports whether a live entry was found.
+func (c *BiCache) DeleteAfter(key interface{}, grace time.Duration) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	delete(c.cacheMap, key)
-	c.metrics.EntriesCount = int64(len(c.cacheMap))
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, now) {
+		return false
+	}
+
+	if grace <= 0 {
+		c.removeEntry(key, removalDeleted)
+		c.emit(CacheEventDelete, key, CacheEntry{})
+		return true
+	}
 
-	if c.cacheEventHandler != nil {
-		go c.cacheEventHandler(CacheEventDelete, key, CacheEntry{})
+	deleteAt := now.Add(grace)
+	if entry.Expiration.IsZero() || deleteAt.Before(entry.Expiration) {
+		entry.Expiration = deleteAt
+	}
+	entry.softExpiration = now
+	c.storeEntry(key, entry)
+	return true
+}
+
+// storeEntry stores entry under key. The caller must hold the write lock.
+func (c *BiCache) storeEntry(key interface{}, entry CacheEntry) {
+	stored := c.mapKey(key)
+	if current, exists := c.cacheMap[stored]; exists {
+		c.metrics.TotalWeight.Add(-current.weight)
+		c.metrics.MemoryBytes.Add(-current.memory)
+	}
+	if entry.memory == 0 && c.measuresMemory() {
+		entry.memory = entryMemory(stored, entry)
+	}
+	c.cacheMap[stored] = entry
+	c.keepKey(key, stored)
+	c.metrics.TotalWeight.Add(entry.weight)
+	c.metrics.MemoryBytes.Add(entry.memory)
+	c.metrics.EntriesCount.Store(int64(len(c.cacheMap)))
+	c.eviction.add(stored)
+	c.invalidateHot(stored)
+}
+
+// removeEntry removes the entry stored under key and counts the removal for reason.
+// The caller must hold the write lock.
+func (c *BiCache) removeEntry(key interface{}, reason removalReason) {
+	stored := c.mapKey(key)
+	if current, exists := c.cacheMap[stored]; exists {
+		c.recordRemoval(current, reason, time.Now())
+	}
+	c.dropEntry(stored)
+}
+
+// dropEntry removes the entry stored under the stored key without counting a removal.
+// The caller must hold the write lock.
+func (c *BiCache) dropEntry(stored interface{}) {
+	if current, exists := c.cacheMap[stored]; exists {
+		c.metrics.TotalWeight.Add(-current.weight)
+		c.metrics.MemoryBytes.Add(-current.memory)
+	}
+	delete(c.cacheMap, stored)
+	c.forgetKey(stored)
+	c.metrics.EntriesCount.Store(int64(len(c.cacheMap)))
+	c.eviction.remove(stored)
+	c.window.remove(stored)
+	c.invalidateHot(stored)
+}
+
+// GetEntryInfo returns the expiration, access time and metadata of a non-expired entry.
+func (c *BiCache) GetEntryInfo(key interface{}) (EntryInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, now) {
+		return EntryInfo{}, false
+	}
+
+	return newEntryInfo(key, entry, now), true
+}
+
+func newEntryInfo(key interface{}, entry CacheEntry, now time.Time) EntryInfo {
+	return EntryInfo{
+		Key:            key,
+		Expiration:     entry.Expiration,
+		SoftExpiration: entry.softExpiration,
+		Accessed:       entry.lastAccessed(),
+		ReadOnly:       entry.ReadOnly,
+		Metadata:       copyMetadata(entry.Metadata),
+		Tags:           copyTags(entry.Tags),
+		Token:          entry.Token,
+		Writer:         entry.Writer,
+		Age:            now.Sub(entry.created),
+		Stale:          !entry.softExpiration.IsZero() && !now.Before(entry.softExpiration),
+		Source:         TierMemory,
 	}
 }
 
@@ -226,7 +882,15 @@ func (c *BiCache) GetMetrics() CacheMetrics {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.metrics
+	metrics := c.metrics.snapshot()
+	metrics.DualWritePending = c.dualWriter.pending()
+	metrics.WriteBehindQueued = c.writeBehind.pending()
+	metrics.Passthrough = c.passthrough.Load()
+	if hot := c.hot.Load(); hot != nil {
+		metrics.HotHits = atomic.LoadInt64(&hot.hits)
+		metrics.Hits += metrics.HotHits
+	}
+	return metrics
 }
 
 func (c *BiCache) SetSerializer(serializer *gob.Encoder) {
@@ -243,14 +907,22 @@ func (c *BiCache) SetDeserializer(deserializer *gob.Decoder) {
 	c.deserializer = deserializer
 }
 
+// SetCapacity changes the number of entries the cache holds, evicting entries if it holds more.
+// A capacity of 0 means what SetZeroCapacity selects, and a negative capacity is treated as 0.
 func (c *BiCache) SetCapacity(capacity int) {
+	capacity = clampCapacity(capacity)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.capacity = capacity
+	c.eviction.setCapacity(capacity)
 
-	if len(c.cacheMap) > c.capacity {
+	if c.storesNothing() {
+		c.clearEntries()
+	} else if c.overCapacity() {
 		c.cleanup()
+		c.evict(nil)
 	}
 }
 
@@ -282,6 +954,13 @@ func (c *BiCache) SetUpdateStrategy(strategy UpdateStrategyFunc) {
 	c.updateStrategy = strategy
 }
 
+func (c *BiCache) SetValidator(validator ValidatorFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.validator = validator
+}
+
 func (c *BiCache) SetCompression(compression CompressionFunc, decompression DecompressionFunc) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -291,37 +970,71 @@ func (c *BiCache) SetCompression(compression CompressionFunc, decompression Deco
 }
 
 func (c *BiCache) periodicCleanup() {
-	for range c.cleanupTicker.C {
-		c.cleanup()
+	for {
+		select {
+		case <-c.cleanupTicker.C:
+			c.mu.Lock()
+			c.cleanup()
+			c.checkThresholds(time.Now())
+			c.mu.Unlock()
+		case <-c.stopCleanup:
+			return
+		}
 	}
 }
 
-// cleanup method cleans up the currently valid items in the cache.
-func (c *BiCache) cleanup() {
+// cleanup method cleans up the currently valid items in the cache and reports what it removed.
+func (c *BiCache) cleanup() CleanupReport {
 	// Get the current time
 	now := time.Now()
+	report := CleanupReport{Scanned: len(c.cacheMap)}
 
-	// Check each item in the cache
-	for key, entry := range c.cacheMap {
-		// Determine the expiration time to use for comparison
-		var expiration time.Time
-		if c.globalExpiration > 0 {
-			// If globalExpiration is greater than 0, use the item's Accessed time plus globalExpiration
-			expiration = entry.Accessed.Add(c.globalExpiration)
-		} else {
-			// If globalExpiration is 0 or negative, use the item's Expiration directly
-			expiration = entry.Expiration
-		}
-
-		// If the calculated expiration is in the past, clean up this item.
-		if expiration.Before(now) {
-			delete(c.cacheMap, key)
-			c.metrics.EntriesCount = int64(len(c.cacheMap))
+	c.cleanupPendingMisses(now)
+	c.cleanupLoadFailures(now)
+	c.cleanupNegatives(now)
+	c.cleanupLeases(now)
+	c.cleanupPredicted()
+	c.cleanupHot()
 
-			// If a cache event handler is defined, call it when the item is deleted.
-			if c.cacheEventHandler != nil {
-				go c.cacheEventHandler(CacheEventDelete, key, CacheEntry{})
+	// Check each item in the cache. Invalidated entries are removed, except hashed ones whose
+	// original key is unknown, so only those still need the invalidations they predate.
+	oldest := uint64(math.MaxUint64)
+	for stored, entry := range c.cacheMap {
+		// If the calculated expiration is in the past or the namespace was invalidated, clean up this item.
+		key := c.externalKey(stored)
+		expiration := c.cleanupExpiration(entry)
+		timedOut := !expiration.IsZero() && expiration.Before(now)
+		if timedOut || c.invalidated(key, entry) {
+			if timedOut {
+				c.removeEntry(key, removalExpired)
+				report.Expired++
+			} else {
+				c.removeEntry(key, removalDeleted)
+				report.Invalidated++
 			}
+
+			// Notify the cache event handlers that the item is deleted.
+			c.emit(CacheEventDelete, key, CacheEntry{})
+		} else if _, hashed := key.(HashedKey); hashed && entry.generation < oldest {
+			oldest = entry.generation
 		}
 	}
+	c.cleanupInvalidations(oldest)
+	c.cleanupHistory()
+	return report
+}
+
+// cleanupExpiration returns the time after which cleanup removes the entry, or zero if it never does.
+func (c *BiCache) cleanupExpiration(entry CacheEntry) time.Time {
+	if c.globalExpiration > 0 {
+		// If globalExpiration is greater than 0, use the item's last access time plus globalExpiration
+		return earliest(entry.lastAccessed().Add(c.globalExpiration), c.staleAt(entry))
+	}
+	// If globalExpiration is 0 or negative, use the item's Expiration directly,
+	// keeping expired entries as long as they may be served stale
+	expiration := entry.Expiration
+	if maxStale := time.Duration(c.maxStale.Load()); maxStale > 0 && !expiration.IsZero() {
+		expiration = expiration.Add(maxStale)
+	}
+	return earliest(expiration, c.staleAt(entry))
 }
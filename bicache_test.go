@@ -2,7 +2,9 @@ package bicache
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
+	"errors"
 	"testing"
 	"time"
 )
@@ -207,28 +209,230 @@ func TestBiCache_PeriodicCleanup(t *testing.T) {
 func TestBiCache_CacheEventHandler(t *testing.T) {
 	cache := NewBiCache(5, time.Second)
 
-	var eventReceived bool
-	var receivedKey interface{}
-	var receivedEvent CacheEvent
-	var receivedEntry CacheEntry
+	type received struct {
+		event CacheEvent
+		key   interface{}
+		entry CacheEntry
+	}
+	events := make(chan received, 1)
 
 	// Set a custom event handler
 	cache.SetCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
-		eventReceived = true
-		receivedKey = key
-		receivedEvent = event
-		receivedEntry = entry
+		events <- received{event: event, key: key, entry: entry}
 	})
 
 	// Set a value in the cache
 	cache.Set("key1", "value1", time.Second)
 
-	// Wait for the event handler to be called
-	time.Sleep(time.Millisecond * 100)
+	// Wait for the event handler to be called and check if the event was received correctly
+	select {
+	case r := <-events:
+		if r.key != "key1" || r.event != CacheEventSet || r.entry.Value != "value1" {
+			t.Errorf("CacheEventHandler test failed. Event not received or incorrect values. Received: key=%v, event=%v, entry=%v",
+				r.key, r.event, r.entry)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("CacheEventHandler test failed. Event not received")
+	}
+}
+
+func TestBiCache_ReadOnly(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+
+	// Set a read-only value in the cache
+	if err := cache.SetWithOptions("key1", "value1", time.Second*20, WithReadOnly()); err != nil {
+		t.Fatalf("ReadOnly test failed. Unexpected error: %v", err)
+	}
+
+	// Try to overwrite the read-only value
+	err := cache.SetWithOptions("key1", "value2", time.Second*20)
+	var readOnlyErr *ReadOnlyError
+	if !errors.As(err, &readOnlyErr) || readOnlyErr.Key != "key1" {
+		t.Errorf("ReadOnly test failed. Expected: ReadOnlyError, Got: '%v'", err)
+	}
+
+	result, found := cache.Get("key1")
+	if !found || result.(string) != "value1" {
+		t.Errorf("ReadOnly test failed. Expected: 'value1', Got: '%v'", result)
+	}
+
+	// Force the overwrite
+	if err := cache.SetWithOptions("key1", "value2", time.Second*20, WithForce()); err != nil {
+		t.Errorf("ReadOnly test (forced) failed. Unexpected error: %v", err)
+	}
+
+	result, found = cache.Get("key1")
+	if !found || result.(string) != "value2" {
+		t.Errorf("ReadOnly test (forced) failed. Expected: 'value2', Got: '%v'", result)
+	}
+}
+
+func TestBiCache_Metadata(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+
+	// Set a value with metadata in the cache
+	metadata := map[string]string{"source": "svcA", "build": "123"}
+	cache.SetWithOptions("key1", "value1", time.Second*20, WithMetadata(metadata))
+
+	// Changing the original map must not affect the stored metadata
+	metadata["source"] = "svcB"
+
+	// Get the entry info from the cache
+	info, found := cache.GetEntryInfo("key1")
+	if !found || info.Metadata["source"] != "svcA" || info.Metadata["build"] != "123" {
+		t.Errorf("Metadata test failed. Expected: source=svcA build=123, Got: '%v'", info.Metadata)
+	}
+
+	// Get the entry info for a missing key
+	if _, found := cache.GetEntryInfo("key2"); found {
+		t.Errorf("Metadata test (missing key) failed. Expected: not found")
+	}
+}
+
+func TestBiCache_Validator(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+	errEmpty := errors.New("empty value")
+	cache.SetValidator(func(key interface{}, value interface{}) error {
+		if value.(string) == "" {
+			return errEmpty
+		}
+		return nil
+	})
+
+	// Set a valid and an invalid value
+	if err := cache.SetWithOptions("key1", "value1", time.Second*20); err != nil {
+		t.Errorf("Validator test (valid value) failed. Unexpected error: %v", err)
+	}
+	if err := cache.SetWithOptions("key2", "", time.Second*20); !errors.Is(err, errEmpty) {
+		t.Errorf("Validator test (invalid value) failed. Expected: '%v', Got: '%v'", errEmpty, err)
+	}
+
+	// The invalid value must not be stored
+	if result, found := cache.Get("key2"); found {
+		t.Errorf("Validator test (invalid value) failed. Expected: not found, Got: '%v'", result)
+	}
+
+	// Rejections are counted separately from encode errors
+	metrics := cache.GetMetrics()
+	if metrics.ValidationError != 1 || metrics.SetError != 0 || metrics.SetSuccess != 1 {
+		t.Errorf("Validator test failed. Expected: ValidationError=1, SetError=0, SetSuccess=1. Got: ValidationError=%v, SetError=%v, SetSuccess=%v",
+			metrics.ValidationError, metrics.SetError, metrics.SetSuccess)
+	}
+}
+
+func TestBiCache_NilValue(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+	cache.SetCompression(func(data []byte) ([]byte, error) {
+		return data, nil
+	}, func(data []byte) ([]byte, error) {
+		return data, nil
+	})
+
+	// Set a nil value in the cache
+	cache.Set("key1", nil, time.Second*20)
+
+	// A cached nil must be distinguishable from a miss
+	result, found := cache.Get("key1")
+	if !found || result != nil {
+		t.Errorf("Nil value test failed. Expected: found nil, Got: '%v' (found=%v)", result, found)
+	}
+
+	entry, found := cache.GetEntry("key1")
+	if !found || entry.Value != nil {
+		t.Errorf("Nil value test (GetEntry) failed. Expected: found nil, Got: '%v' (found=%v)", entry.Value, found)
+	}
+
+	// A missing key is not found
+	if _, found := cache.GetEntry("key2"); found {
+		t.Errorf("Nil value test (missing key) failed. Expected: not found")
+	}
+}
+
+func TestBiCache_GetWithInfo(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+	cache.RegisterLoader("loaded:", func(key interface{}) (interface{}, time.Duration, error) {
+		return "value", time.Minute, nil
+	})
+
+	// Set a value that becomes stale before it expires
+	cache.SetWithOptions("key1", "value1", time.Second*20, WithSoftTTL(time.Millisecond*50))
+
+	result, info, found := cache.GetWithInfo("key1")
+	if !found || result != "value1" || info.Stale || info.Source != TierMemory {
+		t.Errorf("GetWithInfo test failed. Expected: fresh 'value1' from memory, Got: '%v' %+v", result, info)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+	result, info, found = cache.GetWithInfo("key1")
+	if !found || result != "value1" || !info.Stale || info.Age < time.Millisecond*60 {
+		t.Errorf("GetWithInfo test (stale) failed. Expected: stale 'value1', Got: '%v' %+v", result, info)
+	}
+
+	// Loaded values report the loader as their source
+	if _, info, found := cache.GetWithInfo("loaded:1"); !found || info.Source != TierLoader {
+		t.Errorf("GetWithInfo test (loader) failed. Expected: source loader, Got: %v", info.Source)
+	}
+}
+
+func TestBiCache_DeleteAfter(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+
+	if !cache.DeleteAfter("key1", 50*time.Millisecond) {
+		t.Errorf("DeleteAfter test failed. Expected: key1 found")
+	}
+
+	// The entry is still served during the grace period, flagged stale
+	result, info, found := cache.GetWithInfo("key1")
+	if !found || result != "value1" || !info.Stale {
+		t.Errorf("DeleteAfter test failed. Expected: stale 'value1', Got: '%v' (found=%v, stale=%v)", result, found, info.Stale)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, found := cache.Get("key1"); found {
+		t.Errorf("DeleteAfter test failed. Expected: key1 deleted after the grace period")
+	}
+
+	// Writing the key again cancels the deletion
+	cache.Set("key2", "value2", 0)
+	cache.DeleteAfter("key2", 50*time.Millisecond)
+	cache.Set("key2", "value3", 0)
+	time.Sleep(60 * time.Millisecond)
+	if result, found := cache.Get("key2"); !found || result != "value3" {
+		t.Errorf("DeleteAfter test failed. Expected: 'value3', Got: '%v'", result)
+	}
+
+	if cache.DeleteAfter("missing", time.Second) {
+		t.Errorf("DeleteAfter test failed. Expected: missing key not found")
+	}
+}
+
+func TestBiCache_Writer(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	cache.SetWithOptions("key1", "value", 0, WithWriter("billing"))
+	if info, _ := cache.GetEntryInfo("key1"); info.Writer != "billing" {
+		t.Errorf("Writer test failed. Expected: 'billing', Got: '%v'", info.Writer)
+	}
+
+	// The writer can come from the context, including for loaded entries
+	ctx := ContextWithWriter(context.Background(), "search")
+	cache.SetContext(ctx, "key2", "value", 0)
+	if info, _ := cache.GetEntryInfo("key2"); info.Writer != "search" {
+		t.Errorf("Writer test (context) failed. Expected: 'search', Got: '%v'", info.Writer)
+	}
+
+	cache.RegisterLoader("user:", func(key interface{}) (interface{}, time.Duration, error) {
+		return "loaded", 0, nil
+	})
+	cache.GetContext(ctx, "user:1")
+	if info, _ := cache.GetEntryInfo("user:1"); info.Writer != "search" {
+		t.Errorf("Writer test (loader) failed. Expected: 'search', Got: '%v'", info.Writer)
+	}
 
-	// Check if the event was received correctly
-	if !eventReceived || receivedKey != "key1" || receivedEvent != CacheEventSet || receivedEntry.Value != "value1" {
-		t.Errorf("CacheEventHandler test failed. Event not received or incorrect values. Received: key=%v, event=%v, entry=%v",
-			receivedKey, receivedEvent, receivedEntry)
+	// An explicit writer wins over the context
+	cache.SetContext(ctx, "key3", "value", 0, WithWriter("billing"))
+	if info, _ := cache.GetEntryInfo("key3"); info.Writer != "billing" {
+		t.Errorf("Writer test (override) failed. Expected: 'billing', Got: '%v'", info.Writer)
 	}
 }
@@ -0,0 +1,64 @@
+package bicache
+
+// ZeroCapacity selects what a capacity of 0 means.
+type ZeroCapacity int
+
+const (
+	// ZeroCapacityUnbounded lets a cache of capacity 0 hold any number of entries,
+	// limited only by its maximum weight and memory.
+	ZeroCapacityUnbounded ZeroCapacity = iota
+	// ZeroCapacityDisabled makes a cache of capacity 0 store nothing, so every Get misses and
+	// is loaded if a loader is registered. Writes still reach the writer and write-behind store.
+	ZeroCapacityDisabled
+)
+
+func (z ZeroCapacity) String() string {
+	switch z {
+	case ZeroCapacityUnbounded:
+		return "unbounded"
+	case ZeroCapacityDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
+// SetZeroCapacity selects what a capacity of 0 means. ZeroCapacityUnbounded is the default.
+// Switching a cache of capacity 0 to ZeroCapacityDisabled removes its entries.
+func (c *BiCache) SetZeroCapacity(mode ZeroCapacity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.zeroCapacity = mode
+	if c.storesNothing() {
+		c.clearEntries()
+	}
+}
+
+// storesNothing reports whether the cache is in passthrough mode, or has capacity 0 and is disabled
+// by it. The caller must hold the lock.
+func (c *BiCache) storesNothing() bool {
+	return c.passthrough.Load() || c.capacity == 0 && c.zeroCapacity == ZeroCapacityDisabled
+}
+
+// clearEntries removes all entries, counting them as evicted. The caller must hold the write lock.
+func (c *BiCache) clearEntries() {
+	for stored := range c.cacheMap {
+		c.evictEntry(c.externalKey(stored))
+	}
+}
+
+// clampCapacity treats a negative capacity as 0. NewFromConfig reports it as a *ConfigError instead.
+func clampCapacity(capacity int) int {
+	if capacity < 0 {
+		return 0
+	}
+	return capacity
+}
+
+// SetZeroCapacity selects what a capacity of 0 means on every shard.
+func (c *ShardedBiCache) SetZeroCapacity(mode ZeroCapacity) {
+	for _, shard := range c.shards {
+		shard.SetZeroCapacity(mode)
+	}
+}
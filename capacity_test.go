@@ -0,0 +1,80 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_ZeroCapacity(t *testing.T) {
+	cache := NewBiCache(0, time.Minute)
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i, 0)
+	}
+	if count := cache.GetMetrics().EntriesCount; count != 100 {
+		t.Errorf("ZeroCapacity test failed. Expected: 100 entries, Got: %d", count)
+	}
+	if evictions := cache.GetMetrics().Evictions; evictions != 0 {
+		t.Errorf("ZeroCapacity test failed. Expected: no evictions, Got: %d", evictions)
+	}
+
+	// A disabled cache drops its entries and stores nothing, but still loads
+	cache.SetZeroCapacity(ZeroCapacityDisabled)
+	if count := cache.GetMetrics().EntriesCount; count != 0 {
+		t.Errorf("ZeroCapacity test failed. Expected: 0 entries, Got: %d", count)
+	}
+	loads := 0
+	cache.SetLoader(func(key interface{}) (interface{}, time.Duration, error) {
+		loads++
+		return "loaded", 0, nil
+	})
+	cache.Set("key1", "value1", 0)
+	for i := 0; i < 2; i++ {
+		if value, _ := cache.Get("key1"); value != "loaded" {
+			t.Errorf("ZeroCapacity test failed. Expected: loaded, Got: %v", value)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("ZeroCapacity test failed. Expected: 2 loads, Got: %d", loads)
+	}
+
+	// A positive capacity enables the cache again
+	cache.SetCapacity(1)
+	cache.Set("key2", "value2", 0)
+	if value, _ := cache.Get("key2"); value != "value2" {
+		t.Errorf("ZeroCapacity test failed. Expected: value2, Got: %v", value)
+	}
+}
+
+func TestBiCache_NegativeCapacity(t *testing.T) {
+	cache := NewBiCache(-1, time.Minute)
+	cache.Set("key1", "value1", 0)
+	if status := cache.status(); status.Capacity != 0 || status.Entries != 1 {
+		t.Errorf("NegativeCapacity test failed. Expected: capacity 0 with 1 entry, Got: %+v", status)
+	}
+
+	cache.SetCapacity(-5)
+	if status := cache.status(); status.Capacity != 0 || status.Entries != 1 {
+		t.Errorf("NegativeCapacity test failed. Expected: capacity 0 with 1 entry, Got: %+v", status)
+	}
+
+	if _, err := NewFromConfig(Config{Capacity: -1, CleanupInterval: time.Minute}); err == nil {
+		t.Errorf("NegativeCapacity test failed. Expected: config error")
+	}
+}
+
+func TestNewFromConfig_ZeroCapacity(t *testing.T) {
+	cache, err := NewFromConfig(Config{CleanupInterval: time.Minute, ZeroCapacity: ZeroCapacityDisabled})
+	if err != nil {
+		t.Fatalf("ZeroCapacity config test failed. Unexpected error: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", "value1", 0)
+	if cache.Has("key1") {
+		t.Errorf("ZeroCapacity config test failed. Expected: nothing stored")
+	}
+
+	if _, err := NewFromConfig(Config{CleanupInterval: time.Minute, ZeroCapacity: 5}); err == nil {
+		t.Errorf("ZeroCapacity config test failed. Expected: unknown mode reported")
+	}
+}
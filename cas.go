@@ -0,0 +1,79 @@
+package bicache
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// CompareAndSwap stores newValue under key only if the live entry stored there holds oldValue,
+// compared with reflect.DeepEqual, and reports whether it did. The comparison and the write happen
+// under one lock, so concurrent writers can make optimistic updates by retrying with the value they
+// read. A missing or expired entry never matches. Like the other conditional writes, it is not
+// passed to the writer set with SetWriter.
+func (c *BiCache) CompareAndSwap(key, oldValue, newValue interface{}, expiration time.Duration, opts ...SetOption) bool {
+	options := newSetOptions(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() || !c.holds(key, oldValue) {
+		return false
+	}
+	stored, _ := c.tryStore(context.Background(), key, newValue, expiration, options)
+	return stored
+}
+
+// CompareAndDelete deletes the entry stored under key only if it is live and holds expected,
+// compared like CompareAndSwap, and reports whether it did. It releases lock-like entries created
+// with Add without removing one that another holder stored after this one expired. Like the
+// conditional writes, the deletion is not passed to the dual-write or write-behind store.
+func (c *BiCache) CompareAndDelete(key, expected interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() || !c.holds(key, expected) {
+		return false
+	}
+
+	c.removeEntry(key, removalDeleted)
+	c.recordVersion(key, nil, true, time.Now())
+	c.emit(CacheEventDelete, key, CacheEntry{})
+	return true
+}
+
+// holds reports whether the live entry stored under key holds value. The caller must hold the lock.
+func (c *BiCache) holds(key, value interface{}) bool {
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, time.Now()) {
+		return false
+	}
+
+	current, err := c.decodeStored(entry.Value)
+	if err != nil {
+		c.metrics.DecodeError.Add(1)
+		c.reportError("get", key, err)
+		return false
+	}
+	return reflect.DeepEqual(current, value)
+}
+
+// CompareAndSwap stores newValue under key if the entry stored there holds oldValue.
+func (c *ShardedBiCache) CompareAndSwap(key, oldValue, newValue interface{}, expiration time.Duration, opts ...SetOption) bool {
+	return c.Shard(key).CompareAndSwap(key, oldValue, newValue, expiration, opts...)
+}
+
+// CompareAndDelete deletes the entry stored under key if it holds expected.
+func (c *ShardedBiCache) CompareAndDelete(key, expected interface{}) bool {
+	return c.Shard(key).CompareAndDelete(key, expected)
+}
+
+// CompareAndSwap is the typed variant of BiCache.CompareAndSwap.
+func (c *TypedBiCache[K, V]) CompareAndSwap(key K, oldValue, newValue V, expiration time.Duration, opts ...SetOption) bool {
+	return c.cache.CompareAndSwap(key, oldValue, newValue, expiration, opts...)
+}
+
+// CompareAndDelete is the typed variant of BiCache.CompareAndDelete.
+func (c *TypedBiCache[K, V]) CompareAndDelete(key K, expected V) bool {
+	return c.cache.CompareAndDelete(key, expected)
+}
@@ -0,0 +1,107 @@
+package bicache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_CompareAndSwap(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	if cache.CompareAndSwap("key1", nil, "value1", 0) {
+		t.Errorf("CompareAndSwap test failed. Expected: missing key not swapped")
+	}
+
+	cache.Set("key1", []string{"a"}, 0)
+	if cache.CompareAndSwap("key1", []string{"b"}, []string{"c"}, 0) {
+		t.Errorf("CompareAndSwap test failed. Expected: mismatched value not swapped")
+	}
+	if !cache.CompareAndSwap("key1", []string{"a"}, []string{"a", "b"}, 0) {
+		t.Errorf("CompareAndSwap test failed. Expected: matching value swapped")
+	}
+	if value, _ := cache.Get("key1"); len(value.([]string)) != 2 {
+		t.Errorf("CompareAndSwap test failed. Expected: [a b], Got: %v", value)
+	}
+
+	// Read-only entries are not swapped
+	cache.SetWithOptions("key2", "locked", 0, WithReadOnly())
+	if cache.CompareAndSwap("key2", "locked", "other", 0) {
+		t.Errorf("CompareAndSwap test failed. Expected: read-only entry not swapped")
+	}
+
+	// Entries dropped by the cache policy are not reported as swapped
+	cache.SetCachePolicy(func(key interface{}, entry CacheEntry) bool { return key != "key1" })
+	if cache.CompareAndSwap("key1", []string{"a", "b"}, []string{"c"}, 0) {
+		t.Errorf("CompareAndSwap test failed. Expected: rejected value not swapped")
+	}
+	cache.SetCachePolicy(nil)
+
+	// Concurrent optimistic increments don't lose updates
+	cache.Set("counter", 0, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value, _ := cache.Get("counter")
+				if cache.CompareAndSwap("counter", value, value.(int)+1, 0) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if value, _ := cache.Get("counter"); value != 20 {
+		t.Errorf("CompareAndSwap test failed. Expected: 20, Got: %v", value)
+	}
+}
+
+func TestBiCache_CompareAndDelete(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	if !cache.Add("lock", "owner1", time.Millisecond*20) {
+		t.Fatalf("CompareAndDelete test failed. Expected: lock acquired")
+	}
+	if cache.CompareAndDelete("lock", "owner2") {
+		t.Errorf("CompareAndDelete test failed. Expected: lock of another owner kept")
+	}
+
+	// After the lock expired and was taken over, the first owner can't release it
+	time.Sleep(time.Millisecond * 30)
+	if !cache.Add("lock", "owner2", time.Minute) {
+		t.Fatalf("CompareAndDelete test failed. Expected: expired lock taken over")
+	}
+	if cache.CompareAndDelete("lock", "owner1") {
+		t.Errorf("CompareAndDelete test failed. Expected: lock of owner2 kept")
+	}
+	if !cache.CompareAndDelete("lock", "owner2") || cache.Has("lock") {
+		t.Errorf("CompareAndDelete test failed. Expected: lock released")
+	}
+	if cache.CompareAndDelete("lock", "owner2") {
+		t.Errorf("CompareAndDelete test failed. Expected: missing lock not deleted")
+	}
+	if deletions := cache.GetMetrics().Deletions; deletions != 1 {
+		t.Errorf("CompareAndDelete test failed. Expected: 1 deletion, Got: %d", deletions)
+	}
+}
+
+func TestBiCache_CompareAndDeleteWriteBehind(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	store := &testWriteBehindStore{mutations: make(map[interface{}]interface{})}
+	cache.SetWriteBehind(WriteBehindConfig{Store: store, FlushInterval: time.Hour})
+
+	if !cache.Add("lock", "owner1", time.Minute) || !cache.CompareAndDelete("lock", "owner1") {
+		t.Fatalf("CompareAndDelete write-behind test failed. Expected: lock acquired and released")
+	}
+	if queued := cache.GetMetrics().WriteBehindQueued; queued != 0 {
+		t.Errorf("CompareAndDelete write-behind test failed. Expected: nothing queued, Got: %d", queued)
+	}
+
+	cache.Close()
+	if len(store.batches) != 0 {
+		t.Errorf("CompareAndDelete write-behind test failed. Expected: nothing written, Got: %v", store.batches)
+	}
+}
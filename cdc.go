@@ -0,0 +1,87 @@
+package bicache
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ChangeEvent is a change-data-capture message in the Debezium envelope format.
+type ChangeEvent struct {
+	Op     string                 `json:"op"`
+	Before map[string]interface{} `json:"before"`
+	After  map[string]interface{} `json:"after"`
+	Source map[string]interface{} `json:"source"`
+	TsMs   int64                  `json:"ts_ms"`
+}
+
+// Table returns the table the change was made in, as reported by the source block.
+func (e ChangeEvent) Table() string {
+	table, _ := e.Source["table"].(string)
+	return table
+}
+
+// ChangeKeyMapper maps a change event to the cache keys derived from the changed row.
+type ChangeKeyMapper func(event ChangeEvent) []interface{}
+
+// CDCConsumer invalidates cache entries from change-data-capture messages.
+type CDCConsumer struct {
+	cache  *BiCache
+	mapper ChangeKeyMapper
+}
+
+func NewCDCConsumer(cache *BiCache, mapper ChangeKeyMapper) *CDCConsumer {
+	return &CDCConsumer{cache: cache, mapper: mapper}
+}
+
+// Apply decodes a Debezium JSON message, with or without the schema wrapper, and deletes the mapped keys.
+// Tombstone messages are ignored.
+func (c *CDCConsumer) Apply(message []byte) error {
+	if len(message) == 0 || string(message) == "null" {
+		return nil
+	}
+
+	var envelope struct {
+		Payload *ChangeEvent `json:"payload"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return err
+	}
+
+	event := envelope.Payload
+	if event == nil {
+		event = &ChangeEvent{}
+		if err := json.Unmarshal(message, event); err != nil {
+			return err
+		}
+	}
+
+	c.ApplyEvent(*event)
+	return nil
+}
+
+// ApplyEvent deletes the keys mapped from event and returns how many keys were invalidated.
+func (c *CDCConsumer) ApplyEvent(event ChangeEvent) int {
+	keys := c.mapper(event)
+	for _, key := range keys {
+		c.cache.Delete(key)
+	}
+	return len(keys)
+}
+
+// Consume applies messages until the channel is closed or ctx is done.
+// Messages that cannot be decoded are passed to onError if it is not nil.
+func (c *CDCConsumer) Consume(ctx context.Context, messages <-chan []byte, onError func(message []byte, err error)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			if err := c.Apply(message); err != nil && onError != nil {
+				onError(message, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package bicache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCDCConsumer_Apply(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+	cache.Set("user:1", "alice", time.Minute)
+	cache.Set("user:2", "bob", time.Minute)
+
+	consumer := NewCDCConsumer(cache, func(event ChangeEvent) []interface{} {
+		if event.Table() != "users" {
+			return nil
+		}
+		row := event.After
+		if event.Op == "d" {
+			row = event.Before
+		}
+		return []interface{}{fmt.Sprintf("user:%v", row["id"])}
+	})
+
+	// An update wrapped in the schema envelope
+	update := []byte(`{"schema":{},"payload":{"op":"u","before":{"id":1},"after":{"id":1},"source":{"table":"users"}}}`)
+	if err := consumer.Apply(update); err != nil {
+		t.Fatalf("CDCConsumer test failed. Unexpected error: %v", err)
+	}
+	if _, found := cache.Get("user:1"); found {
+		t.Errorf("CDCConsumer test (update) failed. Expected: 'user:1' invalidated")
+	}
+
+	// A bare delete event, a tombstone and a malformed message sent through Consume
+	messages := make(chan []byte, 3)
+	messages <- []byte(`{"op":"d","before":{"id":2},"after":null,"source":{"table":"users"}}`)
+	messages <- []byte("null")
+	messages <- []byte("{")
+	close(messages)
+
+	var failed int
+	err := consumer.Consume(context.Background(), messages, func(message []byte, err error) {
+		failed++
+	})
+	if err != nil || failed != 1 {
+		t.Errorf("CDCConsumer test (consume) failed. Expected: 1 failed message, Got: %v (err=%v)", failed, err)
+	}
+	if _, found := cache.Get("user:2"); found {
+		t.Errorf("CDCConsumer test (delete) failed. Expected: 'user:2' invalidated")
+	}
+}
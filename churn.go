@@ -0,0 +1,76 @@
+package bicache
+
+import "time"
+
+// removalReason tells why an entry was removed, for the lifetime metrics.
+type removalReason int
+
+const (
+	removalDeleted removalReason = iota
+	removalExpired
+	removalEvicted
+)
+
+// EntryLifetimes holds the mean time entries stayed in the cache between being written and
+// being removed, by the reason they were removed for.
+type EntryLifetimes struct {
+	Evicted     time.Duration
+	Expired     time.Duration
+	Deleted     time.Duration
+	Overwritten time.Duration
+}
+
+// AverageLifetimes returns the mean lifetime of the removed entries by removal reason.
+// Deleted includes entries removed by a namespace invalidation or a failed decode.
+func (m CacheMetrics) AverageLifetimes() EntryLifetimes {
+	return EntryLifetimes{
+		Evicted:     averageDuration(m.EvictedLifetime, m.Evictions),
+		Expired:     averageDuration(m.ExpiredLifetime, m.Expirations),
+		Deleted:     averageDuration(m.DeletedLifetime, m.Deletions),
+		Overwritten: averageDuration(m.OverwriteLifetime, m.Overwrites),
+	}
+}
+
+// ReuseRatio returns the number of hits per write. A ratio near zero means entries are
+// written but hardly ever read.
+func (m CacheMetrics) ReuseRatio() float64 {
+	writes := m.Inserts + m.Overwrites
+	if writes == 0 {
+		return 0
+	}
+	return float64(m.Hits) / float64(writes)
+}
+
+func averageDuration(total time.Duration, count int64) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+// recordWrite counts a write of key as an insert or an overwrite. The caller must hold the write lock.
+func (c *BiCache) recordWrite(key interface{}, now time.Time) {
+	current, exists := c.cacheMap[c.mapKey(key)]
+	if !exists {
+		c.metrics.Inserts.Add(1)
+		return
+	}
+	c.metrics.Overwrites.Add(1)
+	c.metrics.OverwriteLifetime.Add(int64(now.Sub(current.created)))
+}
+
+// recordRemoval counts the removal of entry and its lifetime for reason.
+func (c *BiCache) recordRemoval(entry CacheEntry, reason removalReason, now time.Time) {
+	lifetime := int64(now.Sub(entry.created))
+	switch reason {
+	case removalEvicted:
+		c.metrics.Evictions.Add(1)
+		c.metrics.EvictedLifetime.Add(lifetime)
+	case removalExpired:
+		c.metrics.Expirations.Add(1)
+		c.metrics.ExpiredLifetime.Add(lifetime)
+	default:
+		c.metrics.Deletions.Add(1)
+		c.metrics.DeletedLifetime.Add(lifetime)
+	}
+}
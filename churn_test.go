@@ -0,0 +1,38 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_ChurnMetrics(t *testing.T) {
+	cache := NewBiCache(2, time.Minute)
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	time.Sleep(10 * time.Millisecond)
+	cache.Set("key1", "value1b", 0)
+	cache.Delete("key2")
+	cache.Set("key3", "value3", 0)
+	cache.Set("key4", "value4", 0)
+	cache.Get("key4")
+
+	metrics := cache.GetMetrics()
+	if metrics.Inserts != 4 || metrics.Overwrites != 1 {
+		t.Errorf("Churn metrics test failed. Expected: 4 inserts and 1 overwrite, Got: %d and %d", metrics.Inserts, metrics.Overwrites)
+	}
+	if metrics.Deletions != 1 || metrics.Evictions != 1 {
+		t.Errorf("Churn metrics test failed. Expected: 1 deletion and 1 eviction, Got: %d and %d", metrics.Deletions, metrics.Evictions)
+	}
+
+	lifetimes := metrics.AverageLifetimes()
+	if lifetimes.Overwritten < 10*time.Millisecond || lifetimes.Deleted < 10*time.Millisecond {
+		t.Errorf("Churn metrics test failed. Expected: lifetimes of at least 10ms, Got: %+v", lifetimes)
+	}
+	if lifetimes.Expired != 0 {
+		t.Errorf("Churn metrics test failed. Expected: no expired lifetime, Got: %v", lifetimes.Expired)
+	}
+	if ratio := metrics.ReuseRatio(); ratio != 0.2 {
+		t.Errorf("Churn metrics test failed. Expected: reuse ratio 0.2, Got: %v", ratio)
+	}
+}
@@ -0,0 +1,35 @@
+package bicache
+
+import "time"
+
+// CleanupReport describes a cleanup run.
+type CleanupReport struct {
+	// Scanned is the number of entries checked.
+	Scanned int
+	// Expired is the number of entries removed because they expired.
+	Expired int
+	// Invalidated is the number of entries removed because their namespace was invalidated.
+	Invalidated int
+	// Evicted is the number of entries evicted afterwards because the cache was still over capacity.
+	Evicted int
+	// Duration is how long the run took, including waiting for the lock.
+	Duration time.Duration
+}
+
+// RunCleanup removes expired and invalidated entries right away, evicts entries if the cache is
+// still over capacity, and reports what was done. It does not wait for or reset the periodic cleanup.
+func (c *BiCache) RunCleanup() CleanupReport {
+	start := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := c.cleanup()
+	if c.overCapacity() {
+		evictions := c.metrics.Evictions.Load()
+		c.evict(nil)
+		report.Evicted = int(c.metrics.Evictions.Load() - evictions)
+	}
+	report.Duration = time.Since(start)
+	return report
+}
@@ -0,0 +1,25 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_RunCleanup(t *testing.T) {
+	cache := NewBiCache(10, time.Hour)
+
+	cache.Set("key1", "value1", time.Millisecond)
+	cache.Set("key2", "value2", time.Millisecond)
+	cache.Set("user:1", "value3", 0)
+	cache.Set("key4", "value4", 0)
+	cache.InvalidateNamespace("user:")
+	time.Sleep(5 * time.Millisecond)
+
+	report := cache.RunCleanup()
+	if report.Scanned != 4 || report.Expired != 2 || report.Invalidated != 1 || report.Evicted != 0 {
+		t.Errorf("RunCleanup test failed. Expected: 4 scanned, 2 expired, 1 invalidated, Got: %+v", report)
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 1 {
+		t.Errorf("RunCleanup test failed. Expected: 1 entry left, Got: %v", metrics.EntriesCount)
+	}
+}
@@ -0,0 +1,82 @@
+package bicache
+
+import "context"
+
+// ClearOption configures Clear.
+type ClearOption func(*clearOptions)
+
+type clearOptions struct {
+	deleteEvents bool
+}
+
+// WithDeleteEvents makes Clear emit a CacheEventDelete per removed key instead of a single CacheEventClear.
+func WithDeleteEvents() ClearOption {
+	return func(o *clearOptions) {
+		o.deleteEvents = true
+	}
+}
+
+// Clear removes all entries at once and emits a single CacheEventClear with a nil key.
+// Metrics other than the entry count, weight and memory are kept.
+func (c *BiCache) Clear(opts ...ClearOption) {
+	c.ClearContext(context.Background(), opts...)
+}
+
+// ClearContext is like Clear and passes ctx on to the event handlers.
+func (c *BiCache) ClearContext(ctx context.Context, opts ...ClearOption) {
+	var options clearOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return
+	}
+
+	var cleared []interface{}
+	if options.deleteEvents {
+		cleared = make([]interface{}, 0, len(c.cacheMap))
+		for key := range c.cacheMap {
+			cleared = append(cleared, c.externalKey(key))
+		}
+	}
+
+	c.cacheMap = make(map[interface{}]CacheEntry)
+	c.eviction = c.newEvictor(c.evictionPolicy)
+	c.window = newLRUList()
+	c.reads.drain()
+	c.metrics.EntriesCount.Store(0)
+	c.metrics.TotalWeight.Store(0)
+	c.metrics.MemoryBytes.Store(0)
+	if hashing := c.keyHashing.Load(); hashing != nil && hashing.keys != nil {
+		hashing.keys = make(map[HashedKey]string)
+	}
+	if hot := c.hot.Load(); hot != nil {
+		hot.remove(func(key interface{}) bool {
+			return true
+		})
+	}
+
+	if !options.deleteEvents {
+		c.emitContext(ctx, CacheEventClear, nil, CacheEntry{})
+		return
+	}
+	for _, key := range cleared {
+		c.emitContext(ctx, CacheEventDelete, key, CacheEntry{})
+	}
+}
+
+// Clear clears every shard.
+func (c *ShardedBiCache) Clear(opts ...ClearOption) {
+	for _, shard := range c.shards {
+		shard.Clear(opts...)
+	}
+}
+
+// Clear removes all entries.
+func (c *TypedBiCache[K, V]) Clear(opts ...ClearOption) {
+	c.cache.Clear(opts...)
+}
@@ -0,0 +1,45 @@
+package bicache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Clear(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	var mu sync.Mutex
+	events := make(map[CacheEvent]int)
+	cache.AddCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[event]++
+	})
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Clear()
+
+	if _, found := cache.Get("key1"); found {
+		t.Errorf("Clear test failed. Expected: key1 removed")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 0 || metrics.MemoryBytes != 0 {
+		t.Errorf("Clear test failed. Expected: no entries, Got: %+v", metrics)
+	}
+
+	// The cache keeps working after a clear
+	cache.Set("key3", "value3", 0)
+	if value, found := cache.Get("key3"); !found || value != "value3" {
+		t.Errorf("Clear test failed. Expected: value3, Got: %v", value)
+	}
+
+	cache.Clear(WithDeleteEvents())
+	cache.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if events[CacheEventClear] != 1 || events[CacheEventDelete] != 1 {
+		t.Errorf("Clear test failed. Expected: one clear and one delete event, Got: %v", events)
+	}
+}
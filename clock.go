@@ -0,0 +1,133 @@
+package bicache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// clock approximates LRU with a reference bit per key. Reads only set the bit, under a read
+// lock. The hand sweeps the slots in order: keys with the bit set get a second chance and
+// have it cleared, and the first key without it is evicted.
+type clock struct {
+	mu    sync.RWMutex
+	slots []clockSlot
+	index map[interface{}]int
+	free  []int
+	hand  int
+}
+
+type clockSlot struct {
+	key        interface{}
+	used       bool
+	referenced uint32
+}
+
+func newClock() *clock {
+	return &clock{index: make(map[interface{}]int)}
+}
+
+func (c *clock) setCapacity(capacity int) {}
+
+// add inserts new keys with the reference bit cleared and marks existing keys as referenced.
+func (c *clock) add(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i, exists := c.index[key]; exists {
+		atomic.StoreUint32(&c.slots[i].referenced, 1)
+		return
+	}
+
+	slot := clockSlot{key: key, used: true}
+	if n := len(c.free); n > 0 {
+		i := c.free[n-1]
+		c.free = c.free[:n-1]
+		c.slots[i] = slot
+		c.index[key] = i
+		return
+	}
+	c.slots = append(c.slots, slot)
+	c.index[key] = len(c.slots) - 1
+}
+
+// touch sets the reference bit of key.
+func (c *clock) touch(key interface{}) {
+	c.mu.RLock()
+	i, exists := c.index[key]
+	if exists {
+		atomic.StoreUint32(&c.slots[i].referenced, 1)
+	}
+	c.mu.RUnlock()
+
+	if !exists {
+		c.add(key)
+	}
+}
+
+// remove frees the slot of key. If key is the one the hand would evict next,
+// the hand moves past it, clearing the reference bits it passes.
+func (c *clock) remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i, exists := c.index[key]
+	if !exists {
+		return
+	}
+
+	if sweep := c.sweep(1); len(sweep) > 0 && sweep[0] == i {
+		if c.allReferenced() {
+			// The hand went around once, clearing every bit
+			for j := range c.slots {
+				atomic.StoreUint32(&c.slots[j].referenced, 0)
+			}
+		} else {
+			for j := c.hand; j != i; j = (j + 1) % len(c.slots) {
+				atomic.StoreUint32(&c.slots[j].referenced, 0)
+			}
+		}
+		c.hand = (i + 1) % len(c.slots)
+	}
+
+	c.slots[i] = clockSlot{}
+	c.free = append(c.free, i)
+	delete(c.index, key)
+}
+
+// allReferenced reports whether every key has its reference bit set. The caller must hold the lock.
+func (c *clock) allReferenced() bool {
+	for i := range c.slots {
+		if c.slots[i].used && atomic.LoadUint32(&c.slots[i].referenced) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// victims returns up to n keys in the order the hand would evict them if nothing is read meanwhile.
+func (c *clock) victims(n int) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]interface{}, 0, n)
+	for _, i := range c.sweep(n) {
+		keys = append(keys, c.slots[i].key)
+	}
+	return keys
+}
+
+// sweep returns up to n slots in eviction order: unreferenced keys from the hand on,
+// then the referenced ones, which lose their second chance on the first pass.
+// The caller must hold the lock.
+func (c *clock) sweep(n int) []int {
+	slots := make([]int, 0, n)
+	for _, referenced := range []uint32{0, 1} {
+		for k := 0; k < len(c.slots) && len(slots) < n; k++ {
+			i := (c.hand + k) % len(c.slots)
+			if c.slots[i].used && atomic.LoadUint32(&c.slots[i].referenced) == referenced {
+				slots = append(slots, i)
+			}
+		}
+	}
+	return slots
+}
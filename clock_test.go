@@ -0,0 +1,34 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_ClockEviction(t *testing.T) {
+	cache := NewBiCache(3, time.Minute)
+	cache.SetEvictionPolicy(ClockEviction)
+
+	cache.Set("key1", "value", 0)
+	cache.Set("key2", "value", 0)
+	cache.Set("key3", "value", 0)
+
+	// key1 gets a second chance, so key2 is evicted
+	cache.Get("key1")
+	cache.Set("key4", "value", 0)
+	if _, found := cache.Get("key2"); found {
+		t.Errorf("ClockEviction test failed. Expected: key2 evicted")
+	}
+
+	// The hand continues from where it stopped, and key1 lost its second chance when it was passed
+	cache.Set("key5", "value", 0)
+	if victims := cache.WouldEvict(3); len(victims) != 3 || victims[0] != "key4" || victims[1] != "key1" || victims[2] != "key5" {
+		t.Errorf("ClockEviction test failed. Expected: [key4 key1 key5], Got: %v", victims)
+	}
+	if _, found := cache.Get("key3"); found {
+		t.Errorf("ClockEviction test failed. Expected: key3 evicted")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 3 {
+		t.Errorf("ClockEviction test failed. Expected: 3 entries, Got: %v", metrics.EntriesCount)
+	}
+}
@@ -0,0 +1,71 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// NewBiCacheWithContext is like NewBiCache, and closes the cache when ctx is done. This stops
+// the periodic cleanup and the dispatch of events, tying the cache to the application's lifecycle.
+func NewBiCacheWithContext(ctx context.Context, capacity int, cleanupInterval time.Duration) *BiCache {
+	cache := NewBiCache(capacity, cleanupInterval)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			cache.Close()
+		case <-cache.stopCleanup:
+		}
+	}()
+
+	return cache
+}
+
+// Close stops the periodic cleanup and waits for the event handlers of earlier operations to
+// return. Afterwards reads miss, writes fail and GetWithError returns ErrClosed.
+// Closing a closed cache returns ErrClosed.
+func (c *BiCache) Close() error {
+	c.mu.Lock()
+	if c.closed.Load() {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	c.closed.Store(true)
+	c.cleanupTicker.Stop()
+	close(c.stopCleanup)
+	dualWriter := c.dualWriter
+	c.dualWriter = nil
+	writeBehind := c.writeBehind
+	c.writeBehind = nil
+	c.mu.Unlock()
+
+	// No writes are queued once the cache is closed, flush the pending ones while their errors
+	// can still be reported
+	dualWriter.stop()
+	writeBehind.stop()
+
+	// Audit events and errors are dispatched without the lock, wait for the ones that saw the cache open
+	c.dispatchMu.Lock()
+	c.dispatchStopped = true
+	c.dispatchMu.Unlock()
+
+	// No events are queued once the cache is closed, so the pending ones can be waited for
+	c.pendingEvents.Wait()
+	return nil
+}
+
+// Close closes every shard.
+func (c *ShardedBiCache) Close() error {
+	var err error
+	for _, shard := range c.shards {
+		if closeErr := shard.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Close is the typed variant of BiCache.Close.
+func (c *TypedBiCache[K, V]) Close() error {
+	return c.cache.Close()
+}
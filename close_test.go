@@ -0,0 +1,62 @@
+package bicache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Close(t *testing.T) {
+	cache := NewBiCache(10, time.Millisecond)
+
+	var handled int32
+	cache.AddCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&handled, 1)
+	})
+	cache.Set("key1", "value1", 0)
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close test failed. Expected: nil, Got: %v", err)
+	}
+
+	// Pending events are delivered before Close returns
+	if atomic.LoadInt32(&handled) != 1 {
+		t.Errorf("Close test failed. Expected: 1 handled event, Got: %v", atomic.LoadInt32(&handled))
+	}
+
+	if _, err := cache.GetWithError("key1"); err != ErrClosed {
+		t.Errorf("Close test (get) failed. Expected: ErrClosed, Got: %v", err)
+	}
+	if err := cache.SetWithOptions("key2", "value2", 0); err != ErrClosed {
+		t.Errorf("Close test (set) failed. Expected: ErrClosed, Got: %v", err)
+	}
+	cache.Delete("key1")
+	if atomic.LoadInt32(&handled) != 1 {
+		t.Errorf("Close test (delete) failed. Expected: no events after Close")
+	}
+
+	if err := cache.Close(); err != ErrClosed {
+		t.Errorf("Close test (twice) failed. Expected: ErrClosed, Got: %v", err)
+	}
+}
+
+func TestNewBiCacheWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := NewBiCacheWithContext(ctx, 10, time.Minute)
+
+	cache.Set("key1", "value1", 0)
+	if _, found := cache.Get("key1"); !found {
+		t.Errorf("NewBiCacheWithContext test failed. Expected: key1 found")
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for cache.SetWithOptions("key2", "value2", 0) != ErrClosed {
+		if time.Now().After(deadline) {
+			t.Fatal("NewBiCacheWithContext test failed. Expected: cache closed after cancel")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,149 @@
+// Command bicachectl inspects bicache snapshot files written by BiCache.Export and runs
+// a cache as a sidecar server.
+//
+// Usage:
+//
+//	bicachectl diff OLD NEW
+//	bicachectl serve [-addr ADDR] [-config FILE | -capacity N -cleanup INTERVAL] [-snapshot FILE] [-token TOKEN]
+//
+// serve starts listening right away and reports ready on /readyz once the snapshot,
+// if any, was imported. A JSON or YAML config file, see bicache.LoadConfig, replaces
+// the capacity and cleanup flags; it must not configure shards. The token, which defaults
+// to the BICACHE_TOKEN environment variable so it doesn't show up in process lists, is
+// required as a bearer token by /cache/ and /metrics. See bicache.Sidecar for the HTTP protocol.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mtnmunuklu/bicache"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "diff":
+		if len(os.Args) != 4 {
+			usage()
+		}
+		err = diff(os.Stdout, os.Args[2], os.Args[3])
+	case "serve":
+		err = serve(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bicachectl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bicachectl diff OLD NEW")
+	fmt.Fprintln(os.Stderr, "       bicachectl serve [-addr ADDR] [-config FILE | -capacity N -cleanup INTERVAL] [-snapshot FILE] [-token TOKEN]")
+	os.Exit(2)
+}
+
+// diff prints the keys added, removed and changed between two snapshot files.
+func diff(w io.Writer, oldPath, newPath string) error {
+	old, err := readSnapshot(oldPath)
+	if err != nil {
+		return err
+	}
+	new, err := readSnapshot(newPath)
+	if err != nil {
+		return err
+	}
+
+	result := bicache.DiffSnapshots(old, new)
+	for _, d := range result.Added {
+		fmt.Fprintf(w, "+ %v (%d bytes)\n", d.Key, d.NewSize)
+	}
+	for _, d := range result.Removed {
+		fmt.Fprintf(w, "- %v (%d bytes)\n", d.Key, d.OldSize)
+	}
+	for _, d := range result.Changed {
+		fmt.Fprintf(w, "~ %v (%d -> %d bytes)\n", d.Key, d.OldSize, d.NewSize)
+	}
+	fmt.Fprintf(w, "added: %d, removed: %d, changed: %d, size delta: %+d bytes\n",
+		len(result.Added), len(result.Removed), len(result.Changed), result.SizeDelta)
+	return nil
+}
+
+// serve runs a sidecar cache server, warmed up from a snapshot file.
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	capacity := flags.Int("capacity", 10000, "maximum number of entries")
+	cleanup := flags.Duration("cleanup", time.Minute, "cleanup interval")
+	snapshot := flags.String("snapshot", "", "snapshot file to import before reporting ready")
+	configPath := flags.String("config", "", "JSON or YAML config file")
+	token := flags.String("token", os.Getenv("BICACHE_TOKEN"), "bearer token required by /cache/ and /metrics (default $BICACHE_TOKEN)")
+	flags.Parse(args)
+
+	cfg := bicache.Config{Capacity: *capacity, CleanupInterval: *cleanup}
+	if *configPath != "" {
+		var err error
+		if cfg, err = bicache.LoadConfig(*configPath); err != nil {
+			return err
+		}
+	}
+
+	// The sidecar serves a single cache
+	if cfg.Shards > 1 {
+		return fmt.Errorf("serve doesn't support sharded caches, the config sets %d shards", cfg.Shards)
+	}
+
+	cache, err := bicache.NewFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	sidecar := bicache.NewSidecar(cache)
+	sidecar.SetAuthToken(*token)
+	server := &http.Server{Addr: *addr, Handler: sidecar}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	err = sidecar.Warmup(context.Background(), func(ctx context.Context, cache *bicache.BiCache) error {
+		if *snapshot == "" {
+			return nil
+		}
+		f, err := os.Open(*snapshot)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return cache.Import(f)
+	})
+	if err != nil {
+		server.Close()
+		return fmt.Errorf("warmup: %w", err)
+	}
+
+	return <-serverErr
+}
+
+func readSnapshot(path string) ([]bicache.SnapshotEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return bicache.ReadSnapshot(f)
+}
@@ -0,0 +1,141 @@
+package bicache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config holds the settings of a cache created with NewFromConfig. Zero values select the defaults
+// of NewBiCache and the setters, except for CleanupInterval, which is required.
+type Config struct {
+	Capacity         int
+	ZeroCapacity     ZeroCapacity
+	CleanupInterval  time.Duration
+	GlobalExpiration time.Duration
+	EvictionPolicy   EvictionPolicy
+	// ProtectedRatio is the SLRU protected share, 0.8 if zero.
+	ProtectedRatio float64
+	// EvictionSamples is the number of entries compared by SampledEviction, 5 if zero.
+	EvictionSamples int
+	Admission       Admission
+	MaxWeight       int64
+	MaxMemory       int64
+	Weigher         WeigherFunc
+	Compression     CompressionFunc
+	Decompression   DecompressionFunc
+	DecodeFailure   DecodeFailure
+	ErrorHandler    ErrorHandlerFunc
+	// Shards is the number of shards created by NewShardedFromConfig, 1 if zero.
+	Shards int
+}
+
+// ConfigError describes an invalid setting of a Config.
+type ConfigError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("bicache: invalid config: %s %s", e.Field, e.Reason)
+}
+
+// Validate checks the settings and returns a *ConfigError for each invalid one, joined.
+func (cfg Config) Validate() error {
+	var errs []error
+	invalid := func(field, reason string) {
+		errs = append(errs, &ConfigError{Field: field, Reason: reason})
+	}
+
+	if cfg.Capacity < 0 {
+		invalid("Capacity", "must not be negative")
+	}
+	if cfg.ZeroCapacity.String() == "unknown" {
+		invalid("ZeroCapacity", fmt.Sprintf("%d is unknown", cfg.ZeroCapacity))
+	}
+	if cfg.CleanupInterval <= 0 {
+		invalid("CleanupInterval", "must be positive")
+	}
+	if cfg.GlobalExpiration < 0 {
+		invalid("GlobalExpiration", "must not be negative")
+	}
+	if cfg.EvictionPolicy.String() == "unknown" {
+		invalid("EvictionPolicy", fmt.Sprintf("%d is unknown", cfg.EvictionPolicy))
+	}
+	if cfg.ProtectedRatio < 0 || cfg.ProtectedRatio > 1 {
+		invalid("ProtectedRatio", "must be between 0 and 1")
+	}
+	if cfg.EvictionSamples < 0 {
+		invalid("EvictionSamples", "must not be negative")
+	}
+	if cfg.Admission.String() == "unknown" {
+		invalid("Admission", fmt.Sprintf("%d is unknown", cfg.Admission))
+	}
+	if cfg.MaxWeight < 0 {
+		invalid("MaxWeight", "must not be negative")
+	}
+	if cfg.MaxMemory < 0 {
+		invalid("MaxMemory", "must not be negative")
+	}
+	if (cfg.Compression == nil) != (cfg.Decompression == nil) {
+		invalid("Compression", "and Decompression must both be set or both be nil")
+	}
+	if cfg.Shards < 0 {
+		invalid("Shards", "must not be negative")
+	}
+	if cfg.DecodeFailure < DecodeFailureKeep || cfg.DecodeFailure > DecodeFailureError {
+		invalid("DecodeFailure", fmt.Sprintf("%d is unknown", cfg.DecodeFailure))
+	}
+	return errors.Join(errs...)
+}
+
+// NewFromConfig creates a cache with the settings of cfg, or returns the errors of cfg.Validate.
+func NewFromConfig(cfg Config) (*BiCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	cache := NewBiCache(cfg.Capacity, cfg.CleanupInterval)
+	cfg.apply(cache)
+	return cache, nil
+}
+
+// NewShardedFromConfig creates a sharded cache of cfg.Shards shards sharing cfg.Capacity evenly,
+// each with the other settings of cfg, or returns the errors of cfg.Validate.
+func NewShardedFromConfig(cfg Config) (*ShardedBiCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	cache := NewShardedBiCache(cfg.Shards, cfg.Capacity, cfg.CleanupInterval)
+	for _, shard := range cache.Shards() {
+		cfg.apply(shard)
+	}
+	return cache, nil
+}
+
+// apply applies the settings of cfg other than the capacity and cleanup interval to cache.
+func (cfg Config) apply(cache *BiCache) {
+	cache.SetZeroCapacity(cfg.ZeroCapacity)
+	cache.SetGlobalExpiration(cfg.GlobalExpiration)
+	if cfg.ProtectedRatio > 0 {
+		cache.SetProtectedRatio(cfg.ProtectedRatio)
+	}
+	if cfg.EvictionSamples > 0 {
+		cache.SetEvictionSamples(cfg.EvictionSamples)
+	}
+	cache.SetEvictionPolicy(cfg.EvictionPolicy)
+	cache.SetAdmission(cfg.Admission)
+	if cfg.Weigher != nil {
+		cache.SetWeigher(cfg.Weigher)
+	}
+	cache.SetMaxWeight(cfg.MaxWeight)
+	cache.SetMaxMemory(cfg.MaxMemory)
+	if cfg.Compression != nil {
+		cache.SetCompression(cfg.Compression, cfg.Decompression)
+	}
+	cache.SetDecodeFailure(cfg.DecodeFailure)
+	if cfg.ErrorHandler != nil {
+		cache.SetErrorHandler(cfg.ErrorHandler)
+	}
+}
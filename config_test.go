@@ -0,0 +1,102 @@
+package bicache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	cache, err := NewFromConfig(Config{
+		Capacity:        2,
+		CleanupInterval: time.Minute,
+		EvictionPolicy:  SLRUEviction,
+		Admission:       TinyLFUAdmission,
+	})
+	if err != nil {
+		t.Fatalf("NewFromConfig test failed. Unexpected error: %v", err)
+	}
+	defer cache.Close()
+
+	if status := cache.status(); status.Capacity != 2 || status.EvictionPolicy != "slru" || status.Admission != "tinylfu" {
+		t.Errorf("NewFromConfig test failed. Got: %+v", status)
+	}
+}
+
+func TestNewFromConfig_Invalid(t *testing.T) {
+	_, err := NewFromConfig(Config{
+		Capacity:    -1,
+		Compression: func(data []byte) ([]byte, error) { return data, nil },
+	})
+	if err == nil {
+		t.Fatal("NewFromConfig invalid test failed. Expected: error")
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Errorf("NewFromConfig invalid test failed. Expected: *ConfigError, Got: %T", err)
+	}
+	for _, field := range []string{"Capacity", "CleanupInterval", "Compression"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("NewFromConfig invalid test failed. Expected: %v reported, Got: %v", field, err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "cache.yaml")
+	os.WriteFile(yamlPath, []byte(`# cache settings
+capacity: 1000
+cleanup_interval: 30s
+global_expiration: "10m"
+eviction_policy: slru # scan resistant
+shards: 4
+compression: gzip
+`), 0o644)
+	jsonPath := filepath.Join(dir, "cache.json")
+	os.WriteFile(jsonPath, []byte(`{"capacity": 1000, "cleanup_interval": "30s", "global_expiration": "10m", "eviction_policy": "slru", "shards": 4, "compression": "gzip"}`), 0o644)
+
+	for _, path := range []string{yamlPath, jsonPath} {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig test (%s) failed. Unexpected error: %v", path, err)
+		}
+		if cfg.Capacity != 1000 || cfg.CleanupInterval != 30*time.Second || cfg.GlobalExpiration != 10*time.Minute ||
+			cfg.EvictionPolicy != SLRUEviction || cfg.Shards != 4 || cfg.Compression == nil {
+			t.Errorf("LoadConfig test (%s) failed. Got: %+v", path, cfg)
+		}
+	}
+
+	cfg, _ := LoadConfig(yamlPath)
+	cache, err := NewShardedFromConfig(cfg)
+	if err != nil || len(cache.Shards()) != 4 {
+		t.Fatalf("LoadConfig test failed. Expected: 4 shards, Got: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("key1", []byte("value1"), 0)
+	if value, found := cache.Get("key1"); !found || string(value.([]byte)) != "value1" {
+		t.Errorf("LoadConfig test (compression) failed. Expected: value1, Got: %v", value)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"unknown.yaml": "capacity: 10\ncleanup_interval: 1m\ncapacty: 20\n",
+		"nested.yaml":  "capacity: 10\ncleanup:\n  interval: 1m\n",
+		"policy.json":  `{"capacity": 10, "cleanup_interval": "1m", "eviction_policy": "mru"}`,
+		"invalid.json": `{"capacity": -1, "cleanup_interval": "1m"}`,
+		"cache.toml":   "capacity = 10\n",
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0o644)
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("LoadConfig invalid test (%s) failed. Expected: error", name)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package bicache
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fileConfig is the representation of a Config in a configuration file.
+type fileConfig struct {
+	Capacity         int     `json:"capacity"`
+	ZeroCapacity     string  `json:"zero_capacity"`
+	CleanupInterval  string  `json:"cleanup_interval"`
+	GlobalExpiration string  `json:"global_expiration"`
+	EvictionPolicy   string  `json:"eviction_policy"`
+	ProtectedRatio   float64 `json:"protected_ratio"`
+	EvictionSamples  int     `json:"eviction_samples"`
+	Admission        string  `json:"admission"`
+	MaxWeight        int64   `json:"max_weight"`
+	MaxMemory        int64   `json:"max_memory"`
+	Shards           int     `json:"shards"`
+	Compression      string  `json:"compression"`
+}
+
+// LoadConfig reads a Config from a JSON file, or from a YAML file if path ends in .yaml or .yml.
+// Keys are the snake_case names of the Config fields. Durations are strings such as "90s",
+// eviction policies, admissions and zero capacity modes are named as by their String methods, and compression
+// is "gzip" or "none". YAML files are read as a flat mapping of keys to scalar values.
+// The Config is validated like by NewFromConfig.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if data, err = yamlToJSON(data); err != nil {
+			return Config{}, fmt.Errorf("bicache: reading %s: %w", path, err)
+		}
+	case ".json":
+	default:
+		return Config{}, fmt.Errorf("bicache: reading %s: unsupported config format %q", path, ext)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil {
+		return Config{}, fmt.Errorf("bicache: reading %s: %w", path, err)
+	}
+
+	cfg, err := file.config()
+	if err != nil {
+		return Config{}, fmt.Errorf("bicache: reading %s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+func (f fileConfig) config() (Config, error) {
+	cfg := Config{
+		Capacity:        f.Capacity,
+		ProtectedRatio:  f.ProtectedRatio,
+		EvictionSamples: f.EvictionSamples,
+		MaxWeight:       f.MaxWeight,
+		MaxMemory:       f.MaxMemory,
+		Shards:          f.Shards,
+	}
+
+	var err error
+	if cfg.CleanupInterval, err = parseConfigDuration("cleanup_interval", f.CleanupInterval); err != nil {
+		return Config{}, err
+	}
+	if cfg.GlobalExpiration, err = parseConfigDuration("global_expiration", f.GlobalExpiration); err != nil {
+		return Config{}, err
+	}
+	if cfg.EvictionPolicy, err = parseEvictionPolicy(f.EvictionPolicy); err != nil {
+		return Config{}, err
+	}
+	if cfg.Admission, err = parseAdmission(f.Admission); err != nil {
+		return Config{}, err
+	}
+	if cfg.ZeroCapacity, err = parseZeroCapacity(f.ZeroCapacity); err != nil {
+		return Config{}, err
+	}
+
+	switch f.Compression {
+	case "", "none":
+	case "gzip":
+		cfg.Compression, cfg.Decompression = gzipCompress, gzipDecompress
+	default:
+		return Config{}, &ConfigError{Field: "Compression", Reason: fmt.Sprintf("%q is unknown", f.Compression)}
+	}
+	return cfg, nil
+}
+
+func parseConfigDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return d, nil
+}
+
+func parseEvictionPolicy(name string) (EvictionPolicy, error) {
+	if name == "" {
+		return LRUEviction, nil
+	}
+	for policy := LRUEviction; policy.String() != "unknown"; policy++ {
+		if policy.String() == name {
+			return policy, nil
+		}
+	}
+	return 0, &ConfigError{Field: "EvictionPolicy", Reason: fmt.Sprintf("%q is unknown", name)}
+}
+
+func parseAdmission(name string) (Admission, error) {
+	if name == "" {
+		return AdmitAll, nil
+	}
+	for admission := AdmitAll; admission.String() != "unknown"; admission++ {
+		if admission.String() == name {
+			return admission, nil
+		}
+	}
+	return 0, &ConfigError{Field: "Admission", Reason: fmt.Sprintf("%q is unknown", name)}
+}
+
+func parseZeroCapacity(name string) (ZeroCapacity, error) {
+	if name == "" {
+		return ZeroCapacityUnbounded, nil
+	}
+	for mode := ZeroCapacityUnbounded; mode.String() != "unknown"; mode++ {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, &ConfigError{Field: "ZeroCapacity", Reason: fmt.Sprintf("%q is unknown", name)}
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// yamlToJSON converts a flat YAML mapping of keys to scalars into a JSON object.
+// Nested mappings, lists and multi-line values are rejected.
+func yamlToJSON(data []byte) ([]byte, error) {
+	object := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(stripYAMLComment(text))
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if text[0] == ' ' || text[0] == '\t' || strings.HasPrefix(trimmed, "-") {
+			return nil, fmt.Errorf("line %d: only a flat mapping of keys to values is supported", line)
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key: value", line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("line %d: missing value for %s", line, key)
+		}
+		object[key] = yamlScalar(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(object)
+}
+
+// stripYAMLComment removes a comment that starts outside quotes.
+func stripYAMLComment(text string) string {
+	var quote rune
+	for i, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// yamlScalar returns quoted values as strings, and unquoted ones as numbers if they parse as one.
+func yamlScalar(value string) interface{} {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	return value
+}
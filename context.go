@@ -0,0 +1,80 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// ContextEventHandlerFunc is like CacheEventHandlerFunc and also receives the context of the call
+// that caused the event. Events the cache causes itself, such as evictions and expirations,
+// come with context.Background().
+type ContextEventHandlerFunc func(ctx context.Context, event CacheEvent, key interface{}, entry CacheEntry)
+
+// GetContext is like Get and passes ctx on to loaders, event handlers and the tracer,
+// so work caused by the call can be attributed to its request.
+func (c *BiCache) GetContext(ctx context.Context, key interface{}) (interface{}, bool) {
+	entry, _, err := c.lookup(ctx, key)
+	return entry.Value, err == nil
+}
+
+// SetContext is like SetWithOptions and passes ctx on to the event handlers.
+func (c *BiCache) SetContext(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) error {
+	return c.setThrough(ctx, key, value, expiration, opts...)
+}
+
+// DeleteContext is like Delete and passes ctx on to the event handlers.
+func (c *BiCache) DeleteContext(ctx context.Context, key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return
+	}
+	c.delete(ctx, key)
+}
+
+// delete removes the entry stored under key and passes the deletion on to the dual-write and
+// write-behind stores. The caller must hold the write lock.
+func (c *BiCache) delete(ctx context.Context, key interface{}) {
+	c.removeEntry(key, removalDeleted)
+	c.recordVersion(key, nil, true, time.Now())
+	c.queueDualWrite(ctx, dualWrite{key: key, delete: true})
+	if err := c.queueWriteBehind(Mutation{Key: key, Delete: true}); err != nil {
+		c.reportError("writebehind", key, err)
+	}
+
+	c.emitContext(ctx, CacheEventDelete, key, CacheEntry{})
+}
+
+// AddContextEventHandler registers an additional handler that receives the context of the call causing each event.
+func (c *BiCache) AddContextEventHandler(handler ContextEventHandlerFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.eventHandlers = append(c.eventHandlers, handler)
+}
+
+// detachedContext keeps the values of a context but is never canceled,
+// for work that outlives the call it was started by.
+type detachedContext struct {
+	context.Context
+}
+
+func withoutCancel(ctx context.Context) context.Context {
+	if ctx == context.Background() {
+		return ctx
+	}
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
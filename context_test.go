@@ -0,0 +1,61 @@
+package bicache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+func TestBiCache_ContextPropagation(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+
+	var loaderRequestID interface{}
+	cache.RegisterContextLoader("user:", func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		loaderRequestID = ctx.Value(requestIDKey{})
+		return LoaderResult{Value: "loaded", TTL: time.Minute}, nil
+	})
+
+	events := make(chan context.Context, 10)
+	cache.AddContextEventHandler(func(ctx context.Context, event CacheEvent, key interface{}, entry CacheEntry) {
+		events <- ctx
+	})
+
+	var span Span
+	cache.SetTracer(func(s Span) {
+		span = s
+	})
+
+	if result, found := cache.GetContext(ctx, "user:1"); !found || result != "loaded" {
+		t.Errorf("ContextPropagation test failed. Expected: 'loaded', Got: '%v'", result)
+	}
+	if loaderRequestID != "req-1" {
+		t.Errorf("ContextPropagation test failed. Expected: loader to get req-1, Got: %v", loaderRequestID)
+	}
+	if span.Context == nil || span.Context.Value(requestIDKey{}) != "req-1" {
+		t.Errorf("ContextPropagation test failed. Expected: span context with req-1")
+	}
+
+	// Event handlers run after the call returned, so they keep the values but not the cancellation
+	cancel()
+	select {
+	case eventCtx := <-events:
+		if eventCtx.Value(requestIDKey{}) != "req-1" || eventCtx.Err() != nil {
+			t.Errorf("ContextPropagation test failed. Expected: detached event context with req-1, Got: %v, %v", eventCtx.Value(requestIDKey{}), eventCtx.Err())
+		}
+	case <-time.After(time.Second):
+		t.Errorf("ContextPropagation test failed. Expected: set event")
+	}
+
+	cache.DeleteContext(context.WithValue(context.Background(), requestIDKey{}, "req-2"), "user:1")
+	select {
+	case eventCtx := <-events:
+		if eventCtx.Value(requestIDKey{}) != "req-2" {
+			t.Errorf("ContextPropagation test failed. Expected: delete event with req-2, Got: %v", eventCtx.Value(requestIDKey{}))
+		}
+	case <-time.After(time.Second):
+		t.Errorf("ContextPropagation test failed. Expected: delete event")
+	}
+}
@@ -0,0 +1,121 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// dualWriteQueueSize bounds the number of writes waiting to be copied to the dual-write store.
+const dualWriteQueueSize = 1024
+
+// ErrDualWriteQueueFull is reported to the error handler when a write could not be queued for
+// the dual-write store because too many are pending.
+var ErrDualWriteQueueFull = errors.New("bicache: dual-write queue is full")
+
+// DualWriteStore is an adapter to a cache or store being migrated away from, which receives
+// a copy of every write to the cache.
+type DualWriteStore interface {
+	Set(ctx context.Context, key interface{}, value interface{}, expiration time.Duration) error
+	Delete(ctx context.Context, key interface{}) error
+}
+
+// dualWrite is a write waiting to be copied to the dual-write store.
+type dualWrite struct {
+	ctx        context.Context
+	key        interface{}
+	value      interface{}
+	expiration time.Duration
+	delete     bool
+	queued     time.Time
+}
+
+type dualWriter struct {
+	store DualWriteStore
+	queue chan dualWrite
+	done  chan struct{}
+}
+
+// SetDualWrite copies every Set and Delete to store, in order, in the background. Failures of the
+// store never fail the cache write; they are passed to the error handler and counted in the
+// DualWriteErrors metric. DualWriteLag adds up how long writes waited to reach the store.
+// A nil store disables dual writes after the pending ones were copied.
+func (c *BiCache) SetDualWrite(store DualWriteStore) {
+	c.mu.Lock()
+	previous := c.dualWriter
+	c.dualWriter = nil
+	if store != nil && !c.closed.Load() {
+		c.dualWriter = &dualWriter{
+			store: store,
+			queue: make(chan dualWrite, dualWriteQueueSize),
+			done:  make(chan struct{}),
+		}
+		go c.dualWriter.run(c)
+	}
+	c.mu.Unlock()
+
+	previous.stop()
+}
+
+// AverageDualWriteLag returns the mean time writes took to reach the dual-write store.
+func (m CacheMetrics) AverageDualWriteLag() time.Duration {
+	if m.DualWrites == 0 {
+		return 0
+	}
+	return m.DualWriteLag / time.Duration(m.DualWrites)
+}
+
+// queueDualWrite queues a write for the dual-write store, if one is set. The caller must hold the write lock.
+func (c *BiCache) queueDualWrite(ctx context.Context, write dualWrite) {
+	if c.dualWriter == nil {
+		return
+	}
+
+	write.ctx = withoutCancel(ctx)
+	write.queued = time.Now()
+	select {
+	case c.dualWriter.queue <- write:
+	default:
+		c.metrics.DualWriteErrors.Add(1)
+		c.reportError("dualwrite", write.key, ErrDualWriteQueueFull)
+	}
+}
+
+// run copies queued writes to the store until the queue is closed.
+func (w *dualWriter) run(c *BiCache) {
+	defer close(w.done)
+
+	for write := range w.queue {
+		var err error
+		if write.delete {
+			err = w.store.Delete(write.ctx, write.key)
+		} else {
+			err = w.store.Set(write.ctx, write.key, write.value, write.expiration)
+		}
+
+		if err != nil {
+			c.metrics.DualWriteErrors.Add(1)
+			c.reportError("dualwrite", write.key, err)
+			continue
+		}
+		c.metrics.DualWrites.Add(1)
+		c.metrics.DualWriteLag.Add(int64(time.Since(write.queued)))
+	}
+}
+
+// stop waits for the queued writes to be copied and stops the writer.
+func (w *dualWriter) stop() {
+	if w == nil {
+		return
+	}
+	close(w.queue)
+	<-w.done
+}
+
+// pending returns the number of writes not copied yet.
+func (w *dualWriter) pending() int64 {
+	if w == nil {
+		return 0
+	}
+	return int64(len(w.queue))
+}
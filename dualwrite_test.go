@@ -0,0 +1,82 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mapStore struct {
+	mu      sync.Mutex
+	entries map[interface{}]interface{}
+	fail    bool
+}
+
+func (s *mapStore) Set(ctx context.Context, key interface{}, value interface{}, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return errors.New("store unavailable")
+	}
+	s.entries[key] = value
+	return nil
+}
+
+func (s *mapStore) Delete(ctx context.Context, key interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func TestBiCache_DualWrite(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	store := &mapStore{entries: make(map[interface{}]interface{})}
+	cache.SetDualWrite(store)
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Delete("key1")
+
+	// Closing flushes the pending writes
+	cache.Close()
+	if len(store.entries) != 1 || store.entries["key2"] != "value2" {
+		t.Errorf("DualWrite test failed. Expected: only key2, Got: %v", store.entries)
+	}
+	if metrics := cache.GetMetrics(); metrics.DualWrites != 3 || metrics.DualWriteErrors != 0 || metrics.DualWritePending != 0 {
+		t.Errorf("DualWrite test failed. Expected: 3 writes, Got: %+v", metrics)
+	}
+}
+
+func TestBiCache_DualWriteErrorIsolation(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	errs := make(chan error, 1)
+	cache.SetErrorHandler(func(op string, key interface{}, err error) {
+		if op == "dualwrite" {
+			errs <- err
+		}
+	})
+	cache.SetDualWrite(&mapStore{entries: make(map[interface{}]interface{}), fail: true})
+
+	// The cache write succeeds even though the store fails
+	if err := cache.SetWithOptions("key1", "value1", 0); err != nil {
+		t.Errorf("DualWriteErrorIsolation test failed. Expected: nil, Got: %v", err)
+	}
+	if _, found := cache.Get("key1"); !found {
+		t.Errorf("DualWriteErrorIsolation test failed. Expected: key1 cached")
+	}
+
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("DualWriteErrorIsolation test failed. Expected: error reported")
+	}
+	cache.SetDualWrite(nil)
+	if metrics := cache.GetMetrics(); metrics.DualWriteErrors != 1 {
+		t.Errorf("DualWriteErrorIsolation test failed. Expected: 1 error, Got: %v", metrics.DualWriteErrors)
+	}
+}
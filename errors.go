@@ -0,0 +1,123 @@
+package bicache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorHandlerFunc is called with errors the cache can't return to a caller. The operation is
+// "get" for stored values that could not be decoded, "set" for failed writes through Set,
+// "refresh" and "predict" for failed background loads, "shadow" and "dualwrite" for failed
+// reads and writes of the store being migrated from, "writebehind" for mutations that could not
+// be queued or flushed for the write-behind store, "iterate" for values skipped by an iteration
+// and "event" for panics of event handlers.
+type ErrorHandlerFunc func(op string, key interface{}, err error)
+
+// ErrNotFound can be returned by loaders to report that the key does not exist in the backing source.
+var ErrNotFound = errors.New("bicache: key not found")
+
+// ErrLoadSuppressed is returned when a load is skipped because a recent failure of the key is cached.
+var ErrLoadSuppressed = errors.New("bicache: load suppressed after a recent failure")
+
+// ErrNoLoader is returned when a key has to be loaded but no loader is registered for it.
+var ErrNoLoader = errors.New("bicache: no loader registered for key")
+
+// ErrClosed is returned by operations on a cache that was closed.
+var ErrClosed = errors.New("bicache: cache is closed")
+
+// ErrNoRevalidator is returned by Revalidate when no RevalidateFunc is registered.
+var ErrNoRevalidator = errors.New("bicache: no revalidator registered")
+
+// ReadOnlyError is returned when a write targets a read-only entry without being forced.
+type ReadOnlyError struct {
+	Key interface{}
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("bicache: entry %v is read-only", e.Key)
+}
+
+// DecodeError is returned by GetWithError when a stored value could not be decompressed or decoded.
+type DecodeError struct {
+	Key interface{}
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("bicache: decoding entry %v: %v", e.Key, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeFailure selects what Get does with a stored value that can't be decompressed or decoded.
+// In every case the lookup counts as a miss and a decode error, and the error handler is called.
+type DecodeFailure int
+
+const (
+	// DecodeFailureKeep reports a miss and keeps the entry, for example to inspect it.
+	DecodeFailureKeep DecodeFailure = iota
+	// DecodeFailureDelete reports a miss and deletes the entry, so a loader can replace it.
+	DecodeFailureDelete
+	// DecodeFailureError keeps the entry and makes GetWithError return a *DecodeError
+	// instead of loading the key.
+	DecodeFailureError
+)
+
+// SetDecodeFailure selects what Get does with stored values that can't be decoded. DecodeFailureKeep is the default.
+func (c *BiCache) SetDecodeFailure(onFailure DecodeFailure) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.decodeFailure = onFailure
+}
+
+// PanicError wraps the value an event handler or a batch loader panicked with.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("bicache: callback panicked: %v", e.Value)
+}
+
+// SetErrorHandler registers a function that is called asynchronously with errors the cache
+// would otherwise only count in its metrics or ignore. Errors of the flush in Close and panics of
+// the handlers Close waits for are still reported, later errors are not. Close waits for the
+// running calls, and panics of the error handler are recovered.
+func (c *BiCache) SetErrorHandler(handler ErrorHandlerFunc) {
+	c.errorHandler.Store(handler)
+}
+
+// reportError passes err to the error handler, if one is registered. It may be called with or without the lock.
+func (c *BiCache) reportError(op string, key interface{}, err error) {
+	c.dispatchError(op, key, err, false)
+}
+
+// dispatchError calls the error handler in a goroutine Close waits for. Once Close stopped the
+// dispatch, errors are only passed on from handlers Close is still waiting for, as pending.
+func (c *BiCache) dispatchError(op string, key interface{}, err error, pending bool) {
+	handler, _ := c.errorHandler.Load().(ErrorHandlerFunc)
+	if handler == nil {
+		return
+	}
+
+	c.dispatchMu.Lock()
+	defer c.dispatchMu.Unlock()
+	if c.dispatchStopped && !pending {
+		return
+	}
+	c.pendingEvents.Add(1)
+	go func() {
+		defer c.pendingEvents.Done()
+		// A panic is not reported, as that would call the panicking handler again
+		defer func() {
+			recover()
+		}()
+		handler(op, key, err)
+	}()
+}
+
+// errNotCompressed is returned when compression is enabled but a stored value is not a byte slice.
+var errNotCompressed = errors.New("bicache: stored value is not compressed")
@@ -0,0 +1,147 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_ErrorHandler(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	type reported struct {
+		op  string
+		key interface{}
+		err error
+	}
+	errs := make(chan reported, 10)
+	cache.SetErrorHandler(func(op string, key interface{}, err error) {
+		errs <- reported{op, key, err}
+	})
+
+	next := func() reported {
+		select {
+		case r := <-errs:
+			return r
+		case <-time.After(time.Second):
+			t.Fatalf("ErrorHandler test failed. Expected: a reported error")
+			return reported{}
+		}
+	}
+
+	// Failed writes through Set
+	errInvalid := errors.New("invalid")
+	cache.SetValidator(func(key interface{}, value interface{}) error {
+		return errInvalid
+	})
+	cache.Set("key1", "value", 0)
+	if r := next(); r.op != "set" || r.key != "key1" || !errors.Is(r.err, errInvalid) {
+		t.Errorf("ErrorHandler test (set) failed. Got: %+v", r)
+	}
+	cache.SetValidator(nil)
+
+	// Values that can't be decompressed
+	errCorrupt := errors.New("corrupt")
+	cache.SetCompression(func(data []byte) ([]byte, error) {
+		return data, nil
+	}, func(data []byte) ([]byte, error) {
+		return nil, errCorrupt
+	})
+	cache.Set("key2", []byte("value"), 0)
+	cache.Get("key2")
+	if r := next(); r.op != "get" || r.key != "key2" || !errors.Is(r.err, errCorrupt) {
+		t.Errorf("ErrorHandler test (get) failed. Got: %+v", r)
+	}
+	cache.SetCompression(nil, nil)
+
+	// Panicking event handlers
+	cache.SetCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
+		panic("boom")
+	})
+	cache.Set("key3", "value", 0)
+	r := next()
+	var panicErr *PanicError
+	if r.op != "event" || r.key != "key3" || !errors.As(r.err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("ErrorHandler test (event) failed. Got: %+v", r)
+	}
+}
+
+func TestBiCache_DecodeFailure(t *testing.T) {
+	errCorrupt := errors.New("corrupt")
+	newCache := func(onFailure DecodeFailure) *BiCache {
+		cache := NewBiCache(10, time.Minute)
+		cache.SetCompression(func(data []byte) ([]byte, error) {
+			return data, nil
+		}, func(data []byte) ([]byte, error) {
+			return nil, errCorrupt
+		})
+		cache.SetDecodeFailure(onFailure)
+		cache.Set("key", []byte("value"), 0)
+		return cache
+	}
+
+	// Keep reports a miss and keeps the entry
+	cache := newCache(DecodeFailureKeep)
+	if _, err := cache.GetWithError("key"); err != ErrNotFound {
+		t.Errorf("DecodeFailure test (keep) failed. Expected: ErrNotFound, Got: %v", err)
+	}
+	metrics := cache.GetMetrics()
+	if metrics.Misses != 1 || metrics.DecodeError != 1 || metrics.EntriesCount != 1 {
+		t.Errorf("DecodeFailure test (keep) failed. Expected: 1 miss, 1 decode error, 1 entry, Got: %+v", metrics)
+	}
+
+	// Delete reports a miss and removes the entry
+	cache = newCache(DecodeFailureDelete)
+	if _, found := cache.Get("key"); found {
+		t.Errorf("DecodeFailure test (delete) failed. Expected: miss")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 0 {
+		t.Errorf("DecodeFailure test (delete) failed. Expected: 0 entries, Got: %v", metrics.EntriesCount)
+	}
+
+	// Error returns the decode error
+	cache = newCache(DecodeFailureError)
+	_, err := cache.GetWithError("key")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Key != "key" || !errors.Is(err, errCorrupt) {
+		t.Errorf("DecodeFailure test (error) failed. Expected: DecodeError, Got: %v", err)
+	}
+}
+
+func TestBiCache_ErrorHandlerClose(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetValidator(func(key interface{}, value interface{}) error {
+		return errors.New("invalid")
+	})
+	release := make(chan struct{})
+	calls := make(chan struct{}, 10)
+	cache.SetErrorHandler(func(op string, key interface{}, err error) {
+		calls <- struct{}{}
+		<-release
+		panic("handler failed")
+	})
+
+	// Close waits for the handler, whose panic doesn't crash the program
+	cache.Set("key1", "value", 0)
+	<-calls
+	closed := make(chan struct{})
+	go func() {
+		cache.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatalf("ErrorHandler close test failed. Expected: Close to wait for the handler")
+	case <-time.After(time.Millisecond * 20):
+	}
+	close(release)
+	<-closed
+
+	// Errors after Close are not reported
+	cache.reportError("set", "key2", errors.New("late"))
+	select {
+	case <-calls:
+		t.Errorf("ErrorHandler close test failed. Expected: no call after Close")
+	case <-time.After(time.Millisecond * 20):
+	}
+}
@@ -0,0 +1,71 @@
+package bicache
+
+import "context"
+
+// String returns the lowercase name of the event.
+func (e CacheEvent) String() string {
+	switch e {
+	case CacheEventSet:
+		return "set"
+	case CacheEventDelete:
+		return "delete"
+	case CacheEventEvict:
+		return "evict"
+	case CacheEventClear:
+		return "clear"
+	default:
+		return "unknown"
+	}
+}
+
+// AddCacheEventHandler registers an additional handler, such as an event sink,
+// that is called next to the one set with SetCacheEventHandler.
+func (c *BiCache) AddCacheEventHandler(handler CacheEventHandlerFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.eventHandlers = append(c.eventHandlers, func(ctx context.Context, event CacheEvent, key interface{}, entry CacheEntry) {
+		handler(event, key, entry)
+	})
+}
+
+// emit calls the cache event handlers asynchronously for an event the cache caused itself,
+// such as an eviction. The caller must hold the lock.
+func (c *BiCache) emit(event CacheEvent, key interface{}, entry CacheEntry) {
+	c.emitContext(context.Background(), event, key, entry)
+}
+
+// emitContext calls the cache event handlers asynchronously for an event caused by a call
+// with ctx. Events are dropped once the cache is closed. The caller must hold the lock.
+func (c *BiCache) emitContext(ctx context.Context, event CacheEvent, key interface{}, entry CacheEntry) {
+	if c.closed.Load() {
+		return
+	}
+
+	if handler := c.cacheEventHandler; handler != nil {
+		c.pendingEvents.Add(1)
+		go func() {
+			defer c.pendingEvents.Done()
+			defer c.recoverHandler(key)
+			handler(event, key, entry)
+		}()
+	}
+
+	ctx = withoutCancel(ctx)
+	for _, handler := range c.eventHandlers {
+		c.pendingEvents.Add(1)
+		go func(handler ContextEventHandlerFunc) {
+			defer c.pendingEvents.Done()
+			defer c.recoverHandler(key)
+			handler(ctx, event, key, entry)
+		}(handler)
+	}
+}
+
+// recoverHandler reports a panic of an event handler instead of crashing the program.
+func (c *BiCache) recoverHandler(key interface{}) {
+	if r := recover(); r != nil {
+		// The handler is counted in pendingEvents, so the error is reported even while Close waits
+		c.dispatchError("event", key, &PanicError{Value: r}, true)
+	}
+}
@@ -0,0 +1,58 @@
+package bicache
+
+import (
+	"sort"
+	"time"
+)
+
+// WouldEvict returns up to n keys in the order the current policy would remove them next,
+// without removing anything. Expired entries come first, followed by the entries
+// cost-benefit admission would replace if it is enabled, or else by the ones the eviction policy picks.
+func (c *BiCache) WouldEvict(n int) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+
+	type candidate struct {
+		key   interface{}
+		entry CacheEntry
+		at    time.Time
+	}
+	var expired, live []candidate
+	for key, entry := range c.cacheMap {
+		if at := c.cleanupExpiration(entry); !at.IsZero() && at.Before(now) {
+			expired = append(expired, candidate{key: key, entry: entry, at: at})
+		} else {
+			live = append(live, candidate{key: key, entry: entry})
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].at.Before(expired[j].at)
+	})
+	victims := expired
+
+	if c.admission == CostBenefitAdmission {
+		sort.Slice(live, func(i, j int) bool {
+			return costPerByte(live[i].entry) < costPerByte(live[j].entry)
+		})
+		victims = append(victims, live...)
+	} else {
+		for _, key := range c.victims(len(c.cacheMap)) {
+			entry := c.cacheMap[key]
+			if at := c.cleanupExpiration(entry); at.IsZero() || !at.Before(now) {
+				victims = append(victims, candidate{key: key, entry: entry})
+			}
+		}
+	}
+
+	keys := make([]interface{}, 0, n)
+	for _, victim := range victims {
+		if len(keys) >= n {
+			break
+		}
+		keys = append(keys, c.externalKey(victim.key))
+	}
+	return keys
+}
@@ -0,0 +1,32 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_WouldEvict(t *testing.T) {
+	cache := NewBiCache(5, time.Minute)
+
+	cache.Set("expired2", "value", -time.Second)
+	cache.Set("expired1", "value", -time.Second*2)
+	cache.Set("live", "value", time.Minute)
+	cache.Set("forever", "value", 0)
+
+	// Expired entries come first, oldest expiration first, then the least recently used
+	victims := cache.WouldEvict(5)
+	if len(victims) != 4 || victims[0] != "expired1" || victims[1] != "expired2" || victims[2] != "live" || victims[3] != "forever" {
+		t.Errorf("WouldEvict test failed. Expected: [expired1 expired2 live forever], Got: %v", victims)
+	}
+
+	// Nothing is removed
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 4 {
+		t.Errorf("WouldEvict test failed. Expected: 4 entries, Got: %v", metrics.EntriesCount)
+	}
+
+	// With cost-benefit admission the cheapest live entries follow
+	cache.SetAdmission(CostBenefitAdmission)
+	if victims := cache.WouldEvict(3); len(victims) != 3 {
+		t.Errorf("WouldEvict test (cost-benefit) failed. Expected: 3 keys, Got: %v", victims)
+	}
+}
@@ -0,0 +1,175 @@
+package bicache
+
+// EvictionPolicy selects which entries are evicted when the cache is over capacity.
+type EvictionPolicy int
+
+const (
+	// LRUEviction evicts the least recently used entry.
+	LRUEviction EvictionPolicy = iota
+	// SLRUEviction keeps new entries in a probation segment and moves entries read again
+	// into a protected segment. Entries are evicted from probation first, so one-off
+	// reads and scans don't push out entries that are read repeatedly.
+	SLRUEviction
+	// ARCEviction adapts between recency and frequency: it balances entries read once against
+	// entries read again, using the keys it recently evicted from each group as feedback.
+	ARCEviction
+	// ClockEviction approximates LRU with a reference bit per entry, which reads only
+	// have to set, giving the entries read since the last sweep a second chance.
+	ClockEviction
+	// TwoQEviction admits new entries into a FIFO queue and only moves entries that are written
+	// again soon after leaving it into the main LRU queue, so sequential scans pass through.
+	TwoQEviction
+	// SampledEviction evicts the least recently accessed of a few randomly sampled entries,
+	// approximating LRU without keeping any per-entry bookkeeping. See SetEvictionSamples.
+	SampledEviction
+	// SampledLFUEviction evicts the least frequently read of a few randomly sampled entries per unit
+	// of weight, so heavy entries that are rarely read go first. It keeps no per-entry bookkeeping
+	// beyond the frequency sketch, which it enables, suiting caches of tens of millions of entries.
+	SampledLFUEviction
+)
+
+// defaultProtectedRatio is the share of the capacity reserved for protected SLRU entries.
+const defaultProtectedRatio = 0.8
+
+func (p EvictionPolicy) String() string {
+	switch p {
+	case LRUEviction:
+		return "lru"
+	case SLRUEviction:
+		return "slru"
+	case ARCEviction:
+		return "arc"
+	case ClockEviction:
+		return "clock"
+	case TwoQEviction:
+		return "2q"
+	case SampledEviction:
+		return "sampled"
+	case SampledLFUEviction:
+		return "sampled-lfu"
+	default:
+		return "unknown"
+	}
+}
+
+// evictor tracks keys in the order the eviction policy removes them.
+// Implementations have their own lock, because reads update them while holding only the cache read lock.
+type evictor interface {
+	// add records a write of key.
+	add(key interface{})
+	// touch records a read of key.
+	touch(key interface{})
+	remove(key interface{})
+	// victims returns up to n keys, the next one to evict first.
+	victims(n int) []interface{}
+	setCapacity(capacity int)
+}
+
+func (c *BiCache) newEvictor(policy EvictionPolicy) evictor {
+	switch policy {
+	case SLRUEviction:
+		return newSLRU(c.capacity, c.protectedRatio)
+	case ARCEviction:
+		return newARC(c.capacity)
+	case ClockEviction:
+		return newClock()
+	case TwoQEviction:
+		return newTwoQ(c.capacity)
+	case SampledEviction:
+		return &sampled{cache: c, samples: c.evictionSamples}
+	case SampledLFUEviction:
+		if c.sketch == nil {
+			c.sketch = newCountMinSketch(c.capacity, c.hasher)
+		}
+		return &sampled{cache: c, samples: c.evictionSamples, frequency: true}
+	default:
+		return newLRUList()
+	}
+}
+
+// SetEvictionPolicy changes how entries are evicted when the cache is over capacity. LRUEviction is the default.
+func (c *BiCache) SetEvictionPolicy(policy EvictionPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictionPolicy = policy
+	c.resetEvictor()
+}
+
+// SetProtectedRatio sets the share of the capacity, between 0 and 1, that SLRU eviction
+// reserves for entries read more than once. It defaults to 0.8.
+func (c *BiCache) SetProtectedRatio(ratio float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ratio < 0 {
+		ratio = 0
+	} else if ratio > 1 {
+		ratio = 1
+	}
+	c.protectedRatio = ratio
+	c.resetEvictor()
+}
+
+// SetEvictionSamples sets how many entries SampledEviction and SampledLFUEviction compare per eviction. More samples
+// approximate LRU better at a higher cost per eviction. It defaults to 5.
+func (c *BiCache) SetEvictionSamples(samples int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if samples < 1 {
+		samples = 1
+	}
+	c.evictionSamples = samples
+	if s, ok := c.eviction.(*sampled); ok {
+		s.samples = samples
+	}
+}
+
+// resetEvictor replaces the evictor, keeping the current eviction order as far as the new policy allows.
+// The caller must hold the write lock.
+func (c *BiCache) resetEvictor() {
+	keys := c.victims(len(c.cacheMap))
+	c.eviction = c.newEvictor(c.evictionPolicy)
+	for _, key := range keys {
+		c.eviction.add(key)
+	}
+}
+
+// evict removes entries chosen by the eviction policy until the cache is within capacity.
+// The key just written is never evicted. The caller must hold the write lock.
+func (c *BiCache) evict(keep interface{}) {
+	keep = c.mapKey(keep)
+
+	// Hot entries are read without touching the list, so they get one more chance
+	chances := len(c.cacheMap)
+	for c.overCapacity() {
+		victims := c.victims(2)
+		if len(victims) == 0 {
+			return
+		}
+
+		victim := victims[0]
+		if victim == keep {
+			if len(victims) < 2 {
+				return
+			}
+			victim = victims[1]
+		}
+
+		if chances > 0 && c.isHot(victim) {
+			chances--
+			c.eviction.touch(victim)
+			continue
+		}
+
+		c.evictEntry(victim)
+	}
+}
+
+// evictEntry removes the entry stored under key to make room. The caller must hold the write lock.
+func (c *BiCache) evictEntry(key interface{}) {
+	key = c.externalKey(c.mapKey(key))
+	c.removeEntry(key, removalEvicted)
+	c.emit(CacheEventEvict, key, CacheEntry{})
+}
@@ -0,0 +1,90 @@
+package bicache
+
+import "time"
+
+// Touch marks the entry stored under key as accessed and extends its expiration by the TTL it
+// was written with. Entries written without a positive TTL only have their access time updated.
+// It reports whether a live entry was found.
+func (c *BiCache) Touch(key interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, now) {
+		return false
+	}
+
+	entry.Accessed = now
+	entry.touch(now)
+	if entry.ttl > 0 {
+		entry.Expiration = now.Add(entry.ttl)
+	}
+	c.storeEntry(key, entry)
+	return true
+}
+
+// Expire sets the TTL of the entry stored under key to ttl from now, without rewriting its
+// value or changing its recency. A ttl of 0 or less deletes the entry right away.
+// It reports whether a live entry was found.
+func (c *BiCache) Expire(key interface{}, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, now) {
+		return false
+	}
+
+	if ttl <= 0 {
+		c.removeEntry(key, removalDeleted)
+		c.emit(CacheEventDelete, key, CacheEntry{})
+		return true
+	}
+
+	entry.Expiration = now.Add(ttl)
+	entry.ttl = ttl
+	c.updateEntry(key, entry)
+	return true
+}
+
+// Persist removes the expiration of the entry stored under key, so it is kept until it is
+// deleted or evicted. It reports whether a live entry was found.
+func (c *BiCache) Persist(key interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, time.Now()) {
+		return false
+	}
+
+	entry.Expiration = time.Time{}
+	entry.ttl = 0
+	c.updateEntry(key, entry)
+	return true
+}
+
+// updateEntry replaces the stored entry for key with a copy that differs only in its expiration,
+// leaving its recency alone. The caller must hold the write lock.
+func (c *BiCache) updateEntry(key interface{}, entry CacheEntry) {
+	stored := c.mapKey(key)
+	c.cacheMap[stored] = entry
+	c.invalidateHot(stored)
+}
+
+// Touch marks the entry stored under key as accessed and extends it by its TTL.
+func (c *ShardedBiCache) Touch(key interface{}) bool {
+	return c.Shard(key).Touch(key)
+}
+
+// Expire sets the TTL of the entry stored under key to ttl from now.
+func (c *ShardedBiCache) Expire(key interface{}, ttl time.Duration) bool {
+	return c.Shard(key).Expire(key, ttl)
+}
+
+// Persist removes the expiration of the entry stored under key.
+func (c *ShardedBiCache) Persist(key interface{}) bool {
+	return c.Shard(key).Persist(key)
+}
@@ -0,0 +1,41 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_TouchExpirePersist(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 50*time.Millisecond)
+
+	time.Sleep(30 * time.Millisecond)
+	if !cache.Touch("key1") {
+		t.Fatalf("Touch test failed. Expected: key1 found")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if !cache.Has("key1") {
+		t.Errorf("Touch test failed. Expected: key1 extended by its TTL")
+	}
+
+	if !cache.Expire("key1", time.Hour) {
+		t.Fatalf("Expire test failed. Expected: key1 found")
+	}
+	if ttl, _ := cache.TTL("key1"); ttl <= 59*time.Minute {
+		t.Errorf("Expire test failed. Expected: TTL of about an hour, Got: %v", ttl)
+	}
+
+	if !cache.Persist("key1") {
+		t.Fatalf("Persist test failed. Expected: key1 found")
+	}
+	if ttl, found := cache.TTL("key1"); !found || ttl != 0 {
+		t.Errorf("Persist test failed. Expected: no expiration, Got: %v", ttl)
+	}
+
+	if !cache.Expire("key1", 0) || cache.Has("key1") {
+		t.Errorf("Expire test failed. Expected: key1 deleted")
+	}
+	if cache.Touch("missing") || cache.Expire("missing", time.Hour) || cache.Persist("missing") {
+		t.Errorf("Touch test failed. Expected: missing keys not found")
+	}
+}
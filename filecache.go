@@ -0,0 +1,120 @@
+package bicache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileParseFunc parses the contents of a file, for example into a config struct.
+type FileParseFunc func(path string, data []byte) (interface{}, error)
+
+// FileCacheConfig configures a FileCache.
+type FileCacheConfig struct {
+	// Capacity is the maximum number of cached files, 1000 if not set.
+	Capacity int
+	// PollInterval is how often cached files are checked for changes, one second if not set.
+	PollInterval time.Duration
+	// Parse parses the contents of files. If not set, the contents are cached as []byte.
+	Parse FileParseFunc
+	// EagerReload reloads changed files right away instead of on the next Get.
+	EagerReload bool
+	// OnChange is called with each changed file, and with the error of reloading it if EagerReload is set.
+	OnChange func(path string, err error)
+}
+
+// FileCache caches the contents of files, or the configs parsed from them, keyed by path.
+// Entries are invalidated when their file changes or is removed.
+type FileCache struct {
+	// Cache stores the contents, keyed by the cleaned path.
+	Cache   *BiCache
+	config  FileCacheConfig
+	watcher *fileWatcher
+}
+
+// NewFileCache returns a FileCache configured by config and starts watching the cached files.
+// Close stops the watcher.
+func NewFileCache(config FileCacheConfig) *FileCache {
+	if config.Capacity <= 0 {
+		config.Capacity = 1000
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.Parse == nil {
+		config.Parse = func(path string, data []byte) (interface{}, error) {
+			return data, nil
+		}
+	}
+
+	c := &FileCache{
+		// Entries never expire, so cleanup rarely finds anything
+		Cache:  NewBiCache(config.Capacity, time.Hour),
+		config: config,
+	}
+	c.watcher = newFileWatcher(config.PollInterval, c.changed)
+	return c
+}
+
+// Get returns the contents of the file at path, parsed if FileCacheConfig.Parse is set, reading
+// the file on a miss. Concurrent misses of the same file read it once. Errors reading or parsing
+// the file are returned and not cached.
+func (c *FileCache) Get(path string) (interface{}, error) {
+	return c.GetContext(context.Background(), path)
+}
+
+// GetContext is like Get and passes ctx on to the event handlers.
+func (c *FileCache) GetContext(ctx context.Context, path string) (interface{}, error) {
+	path = filepath.Clean(path)
+	entry, _, err := c.Cache.lookupWith(ctx, path, func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		value, err := c.read(path)
+		return LoaderResult{Value: value}, err
+	})
+	return entry.Value, err
+}
+
+// Invalidate removes the cached contents of the file at path.
+func (c *FileCache) Invalidate(path string) {
+	path = filepath.Clean(path)
+	c.watcher.remove(path)
+	c.Cache.Delete(path)
+}
+
+// Close stops the watcher and the cache.
+func (c *FileCache) Close() {
+	c.watcher.stop()
+	c.Cache.Close()
+}
+
+// read watches the file at path and reads and parses it. The file is watched before it is read,
+// so changes made while it is read are not missed.
+func (c *FileCache) read(path string) (interface{}, error) {
+	c.watcher.add(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.watcher.remove(path)
+		return nil, err
+	}
+	return c.config.Parse(path, data)
+}
+
+// changed removes the cached contents of a changed file, or replaces them if EagerReload is set
+// and the file is still cached and can be read and parsed.
+func (c *FileCache) changed(path string) {
+	var err error
+	reload := c.config.EagerReload && c.Cache.Has(path)
+	if reload {
+		var value interface{}
+		if value, err = c.read(path); err == nil {
+			err = c.Cache.SetWithOptions(path, value, 0)
+		}
+	}
+	if !reload || err != nil {
+		c.Cache.Delete(path)
+	}
+
+	if c.config.OnChange != nil {
+		c.config.OnChange(path, err)
+	}
+}
@@ -0,0 +1,96 @@
+package bicache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileCache_Invalidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	os.WriteFile(path, []byte("v1"), 0o644)
+
+	changes := make(chan string, 1)
+	cache := NewFileCache(FileCacheConfig{
+		PollInterval: 10 * time.Millisecond,
+		OnChange: func(path string, err error) {
+			changes <- path
+		},
+	})
+	defer cache.Close()
+
+	value, err := cache.Get(path)
+	if err != nil || string(value.([]byte)) != "v1" {
+		t.Fatalf("File cache test failed. Expected: v1, Got: %v, %v", value, err)
+	}
+
+	os.WriteFile(path, []byte("v2 changed"), 0o644)
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("File cache test failed. Expected: the change to be seen")
+	}
+	if cache.Cache.Has(path) {
+		t.Errorf("File cache test failed. Expected: entry invalidated")
+	}
+	if value, _ := cache.Get(path); string(value.([]byte)) != "v2 changed" {
+		t.Errorf("File cache test failed. Expected: v2 changed, Got: %s", value)
+	}
+
+	if _, err := cache.Get(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("File cache test failed. Expected: not exist error, Got: %v", err)
+	}
+}
+
+func TestFileCache_EagerReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.txt")
+	os.WriteFile(path, []byte("a=1"), 0o644)
+
+	changes := make(chan error, 1)
+	parses := 0
+	cache := NewFileCache(FileCacheConfig{
+		PollInterval: 10 * time.Millisecond,
+		EagerReload:  true,
+		Parse: func(path string, data []byte) (interface{}, error) {
+			parses++
+			return strings.Split(string(data), "="), nil
+		},
+		OnChange: func(path string, err error) {
+			changes <- err
+		},
+	})
+	defer cache.Close()
+
+	cache.Get(path)
+	os.WriteFile(path, []byte("a=22"), 0o644)
+	select {
+	case err := <-changes:
+		if err != nil {
+			t.Errorf("File cache reload test failed. Got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("File cache reload test failed. Expected: the change to be seen")
+	}
+
+	if value, _ := cache.Cache.Peek(path); value == nil || value.([]string)[1] != "22" {
+		t.Errorf("File cache reload test failed. Expected: reloaded value, Got: %v", value)
+	}
+	if parses != 2 {
+		t.Errorf("File cache reload test failed. Expected: 2 parses, Got: %v", parses)
+	}
+
+	os.Remove(path)
+	select {
+	case err := <-changes:
+		if !os.IsNotExist(err) {
+			t.Errorf("File cache reload test failed. Expected: not exist error, Got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("File cache reload test failed. Expected: the removal to be seen")
+	}
+	if cache.Cache.Has(path) {
+		t.Errorf("File cache reload test failed. Expected: removed file invalidated")
+	}
+}
@@ -0,0 +1,200 @@
+package bicache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FileSinkConfig configures a FileSink.
+type FileSinkConfig struct {
+	// Path is the file records are appended to. Rotated files are renamed to Path.1, Path.2
+	// and so on, Path.1 being the most recent.
+	Path string
+	// MaxBytes is the size after which the file is rotated, 64 MiB if not set.
+	MaxBytes int64
+	// MaxFiles is the number of rotated files kept, 5 if not set.
+	MaxFiles int
+	// QueueSize is the number of records that may wait to be written, 1024 if not set.
+	QueueSize int
+	// FlushInterval is how long records may stay buffered before being written, 1s if not set.
+	FlushInterval time.Duration
+	// OnError is called with errors writing or rotating the files.
+	OnError func(err error)
+}
+
+// FileRecord is the JSON representation of a cache event or traced Get written by a FileSink,
+// one per line.
+type FileRecord struct {
+	// Event is the name of the cache event, or "get" for traced Gets.
+	Event      string    `json:"event"`
+	Key        string    `json:"key"`
+	Hit        bool      `json:"hit,omitempty"`
+	Tier       string    `json:"tier,omitempty"`
+	Expiration time.Time `json:"expiration,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// FileSink writes cache events and traced Gets to rotating JSON Lines files for offline analysis,
+// such as replaying the access stream against other cache settings.
+type FileSink struct {
+	config    FileSinkConfig
+	records   chan FileRecord
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+	dropped   int64
+	file      *rotatingFile
+}
+
+// NewFileSink opens or creates the file at config.Path and starts writing records to it.
+func NewFileSink(config FileSinkConfig) (*FileSink, error) {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 64 << 20
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = 5
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+
+	file, err := openRotatingFile(config.Path, config.MaxBytes, config.MaxFiles, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &FileSink{
+		config:  config,
+		records: make(chan FileRecord, config.QueueSize),
+		done:    make(chan struct{}),
+		file:    file,
+	}
+	go sink.run()
+
+	return sink, nil
+}
+
+// Handle queues a cache event, it can be registered with AddCacheEventHandler.
+// Records are dropped if the queue is full.
+func (s *FileSink) Handle(event CacheEvent, key interface{}, entry CacheEntry) {
+	s.queue(FileRecord{
+		Event:      event.String(),
+		Key:        fmt.Sprint(key),
+		Expiration: entry.Expiration,
+		Time:       time.Now(),
+	})
+}
+
+// Trace queues a traced Get, it can be registered with SetTracer.
+func (s *FileSink) Trace(span Span) {
+	s.queue(FileRecord{
+		Event: span.Operation,
+		Key:   fmt.Sprint(span.Key),
+		Hit:   span.Hit,
+		Tier:  span.Tier.String(),
+		Time:  span.Start,
+	})
+}
+
+func (s *FileSink) queue(record FileRecord) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		atomic.AddInt64(&s.dropped, 1)
+		return
+	}
+
+	select {
+	case s.records <- record:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full or the sink was closed.
+func (s *FileSink) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Close stops accepting records, writes the queued ones and closes the file.
+func (s *FileSink) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.records)
+		s.mu.Unlock()
+		<-s.done
+
+		err = s.file.close()
+	})
+	return err
+}
+
+func (s *FileSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case record, ok := <-s.records:
+			if !ok {
+				return
+			}
+			s.write(record)
+		case <-ticker.C:
+			if err := s.file.flush(); err != nil {
+				s.reportError(err)
+			}
+		}
+	}
+}
+
+// write appends a record, rotating the file first if it is full.
+func (s *FileSink) write(record FileRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		s.reportError(err)
+		return
+	}
+	line = append(line, '\n')
+
+	if err := s.file.rotateIfFull(len(line)); err != nil {
+		s.reportError(err)
+	}
+	if err := s.file.write(line); err != nil {
+		s.reportError(err)
+	}
+}
+
+func (s *FileSink) reportError(err error) {
+	if s.config.OnError != nil {
+		s.config.OnError(err)
+	}
+}
+
+// ReadFileRecords reads the records of a file written by a FileSink.
+func ReadFileRecords(r io.Reader) ([]FileRecord, error) {
+	var records []FileRecord
+	decoder := json.NewDecoder(r)
+	for {
+		var record FileRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
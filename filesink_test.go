@@ -0,0 +1,55 @@
+package bicache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := NewFileSink(FileSinkConfig{Path: path, MaxBytes: 200, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("FileSink test failed. Unexpected error: %v", err)
+	}
+
+	sink.Handle(CacheEventSet, "key1", CacheEntry{})
+	sink.Trace(Span{Operation: "get", Key: "key1", Tier: TierMemory, Hit: true, Start: time.Now()})
+	sink.Handle(CacheEventDelete, "key1", CacheEntry{})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("FileSink test failed. Unexpected error: %v", err)
+	}
+
+	// Each record is about 100 bytes, so the first ones were rotated
+	var records []FileRecord
+	for _, name := range []string{path + ".2", path + ".1", path} {
+		f, err := os.Open(name)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			t.Fatalf("FileSink test failed. Unexpected error: %v", err)
+		}
+		read, err := ReadFileRecords(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("FileSink test failed. Unexpected error: %v", err)
+		}
+		records = append(records, read...)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("FileSink test failed. Expected: a rotated file, Got: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("FileSink test failed. Expected: 3 records, Got: %v", records)
+	}
+	if records[0].Event != "set" || records[1].Event != "get" || !records[1].Hit || records[1].Tier != "memory" || records[2].Event != "delete" {
+		t.Errorf("FileSink test failed. Got: %+v", records)
+	}
+
+	sink.Handle(CacheEventSet, "key2", CacheEntry{})
+	if dropped := sink.Dropped(); dropped != 1 {
+		t.Errorf("FileSink test failed. Expected: 1 dropped record after Close, Got: %d", dropped)
+	}
+}
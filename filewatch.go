@@ -0,0 +1,107 @@
+package bicache
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// fileWatcher polls files for changes. A changed or removed file is no longer watched until
+// it is added again.
+type fileWatcher struct {
+	onChange func(path string)
+
+	// mu guards files.
+	mu    sync.Mutex
+	files map[string]fileState
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// fileState is the state of a watched file the last time it was checked.
+type fileState struct {
+	exists   bool
+	modified time.Time
+	size     int64
+}
+
+// newFileWatcher starts checking the watched files every interval and calls onChange
+// with the ones that changed. stop stops the watcher.
+func newFileWatcher(interval time.Duration, onChange func(path string)) *fileWatcher {
+	w := &fileWatcher{
+		onChange: onChange,
+		files:    make(map[string]fileState),
+		done:     make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+// add starts watching the files that are not watched yet.
+func (w *fileWatcher) add(paths ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, path := range paths {
+		if _, watched := w.files[path]; !watched {
+			w.files[path] = statFile(path)
+		}
+	}
+}
+
+// remove stops watching a file.
+func (w *fileWatcher) remove(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.files, path)
+}
+
+func (w *fileWatcher) stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+	})
+}
+
+func (w *fileWatcher) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check calls onChange with the files that changed since they were last checked.
+func (w *fileWatcher) check() {
+	w.mu.Lock()
+	var changed []string
+	for path, previous := range w.files {
+		if current := statFile(path); !current.equal(previous) {
+			changed = append(changed, path)
+			delete(w.files, path)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, path := range changed {
+		w.onChange(path)
+	}
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modified: info.ModTime(), size: info.Size()}
+}
+
+func (f fileState) equal(other fileState) bool {
+	return f.exists == other.exists && f.modified.Equal(other.modified) && f.size == other.size
+}
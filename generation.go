@@ -0,0 +1,68 @@
+package bicache
+
+import (
+	"strings"
+	"time"
+)
+
+// InvalidateNamespace invalidates every entry whose string key starts with namespace in O(1),
+// by recording the current write generation for it. Entries written before are treated as
+// misses and purged lazily, on access or by cleanup, which then forgets the invalidation.
+// The empty namespace invalidates all entries.
+func (c *BiCache) InvalidateNamespace(namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidations[namespace] = c.generation
+
+	// Hashed keys whose original key is not kept may be in any namespace
+	if hot := c.hot.Load(); hot != nil {
+		hot.remove(func(key interface{}) bool {
+			key = c.externalKey(key)
+			if _, hashed := key.(HashedKey); hashed {
+				return true
+			}
+			return inNamespace(key, namespace)
+		})
+	}
+}
+
+// live reports whether entry is neither expired nor invalidated. The caller must hold the lock.
+func (c *BiCache) live(key interface{}, entry CacheEntry, now time.Time) bool {
+	return !expired(entry, now) && !c.tooStale(entry, now) && !c.invalidated(key, entry)
+}
+
+// expired reports whether entry has passed its expiration time.
+func expired(entry CacheEntry, now time.Time) bool {
+	return !entry.Expiration.IsZero() && !now.Before(entry.Expiration)
+}
+
+// invalidated reports whether entry was written before its namespace was invalidated.
+// The caller must hold the lock.
+func (c *BiCache) invalidated(key interface{}, entry CacheEntry) bool {
+	for namespace, generation := range c.invalidations {
+		if entry.generation <= generation && inNamespace(key, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanupInvalidations forgets the invalidations that no entry left in the cache is hidden by,
+// given the oldest generation of the entries that may be, so they no longer slow down every lookup.
+// The caller must hold the write lock.
+func (c *BiCache) cleanupInvalidations(oldest uint64) {
+	for namespace, generation := range c.invalidations {
+		if generation < oldest {
+			delete(c.invalidations, namespace)
+		}
+	}
+}
+
+func inNamespace(key interface{}, namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+	stringKey, ok := key.(string)
+	return ok && strings.HasPrefix(stringKey, namespace)
+}
@@ -0,0 +1,84 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_InvalidateNamespace(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("user:1", "value", 0)
+	cache.Set("user:2", "value", 0)
+	cache.Set("product:1", "value", 0)
+
+	cache.InvalidateNamespace("user:")
+
+	// Entries written before the invalidation are misses
+	if _, found := cache.Get("user:1"); found {
+		t.Errorf("InvalidateNamespace test failed. Expected: user:1 invalidated")
+	}
+	if _, found := cache.Get("product:1"); !found {
+		t.Errorf("InvalidateNamespace test failed. Expected: product:1 kept")
+	}
+
+	// Entries written afterwards are not affected
+	cache.Set("user:1", "new", 0)
+	if result, found := cache.Get("user:1"); !found || result != "new" {
+		t.Errorf("InvalidateNamespace test failed. Expected: 'new', Got: '%v'", result)
+	}
+
+	// The rest is purged lazily by cleanup
+	cache.mu.Lock()
+	cache.cleanup()
+	cache.mu.Unlock()
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 2 {
+		t.Errorf("InvalidateNamespace test failed. Expected: 2 entries, Got: %v", metrics.EntriesCount)
+	}
+
+	// Invalidations are forgotten once cleanup purged their entries
+	if n := len(cache.invalidations); n != 0 {
+		t.Errorf("InvalidateNamespace test failed. Expected: invalidations forgotten, Got: %d", n)
+	}
+
+	// The empty namespace invalidates everything
+	cache.Set(42, "value", 0)
+	cache.InvalidateNamespace("")
+	if _, found := cache.Get(42); found {
+		t.Errorf("InvalidateNamespace test failed. Expected: all entries invalidated")
+	}
+}
+
+func TestBiCache_InvalidateNamespaceHashedKeys(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetKeyHashing(4, false)
+	cache.Set("user:1", "value", 0)
+	cache.InvalidateNamespace("user:")
+
+	// Cleanup can't tell the namespace of hashed keys, so the invalidation keeps hiding them
+	cache.RunCleanup()
+	if _, found := cache.Get("user:1"); found {
+		t.Errorf("InvalidateNamespace hashed keys test failed. Expected: user:1 invalidated")
+	}
+
+	cache.Delete("user:1")
+	cache.RunCleanup()
+	if n := len(cache.invalidations); n != 0 {
+		t.Errorf("InvalidateNamespace hashed keys test failed. Expected: invalidations forgotten, Got: %d", n)
+	}
+}
+
+func TestBiCache_InvalidateNamespaceHashedHotKeys(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetKeyHashing(4, false)
+	cache.EnableHotEntries(1, 10)
+	cache.Set("user:1", "value", 0)
+	for i := 0; i < 3; i++ {
+		cache.Get("user:1")
+	}
+
+	// The hot copy of a hashed key is dropped although its namespace is unknown
+	cache.InvalidateNamespace("user:")
+	if _, found := cache.Get("user:1"); found {
+		t.Errorf("InvalidateNamespace hashed hot keys test failed. Expected: user:1 invalidated")
+	}
+}
@@ -0,0 +1,86 @@
+package bicache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flightGroup lets concurrent loads of the same key share one call.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[interface{}]*scopedLoad
+}
+
+// do calls load for key unless a load of key is already running, in which case it waits for
+// that load and returns its result.
+func (g *flightGroup) do(ctx context.Context, cache *BiCache, key interface{}, load func() (CacheEntry, error)) (CacheEntry, error) {
+	g.mu.Lock()
+	if call, exists := g.calls[key]; exists {
+		g.mu.Unlock()
+		cache.metrics.LoadsDeduplicated.Add(1)
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return CacheEntry{}, ctx.Err()
+		}
+		entry := call.entry
+		entry.Tags = copyTags(entry.Tags)
+		return entry, call.err
+	}
+	if g.calls == nil {
+		g.calls = make(map[interface{}]*scopedLoad)
+	}
+	call := &scopedLoad{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.entry, call.err = load()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+
+	entry := call.entry
+	entry.Tags = copyTags(entry.Tags)
+	return entry, call.err
+}
+
+// GetOrLoad returns the value stored under key, or calls loader on a miss and stores its result
+// for ttl. Concurrent misses of the same key call loader only once and all get its result.
+// Errors of loader are returned and not stored.
+func (c *BiCache) GetOrLoad(key interface{}, ttl time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	return c.GetOrLoadContext(context.Background(), key, ttl, func(ctx context.Context) (interface{}, error) {
+		return loader()
+	})
+}
+
+// GetOrLoadContext is like GetOrLoad and passes ctx on to loader and the event handlers.
+// Callers waiting for the load of another caller stop waiting when ctx is done.
+func (c *BiCache) GetOrLoadContext(ctx context.Context, key interface{}, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	entry, _, err := c.lookupWith(ctx, key, func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		value, err := loader(ctx)
+		return LoaderResult{Value: value, TTL: ttl}, err
+	})
+	return entry.Value, err
+}
+
+// GetOrLoad returns the value stored under key, calling loader once on concurrent misses.
+func (c *ShardedBiCache) GetOrLoad(key interface{}, ttl time.Duration, loader func() (interface{}, error)) (interface{}, error) {
+	return c.Shard(key).GetOrLoad(key, ttl, loader)
+}
+
+// GetOrLoad is the typed variant of BiCache.GetOrLoad.
+func (c *TypedBiCache[K, V]) GetOrLoad(key K, ttl time.Duration, loader func() (V, error)) (V, error) {
+	value, err := c.cache.GetOrLoad(key, ttl, func() (interface{}, error) {
+		return loader()
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	typed, _ := typedValue[V](value, true)
+	return typed, nil
+}
@@ -0,0 +1,61 @@
+package bicache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_GetOrLoad(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	var loads int64
+	release := make(chan struct{})
+	loader := func() (interface{}, error) {
+		atomic.AddInt64(&loads, 1)
+		<-release
+		return "loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	values := make(chan interface{}, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.GetOrLoad("key1", time.Minute, loader)
+			if err != nil {
+				t.Errorf("GetOrLoad test failed. Unexpected error: %v", err)
+			}
+			values <- value
+		}()
+	}
+
+	// Let the goroutines pile up on the running load
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(values)
+
+	if n := atomic.LoadInt64(&loads); n != 1 {
+		t.Errorf("GetOrLoad test failed. Expected: 1 load, Got: %d", n)
+	}
+	for value := range values {
+		if value != "loaded" {
+			t.Errorf("GetOrLoad test failed. Expected: loaded, Got: %v", value)
+		}
+	}
+	if value, found := cache.Get("key1"); !found || value != "loaded" {
+		t.Errorf("GetOrLoad test failed. Expected: loaded value stored, Got: %v", value)
+	}
+
+	errLoad := errors.New("load failed")
+	if _, err := cache.GetOrLoad("key2", time.Minute, func() (interface{}, error) { return nil, errLoad }); err != errLoad {
+		t.Errorf("GetOrLoad test failed. Expected: %v, Got: %v", errLoad, err)
+	}
+	if cache.Has("key2") {
+		t.Errorf("GetOrLoad test failed. Expected: failed load not stored")
+	}
+}
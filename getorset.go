@@ -0,0 +1,131 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// GetOrSet returns the value stored under key if there is one, and otherwise stores value and
+// returns it. Both happen under one lock, so of several goroutines racing on a missing key only
+// the first stores its value and the others get it back. loaded reports whether the value was
+// already stored. Loaders are not called. Values that can't be stored, for example because the
+// validator rejects them, are returned without being stored.
+func (c *BiCache) GetOrSet(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) (actual interface{}, loaded bool) {
+	options := newSetOptions(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return value, false
+	}
+
+	if current, found := c.liveValue(key, time.Now()); found {
+		return current, true
+	}
+
+	c.metrics.Misses.Add(1)
+	c.store(context.Background(), key, value, expiration, options)
+	return value, false
+}
+
+// Add stores value under key only if no live entry is stored there yet, and reports whether it
+// did. The check and the write happen under one lock, so of several goroutines adding the same key
+// exactly one succeeds until the entry expires or is deleted, which makes it usable for locks and
+// deduplication. Unlike GetOrSet it neither reads nor touches an existing entry.
+func (c *BiCache) Add(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) bool {
+	options := newSetOptions(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return false
+	}
+
+	if current, exists := c.cacheMap[c.mapKey(key)]; exists && c.live(key, current, time.Now()) {
+		return false
+	}
+
+	// The cache policy and admission may drop the entry without an error
+	stored, _ := c.tryStore(context.Background(), key, value, expiration, options)
+	return stored
+}
+
+// Replace stores value under key only if a live entry is already stored there, and reports whether
+// it did. Unlike Set it never creates an entry, so a write racing with a Delete of the key can't
+// bring back a value the data source no longer has.
+func (c *BiCache) Replace(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) bool {
+	options := newSetOptions(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return false
+	}
+
+	if current, exists := c.cacheMap[c.mapKey(key)]; !exists || !c.live(key, current, time.Now()) {
+		return false
+	}
+
+	// The cache policy may drop the entry without an error
+	stored, _ := c.tryStore(context.Background(), key, value, expiration, options)
+	return stored
+}
+
+// liveValue returns the decoded value of the live entry stored under key and counts the read
+// as a hit. Values that can't be decoded are treated as missing. The caller must hold the write lock.
+func (c *BiCache) liveValue(key interface{}, now time.Time) (interface{}, bool) {
+	mapped := c.mapKey(key)
+	entry, exists := c.cacheMap[mapped]
+	if !exists || !c.live(key, entry, now) {
+		return nil, false
+	}
+
+	value, err := c.decodeStored(entry.Value)
+	if err != nil {
+		c.metrics.DecodeError.Add(1)
+		c.reportError("get", key, err)
+		return nil, false
+	}
+
+	entry.touch(now)
+	c.recordRead(mapped, now)
+	c.metrics.Hits.Add(1)
+	return value, true
+}
+
+// GetOrSet returns the value stored under key, storing value if there is none.
+func (c *ShardedBiCache) GetOrSet(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) (interface{}, bool) {
+	return c.Shard(key).GetOrSet(key, value, expiration, opts...)
+}
+
+// Add stores value under key if no live entry is stored there yet.
+func (c *ShardedBiCache) Add(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) bool {
+	return c.Shard(key).Add(key, value, expiration, opts...)
+}
+
+// Replace stores value under key if a live entry is already stored there.
+func (c *ShardedBiCache) Replace(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) bool {
+	return c.Shard(key).Replace(key, value, expiration, opts...)
+}
+
+// GetOrSet is the typed variant of BiCache.GetOrSet.
+func (c *TypedBiCache[K, V]) GetOrSet(key K, value V, expiration time.Duration, opts ...SetOption) (V, bool) {
+	actual, loaded := c.cache.GetOrSet(key, value, expiration, opts...)
+	if typed, ok := typedValue[V](actual, true); ok {
+		return typed, loaded
+	}
+	return value, loaded
+}
+
+// Add is the typed variant of BiCache.Add.
+func (c *TypedBiCache[K, V]) Add(key K, value V, expiration time.Duration, opts ...SetOption) bool {
+	return c.cache.Add(key, value, expiration, opts...)
+}
+
+// Replace is the typed variant of BiCache.Replace.
+func (c *TypedBiCache[K, V]) Replace(key K, value V, expiration time.Duration, opts ...SetOption) bool {
+	return c.cache.Replace(key, value, expiration, opts...)
+}
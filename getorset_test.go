@@ -0,0 +1,133 @@
+package bicache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_GetOrSet(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	if actual, loaded := cache.GetOrSet("key1", "value1", 0); loaded || actual != "value1" {
+		t.Errorf("GetOrSet test failed. Expected: value1 stored, Got: %v, %v", actual, loaded)
+	}
+	if actual, loaded := cache.GetOrSet("key1", "value2", 0); !loaded || actual != "value1" {
+		t.Errorf("GetOrSet test failed. Expected: value1 loaded, Got: %v, %v", actual, loaded)
+	}
+
+	// Only one of the racing goroutines stores its value
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stored := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, loaded := cache.GetOrSet("key2", i, 0); !loaded {
+				mu.Lock()
+				stored++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if stored != 1 {
+		t.Errorf("GetOrSet test failed. Expected: 1 stored value, Got: %d", stored)
+	}
+}
+
+func TestBiCache_Add(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	if !cache.Add("key1", "value1", time.Millisecond*20) {
+		t.Errorf("Add test failed. Expected: value1 added")
+	}
+	if cache.Add("key1", "value2", 0) {
+		t.Errorf("Add test failed. Expected: value2 not added")
+	}
+	if value, _ := cache.Get("key1"); value != "value1" {
+		t.Errorf("Add test failed. Expected: value1, Got: %v", value)
+	}
+
+	// An expired entry no longer blocks the key
+	time.Sleep(time.Millisecond * 30)
+	if !cache.Add("key1", "value3", 0) {
+		t.Errorf("Add test failed. Expected: value3 added after expiry")
+	}
+
+	// Entries dropped by the cache policy are not reported as added
+	cache.SetCachePolicy(func(key interface{}, entry CacheEntry) bool { return false })
+	if cache.Add("key2", "value", 0) {
+		t.Errorf("Add test failed. Expected: rejected entry not added")
+	}
+	cache.SetCachePolicy(nil)
+
+	// Nor are they when an expired entry is still stored under the key
+	cache.Set("key3", "old", time.Millisecond)
+	time.Sleep(time.Millisecond * 5)
+	cache.SetCachePolicy(func(key interface{}, entry CacheEntry) bool { return false })
+	if cache.Add("key3", "value", 0) {
+		t.Errorf("Add test failed. Expected: rejected entry not added over an expired one")
+	}
+	cache.SetCachePolicy(nil)
+
+	// Exactly one of the racing goroutines adds the key
+	var wg sync.WaitGroup
+	var added atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if cache.Add("lock", i, time.Minute) {
+				added.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if added.Load() != 1 {
+		t.Errorf("Add test failed. Expected: 1 added value, Got: %d", added.Load())
+	}
+}
+
+func TestBiCache_Replace(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	if cache.Replace("key1", "value1", 0) || cache.Has("key1") {
+		t.Errorf("Replace test failed. Expected: missing key not created")
+	}
+
+	cache.Set("key1", "value1", time.Millisecond*20)
+	if !cache.Replace("key1", "value2", time.Millisecond*20) {
+		t.Errorf("Replace test failed. Expected: value2 replaced")
+	}
+	if value, _ := cache.Get("key1"); value != "value2" {
+		t.Errorf("Replace test failed. Expected: value2, Got: %v", value)
+	}
+
+	// An expired entry is not replaced
+	time.Sleep(time.Millisecond * 30)
+	if cache.Replace("key1", "value3", 0) || cache.Has("key1") {
+		t.Errorf("Replace test failed. Expected: expired entry not replaced")
+	}
+
+	// Read-only entries are not replaced
+	cache.SetWithOptions("key2", "locked", 0, WithReadOnly())
+	if cache.Replace("key2", "other", 0) {
+		t.Errorf("Replace test failed. Expected: read-only entry not replaced")
+	}
+
+	// Entries dropped by the cache policy are not reported as replaced
+	cache.Set("key3", "old", 0)
+	cache.SetCachePolicy(func(key interface{}, entry CacheEntry) bool { return false })
+	if cache.Replace("key3", "new", 0) {
+		t.Errorf("Replace test failed. Expected: rejected value not replaced")
+	}
+	cache.SetCachePolicy(nil)
+	if value, _ := cache.Get("key3"); value != "old" {
+		t.Errorf("Replace test failed. Expected: old, Got: %v", value)
+	}
+}
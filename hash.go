@@ -0,0 +1,54 @@
+package bicache
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"math"
+)
+
+// keyHasher hashes arbitrary comparable keys with a per-cache seed.
+type keyHasher struct {
+	seed maphash.Seed
+}
+
+func newKeyHasher() keyHasher {
+	return keyHasher{seed: maphash.MakeSeed()}
+}
+
+// hash returns a 64-bit hash of key. Common key types are hashed directly,
+// other types are hashed through their Go-syntax representation.
+func (h keyHasher) hash(key interface{}) uint64 {
+	var hash maphash.Hash
+	hash.SetSeed(h.seed)
+
+	var buf [8]byte
+	switch k := key.(type) {
+	case string:
+		hash.WriteString(k)
+	case int:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		hash.Write(buf[:])
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		hash.Write(buf[:])
+	case int32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		hash.Write(buf[:])
+	case uint:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		hash.Write(buf[:])
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], k)
+		hash.Write(buf[:])
+	case uint32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		hash.Write(buf[:])
+	case float64:
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(k))
+		hash.Write(buf[:])
+	default:
+		fmt.Fprintf(&hash, "%T:%#v", key, key)
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,114 @@
+package bicache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// Version is a past value of a key recorded by SetHistory.
+type Version struct {
+	// Value is the written value, or nil if it did not fit in the memory budget.
+	Value interface{}
+	// Hash identifies the value even if it was not kept.
+	Hash uint64
+	// Time is when the value was written or deleted.
+	Time time.Time
+	// Deleted marks the deletion of the key.
+	Deleted bool
+	size    int64
+}
+
+// history keeps the last versions of each key.
+type history struct {
+	versions int
+	budget   int64
+	used     int64
+	keys     map[interface{}][]Version
+}
+
+// SetHistory keeps the last versions writes and deletes of each key for History, to find out what
+// was cached when something went wrong. The values themselves are kept as long as they fit in
+// memoryBudget bytes in total, later ones only by hash. Histories of keys that are no longer cached
+// are dropped on cleanup. Replacing the settings drops the recorded history, and 0 versions disables it.
+func (c *BiCache) SetHistory(versions int, memoryBudget int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.history = nil
+	if versions > 0 {
+		c.history = &history{versions: versions, budget: memoryBudget, keys: make(map[interface{}][]Version)}
+	}
+}
+
+// History returns the recorded versions of key, oldest first.
+func (c *BiCache) History(key interface{}) []Version {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.history == nil {
+		return nil
+	}
+	return append([]Version(nil), c.history.keys[key]...)
+}
+
+// recordVersion adds a write of value, or a delete, to the history of key. The caller must hold the write lock.
+func (c *BiCache) recordVersion(key, value interface{}, deleted bool, now time.Time) {
+	h := c.history
+	if h == nil || deleted && len(h.keys[key]) == 0 {
+		return
+	}
+
+	version := Version{Time: now, Deleted: deleted}
+	if !deleted {
+		version.Hash = hashValue(value)
+		if size := estimateSize(value); h.used+size <= h.budget {
+			version.Value, version.size = value, size
+			h.used += size
+		}
+	}
+
+	versions := append(h.keys[key], version)
+	if drop := len(versions) - h.versions; drop > 0 {
+		for _, dropped := range versions[:drop] {
+			h.used -= dropped.size
+		}
+		versions = append([]Version(nil), versions[drop:]...)
+	}
+	h.keys[key] = versions
+}
+
+// cleanupHistory drops the histories of keys that are no longer cached. The caller must hold the write lock.
+func (c *BiCache) cleanupHistory() {
+	h := c.history
+	if h == nil {
+		return
+	}
+	for key, versions := range h.keys {
+		if _, cached := c.cacheMap[c.mapKey(key)]; !cached {
+			for _, version := range versions {
+				h.used -= version.size
+			}
+			delete(h.keys, key)
+		}
+	}
+}
+
+// hashValue returns an FNV-1a hash of the printed value.
+func hashValue(value interface{}) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%#v", value)
+	return h.Sum64()
+}
+
+// SetHistory enables the history on every shard, each with its own memory budget.
+func (c *ShardedBiCache) SetHistory(versions int, memoryBudget int64) {
+	for _, shard := range c.shards {
+		shard.SetHistory(versions, memoryBudget)
+	}
+}
+
+// History returns the recorded versions of key, oldest first.
+func (c *ShardedBiCache) History(key interface{}) []Version {
+	return c.Shard(key).History(key)
+}
@@ -0,0 +1,50 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_History(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	if versions := cache.History("key1"); versions != nil {
+		t.Errorf("History test failed. Expected: no history, Got: %v", versions)
+	}
+
+	cache.SetHistory(3, 12)
+	cache.Set("key1", "aaaa", 0)
+	cache.Set("key1", "bbbb", 0)
+	cache.Set("key1", "cccc", 0)
+	cache.Set("key1", "dddd", 0)
+
+	// The oldest version is dropped, and the last one no longer fits in the budget
+	versions := cache.History("key1")
+	if len(versions) != 3 {
+		t.Fatalf("History test failed. Expected: 3 versions, Got: %d", len(versions))
+	}
+	if versions[0].Value != "bbbb" || versions[1].Value != "cccc" || versions[2].Value != nil {
+		t.Errorf("History test failed. Expected: bbbb, cccc, nil, Got: %v, %v, %v", versions[0].Value, versions[1].Value, versions[2].Value)
+	}
+	if versions[2].Hash != hashValue("dddd") || versions[2].Hash == versions[1].Hash {
+		t.Errorf("History test failed. Expected: hash of dddd, Got: %d", versions[2].Hash)
+	}
+	if versions[0].Time.After(versions[2].Time) {
+		t.Errorf("History test failed. Expected: oldest version first")
+	}
+
+	cache.Delete("key1")
+	versions = cache.History("key1")
+	if last := versions[len(versions)-1]; !last.Deleted {
+		t.Errorf("History test failed. Expected: deletion recorded, Got: %+v", last)
+	}
+
+	// Histories of keys that are no longer cached are dropped on cleanup
+	cache.RunCleanup()
+	if versions := cache.History("key1"); len(versions) != 0 {
+		t.Errorf("History test failed. Expected: no history, Got: %d versions", len(versions))
+	}
+	cache.Set("key2", "aaaa", 0)
+	if versions := cache.History("key2"); len(versions) != 1 || versions[0].Value != "aaaa" {
+		t.Errorf("History test failed. Expected: budget freed for aaaa, Got: %+v", versions)
+	}
+}
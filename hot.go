@@ -0,0 +1,179 @@
+package bicache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// hotStore keeps copies of frequently read entries in a copy-on-write map,
+// so hits on them don't take the cache lock at all.
+type hotStore struct {
+	mu        sync.Mutex
+	entries   atomic.Value
+	threshold int
+	max       int
+	hits      int64
+}
+
+func newHotStore(threshold, max int) *hotStore {
+	store := &hotStore{threshold: threshold, max: max}
+	store.entries.Store(map[interface{}]CacheEntry{})
+	return store
+}
+
+func (h *hotStore) load() map[interface{}]CacheEntry {
+	return h.entries.Load().(map[interface{}]CacheEntry)
+}
+
+// get returns a non-expired hot entry without locking.
+func (h *hotStore) get(key interface{}) (CacheEntry, bool) {
+	entry, exists := h.load()[key]
+	if !exists || (!entry.Expiration.IsZero() && !time.Now().Before(entry.Expiration)) {
+		return CacheEntry{}, false
+	}
+
+	atomic.AddInt64(&h.hits, 1)
+	entry.Metadata = copyMetadata(entry.Metadata)
+	entry.Tags = copyTags(entry.Tags)
+	return entry, true
+}
+
+// add copies entries into a new map with entry added, unless the store is full.
+func (h *hotStore) add(key interface{}, entry CacheEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current := h.load()
+	if _, exists := current[key]; exists || len(current) >= h.max {
+		return
+	}
+
+	entries := make(map[interface{}]CacheEntry, len(current)+1)
+	for k, e := range current {
+		entries[k] = e
+	}
+	entries[key] = entry
+	h.entries.Store(entries)
+}
+
+// remove copies entries into a new map without the keys matching drop.
+func (h *hotStore) remove(drop func(key interface{}) bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current := h.load()
+	entries := make(map[interface{}]CacheEntry, len(current))
+	for k, e := range current {
+		if !drop(k) {
+			entries[k] = e
+		}
+	}
+	if len(entries) != len(current) {
+		h.entries.Store(entries)
+	}
+}
+
+// EnableHotEntries serves keys whose estimated access frequency reaches threshold from a lock-free
+// copy-on-write store holding up to max entries. Hot entries are invalidated whenever their key is
+// written or removed. It enables the frequency sketch if needed. A threshold of 0 or less disables it.
+func (c *BiCache) EnableHotEntries(threshold, max int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if threshold <= 0 || max <= 0 {
+		c.hot.Store(nil)
+		return
+	}
+
+	if c.sketch == nil {
+		c.sketch = newCountMinSketch(c.capacity, c.hasher)
+	}
+	c.hot.Store(newHotStore(threshold, max))
+}
+
+// getHot returns key from the hot store, if it is there.
+func (c *BiCache) getHot(key interface{}) (CacheEntry, bool) {
+	hot := c.hot.Load()
+	if hot == nil {
+		return CacheEntry{}, false
+	}
+	entry, found := hot.get(c.mapKey(key))
+	if found && c.tooStale(entry, time.Now()) {
+		// Leave the entry to the locked path, which removes it
+		return CacheEntry{}, false
+	}
+	return entry, found
+}
+
+// touchHot records a hit on a hot entry like readEntry records one on a stored entry, so that hot
+// keys keep their access time, eviction position and frequency.
+func (c *BiCache) touchHot(key interface{}, entry *CacheEntry) {
+	now := time.Now()
+	entry.Accessed = now
+	// The hot copy shares the access time of the stored entry
+	entry.touch(now)
+	c.recordAccess(key)
+	if reads := c.reads.record(c.mapKey(key), now); reads != nil {
+		c.mu.RLock()
+		c.applyReads(reads)
+		c.mu.RUnlock()
+	}
+}
+
+// promoteHot copies a decoded entry into the hot store once its key is read often enough.
+func (c *BiCache) promoteHot(key interface{}, entry CacheEntry) {
+	hot := c.hot.Load()
+	if hot == nil {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	key = c.mapKey(key)
+	if c.sketch == nil || c.sketch.estimate(key) < hot.threshold {
+		return
+	}
+
+	// Don't promote a copy that was replaced since it was read
+	current, exists := c.cacheMap[key]
+	if !exists || !current.created.Equal(entry.created) || !current.Expiration.Equal(entry.Expiration) {
+		return
+	}
+	hot.add(key, entry)
+}
+
+// isHot reports whether key is in the hot store.
+func (c *BiCache) isHot(key interface{}) bool {
+	hot := c.hot.Load()
+	if hot == nil {
+		return false
+	}
+	_, exists := hot.load()[key]
+	return exists
+}
+
+// invalidateHot drops key from the hot store. The caller must hold the write lock.
+func (c *BiCache) invalidateHot(key interface{}) {
+	hot := c.hot.Load()
+	if hot == nil {
+		return
+	}
+	if _, exists := hot.load()[key]; exists {
+		hot.remove(func(k interface{}) bool {
+			return k == key
+		})
+	}
+}
+
+// cleanupHot demotes hot entries whose keys are no longer read often enough.
+func (c *BiCache) cleanupHot() {
+	hot := c.hot.Load()
+	if hot == nil || c.sketch == nil {
+		return
+	}
+	hot.remove(func(key interface{}) bool {
+		return c.sketch.estimate(key) < hot.threshold
+	})
+}
@@ -0,0 +1,89 @@
+package bicache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_HotEntries(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.EnableHotEntries(3, 5)
+
+	cache.Set("hot", "value1", time.Minute)
+	for i := 0; i < 5; i++ {
+		cache.Get("hot")
+	}
+
+	// Further reads are served from the hot store
+	if result, found := cache.Get("hot"); !found || result != "value1" {
+		t.Errorf("HotEntries test failed. Expected: 'value1', Got: '%v'", result)
+	}
+	metrics := cache.GetMetrics()
+	if metrics.HotHits == 0 || metrics.Hits != 6 {
+		t.Errorf("HotEntries test failed. Expected: hot hits and 6 hits, Got: HotHits=%v, Hits=%v", metrics.HotHits, metrics.Hits)
+	}
+
+	// Updates invalidate the hot copy
+	cache.Set("hot", "value2", time.Minute)
+	if result, _ := cache.Get("hot"); result != "value2" {
+		t.Errorf("HotEntries test (update) failed. Expected: 'value2', Got: '%v'", result)
+	}
+
+	// Deletes invalidate the hot copy
+	cache.Delete("hot")
+	if result, found := cache.Get("hot"); found {
+		t.Errorf("HotEntries test (delete) failed. Expected: not found, Got: '%v'", result)
+	}
+}
+
+func TestBiCache_HotEntriesConcurrent(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.EnableHotEntries(2, 5)
+	cache.Set("hot", 0, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				cache.Get("hot")
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		cache.Set("hot", i, time.Minute)
+	}
+	wg.Wait()
+
+	// The last write must win over any hot copy promoted concurrently
+	if result, _ := cache.Get("hot"); result != 100 {
+		t.Errorf("HotEntries concurrent test failed. Expected: 100, Got: '%v'", result)
+	}
+}
+
+func TestBiCache_HotEntriesTouch(t *testing.T) {
+	cache := NewBiCache(2, time.Minute)
+	cache.SetGlobalExpiration(time.Millisecond * 50)
+	cache.EnableHotEntries(1, 5)
+
+	cache.Set("hot", "value", 0)
+	cache.Set("cold", "value", 0)
+
+	// Hot hits keep the entry from expiring while it is idle for longer than the global expiration
+	for i := 0; i < 10; i++ {
+		cache.Get("hot")
+		time.Sleep(time.Millisecond * 10)
+	}
+	cache.RunCleanup()
+	if _, found := cache.Get("hot"); !found {
+		t.Errorf("HotEntriesTouch test failed. Expected: hot entry kept by its reads")
+	}
+	if _, found := cache.Get("cold"); found {
+		t.Errorf("HotEntriesTouch test failed. Expected: cold entry expired")
+	}
+	if metrics := cache.GetMetrics(); metrics.HotHits == 0 {
+		t.Errorf("HotEntriesTouch test failed. Expected: hot hits")
+	}
+}
@@ -0,0 +1,117 @@
+package bicache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// HTTPTransport is an http.RoundTripper that serves GET requests from a cache. Responses are cached
+// with the TTL decided by Policy and per Vary header: requests that differ in a header the response
+// varies on, such as Accept-Encoding or Accept-Language, are cached separately.
+type HTTPTransport struct {
+	Cache *BiCache
+	// Transport makes the requests that miss, http.DefaultTransport if nil.
+	Transport http.RoundTripper
+	Policy    HTTPTTLPolicy
+}
+
+// cachedResponse is a response stored by HTTPTransport.
+type cachedResponse struct {
+	Status     string
+	StatusCode int
+	Proto      string
+	Header     http.Header
+	Body       []byte
+}
+
+// RoundTrip serves req from the cache or forwards it and caches successful responses.
+func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet {
+		return transport.RoundTrip(req)
+	}
+
+	url := req.URL.String()
+	if value, found := t.Cache.Get(httpVaryKey(url)); found {
+		if cached, found := t.Cache.Get(httpResponseKey(url, value.([]string), req.Header)); found {
+			return cached.(*cachedResponse).response(req), nil
+		}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	ttl, cacheable := t.Policy.TTL(resp)
+	vary := httpVaryHeaders(resp.Header)
+	if !cacheable || (len(vary) == 1 && vary[0] == "*") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	cached := &cachedResponse{Status: resp.Status, StatusCode: resp.StatusCode, Proto: resp.Proto, Header: resp.Header.Clone(), Body: body}
+	t.Cache.Set(httpVaryKey(url), vary, ttl)
+	t.Cache.Set(httpResponseKey(url, vary, req.Header), cached, ttl)
+	return resp, nil
+}
+
+func (r *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        r.Status,
+		StatusCode:    r.StatusCode,
+		Proto:         r.Proto,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// httpVaryKey is the key of the headers the responses for url vary on.
+func httpVaryKey(url string) string {
+	return "http:vary:" + url
+}
+
+// httpResponseKey is the key of the response for url to requests with the given values of the vary headers.
+func httpResponseKey(url string, vary []string, header http.Header) string {
+	var key strings.Builder
+	key.WriteString("http:response:")
+	key.WriteString(url)
+	for _, name := range vary {
+		key.WriteString("\n")
+		key.WriteString(name)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(header.Values(name), ","))
+	}
+	return key.String()
+}
+
+// httpVaryHeaders returns the canonical, sorted names in the Vary headers of a response.
+func httpVaryHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, textproto.CanonicalMIMEHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,43 @@
+package bicache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPTransport_Vary(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte("hello in " + r.Header.Get("Accept-Language")))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &HTTPTransport{Cache: NewBiCache(10, time.Minute)}}
+	get := func(language string) string {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("Accept-Language", language)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("HTTPTransport test failed. Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if body := get("en"); body != "hello in en" {
+		t.Errorf("HTTPTransport test failed. Expected: hello in en, Got: %v", body)
+	}
+	if body := get("de"); body != "hello in de" {
+		t.Errorf("HTTPTransport test failed. Expected: hello in de, Got: %v", body)
+	}
+	if body := get("en"); body != "hello in en" || requests != 2 {
+		t.Errorf("HTTPTransport test failed. Expected: cached hello in en after 2 requests, Got: %v after %v", body, requests)
+	}
+}
@@ -0,0 +1,158 @@
+package bicache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPTTLPolicy derives the TTL of a value loaded over HTTP from the Cache-Control, Expires,
+// Date and Age headers of the response.
+type HTTPTTLPolicy struct {
+	// Default is the TTL of responses without freshness headers. Zero keeps them until evicted.
+	Default time.Duration
+	// Min and Max clamp the TTLs taken from headers, if set.
+	Min time.Duration
+	Max time.Duration
+	// Routes overrides the TTL of requests whose URL path starts with a key; the longest matching
+	// path wins and the headers are ignored.
+	Routes map[string]time.Duration
+}
+
+// TTL returns the TTL of resp. It reports false for responses that must not be cached: those
+// with no-store or no-cache, and those already stale.
+func (p HTTPTTLPolicy) TTL(resp *http.Response) (time.Duration, bool) {
+	if resp.Request != nil && resp.Request.URL != nil {
+		if ttl, exists := p.route(resp.Request.URL.Path); exists {
+			return ttl, true
+		}
+	}
+
+	ttl, fromHeaders, cacheable := headerTTL(resp.Header, time.Now())
+	if !cacheable {
+		return 0, false
+	}
+	if !fromHeaders {
+		return p.Default, true
+	}
+
+	if p.Min > 0 && ttl < p.Min {
+		ttl = p.Min
+	}
+	if p.Max > 0 && ttl > p.Max {
+		ttl = p.Max
+	}
+	return ttl, true
+}
+
+// route returns the override of the longest route matching path.
+func (p HTTPTTLPolicy) route(path string) (time.Duration, bool) {
+	longest := -1
+	var ttl time.Duration
+	for prefix, routeTTL := range p.Routes {
+		if len(prefix) > longest && strings.HasPrefix(path, prefix) {
+			ttl, longest = routeTTL, len(prefix)
+		}
+	}
+	return ttl, longest >= 0
+}
+
+// headerTTL returns the remaining freshness of a response by its headers, whether the headers
+// specified one, and whether the response may be cached at all.
+func headerTTL(header http.Header, now time.Time) (time.Duration, bool, bool) {
+	var maxAge, sharedMaxAge time.Duration = -1, -1
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, false, false
+		case "max-age":
+			maxAge = parseSeconds(value)
+		case "s-maxage":
+			sharedMaxAge = parseSeconds(value)
+		}
+	}
+
+	var age time.Duration
+	if seconds := parseSeconds(header.Get("Age")); seconds > 0 {
+		age = seconds
+	}
+
+	var lifetime time.Duration
+	switch {
+	case sharedMaxAge >= 0:
+		lifetime = sharedMaxAge
+	case maxAge >= 0:
+		lifetime = maxAge
+	case header.Get("Expires") != "":
+		expires, err := http.ParseTime(header.Get("Expires"))
+		if err != nil {
+			// Invalid dates mean already expired
+			return 0, true, false
+		}
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			date = now
+		}
+		lifetime = expires.Sub(date)
+	default:
+		return 0, false, true
+	}
+
+	ttl := lifetime - age
+	return ttl, true, ttl > 0
+}
+
+// parseSeconds parses a header value in seconds, returning -1 if it is invalid.
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+	if err != nil || seconds < 0 {
+		return -1
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// NewHTTPLoader returns a loader that GETs the URL returned by urlFor for a key with client,
+// or http.DefaultClient if nil, and caches the response body as a []byte with the TTL decided
+// by policy. Not found responses load as ErrNotFound and other non-2xx responses fail. Bodies
+// that must not be cached are returned with a negative TTL, so they are served once but not kept.
+func NewHTTPLoader(client *http.Client, urlFor func(key interface{}) string, policy HTTPTTLPolicy) ContextLoaderFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlFor(key), nil)
+		if err != nil {
+			return LoaderResult{}, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return LoaderResult{}, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return LoaderResult{}, ErrNotFound
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return LoaderResult{}, fmt.Errorf("bicache: loading %v returned status %d", key, resp.StatusCode)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return LoaderResult{}, err
+		}
+
+		ttl, cacheable := policy.TTL(resp)
+		if !cacheable {
+			ttl = -1
+		}
+		return LoaderResult{Value: body, TTL: ttl, Token: resp.Header.Get("ETag")}, nil
+	}
+}
@@ -0,0 +1,74 @@
+package bicache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPTTLPolicy(t *testing.T) {
+	policy := HTTPTTLPolicy{
+		Default: time.Minute,
+		Min:     10 * time.Second,
+		Max:     time.Hour,
+		Routes:  map[string]time.Duration{"/static/": 24 * time.Hour},
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		path      string
+		header    http.Header
+		ttl       time.Duration
+		cacheable bool
+	}{
+		{"max-age", "/api", http.Header{"Cache-Control": {"public, max-age=300"}}, 5 * time.Minute, true},
+		{"s-maxage wins", "/api", http.Header{"Cache-Control": {"max-age=300, s-maxage=600"}}, 10 * time.Minute, true},
+		{"age", "/api", http.Header{"Cache-Control": {"max-age=300"}, "Age": {"100"}}, 200 * time.Second, true},
+		{"min clamp", "/api", http.Header{"Cache-Control": {"max-age=1"}}, 10 * time.Second, true},
+		{"max clamp", "/api", http.Header{"Cache-Control": {"max-age=86400"}}, time.Hour, true},
+		{"expires", "/api", http.Header{"Date": {now.UTC().Format(http.TimeFormat)}, "Expires": {now.Add(2 * time.Minute).UTC().Format(http.TimeFormat)}}, 2 * time.Minute, true},
+		{"no-store", "/api", http.Header{"Cache-Control": {"no-store"}}, 0, false},
+		{"stale", "/api", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"120"}}, 0, false},
+		{"default", "/api", http.Header{}, time.Minute, true},
+		{"route", "/static/app.js", http.Header{"Cache-Control": {"no-store"}}, 24 * time.Hour, true},
+	}
+
+	for _, test := range tests {
+		resp := &http.Response{Header: test.header, Request: httptest.NewRequest("GET", test.path, nil)}
+		ttl, cacheable := policy.TTL(resp)
+		if ttl != test.ttl || cacheable != test.cacheable {
+			t.Errorf("HTTPTTLPolicy test (%s) failed. Expected: %v %v, Got: %v %v", test.name, test.ttl, test.cacheable, ttl, cacheable)
+		}
+	}
+}
+
+func TestNewHTTPLoader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=120")
+		w.Write([]byte("body of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	cache := NewBiCache(10, time.Minute)
+	cache.RegisterContextLoader("/", NewHTTPLoader(nil, func(key interface{}) string {
+		return server.URL + key.(string)
+	}, HTTPTTLPolicy{}))
+
+	value, info, found := cache.GetWithInfo("/page")
+	if !found || string(value.([]byte)) != "body of /page" {
+		t.Fatalf("NewHTTPLoader test failed. Expected: body of /page, Got: %v", value)
+	}
+	if remaining := time.Until(info.Expiration); remaining <= time.Minute || remaining > 2*time.Minute {
+		t.Errorf("NewHTTPLoader test failed. Expected: expiration in about 2m, Got: %v", remaining)
+	}
+
+	if _, err := cache.GetWithError("/missing"); err != ErrNotFound {
+		t.Errorf("NewHTTPLoader test (missing) failed. Expected: ErrNotFound, Got: %v", err)
+	}
+}
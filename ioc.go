@@ -0,0 +1,402 @@
+package bicache
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Indicator is a threat-intel indicator of compromise, such as an IP address, domain or file hash,
+// as reported by one feed.
+type Indicator struct {
+	Value      string   `json:"value"`
+	Type       string   `json:"type,omitempty"`
+	Feed       string   `json:"feed,omitempty"`
+	Confidence int      `json:"confidence,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// ValidUntil is when the feed stops vouching for the indicator. If set, it is used
+	// instead of the TTL of the feed.
+	ValidUntil time.Time `json:"valid_until,omitempty"`
+}
+
+// FeedInfo is the metadata of a threat-intel feed.
+type FeedInfo struct {
+	Name string
+	// TTL is how long indicators of the feed are cached, usually its update interval.
+	// IOCConfig.DefaultTTL is used if not set.
+	TTL time.Duration
+}
+
+// IOCLookupFunc looks up a value missing from the cache in an upstream threat-intel service.
+// It returns false if the value is not a known indicator.
+type IOCLookupFunc func(ctx context.Context, value string) (Indicator, bool, error)
+
+// IOCConfig configures an IOCCache.
+type IOCConfig struct {
+	// Capacity is the maximum number of cached values, 100000 if not set.
+	Capacity int
+	// CleanupInterval is how often expired values are removed, one minute if not set.
+	CleanupInterval time.Duration
+	// DefaultTTL is the TTL of feeds without one, 24 hours if not set.
+	DefaultTTL time.Duration
+	// NegativeTTL is how long a value Lookup found not to be an indicator is remembered, 5 minutes if not set.
+	NegativeTTL time.Duration
+	// Lookup is called for values missing from the cache. Without it, values missing from the cache
+	// are not indicators.
+	Lookup IOCLookupFunc
+}
+
+// FeedStats are the statistics of a threat-intel feed.
+type FeedStats struct {
+	TTL time.Duration
+	// Indicators is the number of indicators imported from the feed.
+	Indicators int64
+	// Hits and Misses count the lookups that did and did not match an indicator of the feed.
+	Hits       int64
+	Misses     int64
+	LastImport time.Time
+}
+
+// IOCStats are the statistics of an IOCCache.
+type IOCStats struct {
+	Lookups int64
+	Hits    int64
+	Misses  int64
+	// NegativeHits counts the misses answered by a remembered negative upstream lookup.
+	NegativeHits int64
+	// UpstreamLookups counts the calls of IOCConfig.Lookup.
+	UpstreamLookups int64
+	Feeds           map[string]FeedStats
+}
+
+// IOCCache is a cache preset for threat-intel indicator lookups. Indicators are bulk imported
+// from feeds and cached with the TTL of their feed, values that are not indicators are cached
+// for a short negative TTL, and hits and misses are counted per feed.
+type IOCCache struct {
+	// Cache stores the matches of each value, keyed by the normalized value.
+	Cache  *BiCache
+	config IOCConfig
+
+	// mu guards feeds and serializes imports.
+	mu    sync.RWMutex
+	feeds map[string]*iocFeed
+
+	lookups      atomic.Int64
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	upstream     atomic.Int64
+}
+
+type iocFeed struct {
+	info       FeedInfo
+	indicators atomic.Int64
+	hits       atomic.Int64
+	misses     atomic.Int64
+	lastImport time.Time
+}
+
+// iocMatch is an indicator stored with the time its feed stops vouching for it.
+type iocMatch struct {
+	indicator Indicator
+	expiresAt time.Time
+}
+
+// iocMatches are the indicators of one value from all feeds. An empty list is a negative entry.
+type iocMatches []iocMatch
+
+// NewIOCCache returns an IOCCache configured by config.
+func NewIOCCache(config IOCConfig) *IOCCache {
+	if config.Capacity <= 0 {
+		config.Capacity = 100000
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = time.Minute
+	}
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = 24 * time.Hour
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = 5 * time.Minute
+	}
+
+	return &IOCCache{
+		Cache:  NewBiCache(config.Capacity, config.CleanupInterval),
+		config: config,
+		feeds:  make(map[string]*iocFeed),
+	}
+}
+
+// AddFeed registers a feed or updates its metadata. Indicators already imported keep their TTL.
+func (c *IOCCache) AddFeed(info FeedInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.feed(info.Name).info = info
+}
+
+// feed returns the feed called name, registering it if needed. The caller must hold the write lock.
+func (c *IOCCache) feed(name string) *iocFeed {
+	feed, exists := c.feeds[name]
+	if !exists {
+		feed = &iocFeed{info: FeedInfo{Name: name}}
+		c.feeds[name] = feed
+	}
+	return feed
+}
+
+// feedTTL returns the TTL of a feed. The caller must hold the lock.
+func (c *IOCCache) feedTTL(feed *iocFeed) time.Duration {
+	if feed.info.TTL > 0 {
+		return feed.info.TTL
+	}
+	return c.config.DefaultTTL
+}
+
+// Import stores indicators reported by a feed, which is registered if needed, and returns how many
+// were stored. Indicators whose ValidUntil has passed are skipped. A value reported by several feeds
+// matches all of them.
+func (c *IOCCache) Import(feedName string, indicators []Indicator) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	feed := c.feed(feedName)
+	now := time.Now()
+	imported := 0
+	for _, indicator := range indicators {
+		indicator.Value = normalizeIndicator(indicator.Value)
+		indicator.Feed = feedName
+		indicator.Tags = copyTags(indicator.Tags)
+		if indicator.Value == "" {
+			continue
+		}
+
+		expiresAt := indicator.ValidUntil
+		if expiresAt.IsZero() {
+			expiresAt = now.Add(c.feedTTL(feed))
+		} else if !expiresAt.After(now) {
+			continue
+		}
+
+		matches := iocMatches{{indicator: indicator, expiresAt: expiresAt}}
+		if value, found := c.Cache.Peek(indicator.Value); found {
+			for _, match := range value.(iocMatches) {
+				if match.indicator.Feed != feedName && match.expiresAt.After(now) {
+					matches = append(matches, match)
+				}
+			}
+		}
+		c.store(indicator.Value, matches, now)
+		imported++
+	}
+
+	feed.indicators.Add(int64(imported))
+	feed.lastImport = now
+	return imported
+}
+
+// ImportFeed reads a plain text feed with one indicator per line and imports it like Import.
+// Empty lines and lines starting with # are skipped, and the type of each indicator is detected
+// from its value.
+func (c *IOCCache) ImportFeed(feedName string, r io.Reader) (int, error) {
+	var indicators []Indicator
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		indicators = append(indicators, Indicator{Value: line, Type: IndicatorType(line)})
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return c.Import(feedName, indicators), nil
+}
+
+// store caches the matches of a value until the last of them expires, tagged with their feeds.
+func (c *IOCCache) store(value string, matches iocMatches, now time.Time) {
+	var expiresAt time.Time
+	tags := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if match.expiresAt.After(expiresAt) {
+			expiresAt = match.expiresAt
+		}
+		tags = append(tags, "feed:"+match.indicator.Feed)
+	}
+	sort.Strings(tags)
+	c.Cache.SetWithOptions(value, matches, expiresAt.Sub(now), WithTags(tags...))
+}
+
+// Lookup returns the indicators matching value from all feeds, or nil if value is not an indicator.
+// Values missing from the cache are looked up upstream if IOCConfig.Lookup is set, and the result,
+// even a negative one, is cached.
+func (c *IOCCache) Lookup(ctx context.Context, value string) ([]Indicator, error) {
+	c.lookups.Add(1)
+	value = normalizeIndicator(value)
+
+	var loader ContextLoaderFunc
+	if c.config.Lookup != nil {
+		loader = c.load
+	}
+	entry, tier, err := c.Cache.lookupWith(ctx, value, loader)
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	var indicators []Indicator
+	matched := make(map[string]bool)
+	if matches, ok := entry.Value.(iocMatches); ok {
+		if len(matches) == 0 && tier == TierMemory {
+			c.negativeHits.Add(1)
+		}
+		now := time.Now()
+		for _, match := range matches {
+			if match.expiresAt.After(now) {
+				indicator := match.indicator
+				indicator.Tags = copyTags(indicator.Tags)
+				indicators = append(indicators, indicator)
+				matched[indicator.Feed] = true
+			}
+		}
+	}
+	if len(indicators) > 0 {
+		c.hits.Add(1)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, feed := range c.feeds {
+		if matched[name] {
+			feed.hits.Add(1)
+		} else {
+			feed.misses.Add(1)
+		}
+	}
+	return indicators, nil
+}
+
+// load looks up a missing value upstream. Indicators found are cached with the TTL of their feed,
+// "upstream" if the lookup does not name one, and values that are not indicators with the negative TTL.
+func (c *IOCCache) load(ctx context.Context, key interface{}) (LoaderResult, error) {
+	c.upstream.Add(1)
+	value := key.(string)
+	indicator, found, err := c.config.Lookup(ctx, value)
+	if err != nil {
+		return LoaderResult{}, err
+	}
+	if !found {
+		return LoaderResult{Value: iocMatches{}, TTL: c.config.NegativeTTL}, nil
+	}
+
+	indicator.Value = value
+	if indicator.Feed == "" {
+		indicator.Feed = "upstream"
+	}
+	indicator.Tags = copyTags(indicator.Tags)
+
+	c.mu.RLock()
+	ttl := c.config.DefaultTTL
+	if feed, exists := c.feeds[indicator.Feed]; exists {
+		ttl = c.feedTTL(feed)
+	}
+	c.mu.RUnlock()
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	if !indicator.ValidUntil.IsZero() {
+		expiresAt = indicator.ValidUntil
+		ttl = expiresAt.Sub(now)
+	}
+	if ttl <= 0 {
+		return LoaderResult{Value: iocMatches{}, TTL: c.config.NegativeTTL}, nil
+	}
+	return LoaderResult{
+		Value: iocMatches{{indicator: indicator, expiresAt: expiresAt}},
+		TTL:   ttl,
+		Tags:  []string{"feed:" + indicator.Feed},
+	}, nil
+}
+
+// Stats returns the lookup statistics of the cache and of each feed.
+func (c *IOCCache) Stats() IOCStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	lookups, hits := c.lookups.Load(), c.hits.Load()
+	stats := IOCStats{
+		Lookups:         lookups,
+		Hits:            hits,
+		Misses:          lookups - hits,
+		NegativeHits:    c.negativeHits.Load(),
+		UpstreamLookups: c.upstream.Load(),
+		Feeds:           make(map[string]FeedStats, len(c.feeds)),
+	}
+	for name, feed := range c.feeds {
+		stats.Feeds[name] = FeedStats{
+			TTL:        c.feedTTL(feed),
+			Indicators: feed.indicators.Load(),
+			Hits:       feed.hits.Load(),
+			Misses:     feed.misses.Load(),
+			LastImport: feed.lastImport,
+		}
+	}
+	return stats
+}
+
+// Close stops the cache.
+func (c *IOCCache) Close() {
+	c.Cache.Close()
+}
+
+// normalizeIndicator trims and lowercases a value so that lookups match regardless of case,
+// and removes the trailing dot of fully qualified domain names.
+func normalizeIndicator(value string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), ".")
+}
+
+// IndicatorType guesses the type of an indicator from its value: "ipv4", "ipv6", "cidr", "md5",
+// "sha1", "sha256", "url", "email" or "domain".
+func IndicatorType(value string) string {
+	value = strings.TrimSpace(value)
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return "ipv4"
+		}
+		return "ipv6"
+	}
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return "cidr"
+	}
+	if isHex(value) {
+		switch len(value) {
+		case 32:
+			return "md5"
+		case 40:
+			return "sha1"
+		case 64:
+			return "sha256"
+		}
+	}
+	if strings.Contains(value, "://") {
+		return "url"
+	}
+	if strings.Contains(value, "@") {
+		return "email"
+	}
+	return "domain"
+}
+
+func isHex(value string) bool {
+	for _, r := range value {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,116 @@
+package bicache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIOCCache_ImportFeed(t *testing.T) {
+	cache := NewIOCCache(IOCConfig{})
+	defer cache.Close()
+
+	cache.AddFeed(FeedInfo{Name: "abuse", TTL: time.Hour})
+	feed := "# blocklist\n198.51.100.7\n\nEvil.example.com.\n"
+	imported, err := cache.ImportFeed("abuse", strings.NewReader(feed))
+	if err != nil || imported != 2 {
+		t.Errorf("IOC import test failed. Expected: 2 indicators, Got: %v, %v", imported, err)
+	}
+	cache.Import("phish", []Indicator{{Value: "evil.example.com", Confidence: 80}})
+
+	indicators, err := cache.Lookup(context.Background(), "EVIL.example.com")
+	if err != nil || len(indicators) != 2 {
+		t.Fatalf("IOC import test failed. Expected: matches of both feeds, Got: %+v, %v", indicators, err)
+	}
+	if indicators[0].Feed != "phish" || indicators[1].Feed != "abuse" || indicators[1].Type != "domain" {
+		t.Errorf("IOC import test failed. Got: %+v", indicators)
+	}
+	if ttl, _ := cache.Cache.TTL("evil.example.com"); ttl <= time.Hour {
+		t.Errorf("IOC import test failed. Expected: the longest feed TTL, Got: %v", ttl)
+	}
+
+	cache.Lookup(context.Background(), "198.51.100.7")
+	cache.Lookup(context.Background(), "203.0.113.1")
+
+	stats := cache.Stats()
+	if stats.Lookups != 3 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("IOC import test failed. Expected: 3 lookups with 2 hits, Got: %+v", stats)
+	}
+	if abuse := stats.Feeds["abuse"]; abuse.Indicators != 2 || abuse.Hits != 2 || abuse.Misses != 1 || abuse.TTL != time.Hour {
+		t.Errorf("IOC import test failed. Got abuse: %+v", abuse)
+	}
+	if phish := stats.Feeds["phish"]; phish.Hits != 1 || phish.Misses != 2 || phish.TTL != 24*time.Hour {
+		t.Errorf("IOC import test failed. Got phish: %+v", phish)
+	}
+}
+
+func TestIOCCache_ValidUntil(t *testing.T) {
+	cache := NewIOCCache(IOCConfig{})
+	defer cache.Close()
+
+	imported := cache.Import("feed", []Indicator{
+		{Value: "expired.example.com", ValidUntil: time.Now().Add(-time.Minute)},
+		{Value: "valid.example.com", ValidUntil: time.Now().Add(time.Minute)},
+	})
+	if imported != 1 {
+		t.Errorf("IOC valid until test failed. Expected: 1, Got: %v", imported)
+	}
+	if ttl, _ := cache.Cache.TTL("valid.example.com"); ttl > time.Minute {
+		t.Errorf("IOC valid until test failed. Expected: at most a minute, Got: %v", ttl)
+	}
+}
+
+func TestIOCCache_NegativeCaching(t *testing.T) {
+	calls := 0
+	cache := NewIOCCache(IOCConfig{
+		NegativeTTL: time.Minute,
+		Lookup: func(ctx context.Context, value string) (Indicator, bool, error) {
+			calls++
+			if value == "d41d8cd98f00b204e9800998ecf8427e" {
+				return Indicator{Type: "md5", Feed: "sandbox"}, true, nil
+			}
+			return Indicator{}, false, nil
+		},
+	})
+	defer cache.Close()
+
+	for i := 0; i < 2; i++ {
+		if indicators, err := cache.Lookup(context.Background(), "benign.example.com"); err != nil || indicators != nil {
+			t.Errorf("IOC negative caching test failed. Expected: no match, Got: %+v, %v", indicators, err)
+		}
+		indicators, err := cache.Lookup(context.Background(), "D41D8CD98F00B204E9800998ECF8427E")
+		if err != nil || len(indicators) != 1 || indicators[0].Feed != "sandbox" {
+			t.Errorf("IOC negative caching test failed. Expected: upstream match, Got: %+v, %v", indicators, err)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("IOC negative caching test failed. Expected: 2 upstream calls, Got: %v", calls)
+	}
+	if ttl, _ := cache.Cache.TTL("benign.example.com"); ttl > time.Minute {
+		t.Errorf("IOC negative caching test failed. Expected: negative TTL, Got: %v", ttl)
+	}
+	if stats := cache.Stats(); stats.NegativeHits != 1 || stats.UpstreamLookups != 2 || stats.Hits != 2 {
+		t.Errorf("IOC negative caching test failed. Got: %+v", stats)
+	}
+}
+
+func TestIndicatorType(t *testing.T) {
+	tests := map[string]string{
+		"192.0.2.1":             "ipv4",
+		"2001:db8::1":           "ipv6",
+		"192.0.2.0/24":          "cidr",
+		strings.Repeat("a", 32): "md5",
+		strings.Repeat("b", 40): "sha1",
+		strings.Repeat("c", 64): "sha256",
+		"http://evil.example/x": "url",
+		"phish@example.com":     "email",
+		"example.com":           "domain",
+	}
+	for value, expected := range tests {
+		if got := IndicatorType(value); got != expected {
+			t.Errorf("Indicator type test failed for %v. Expected: %v, Got: %v", value, expected, got)
+		}
+	}
+}
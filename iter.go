@@ -0,0 +1,121 @@
+//go:build go1.23
+
+package bicache
+
+import (
+	"iter"
+	"sort"
+	"strings"
+	"time"
+)
+
+// All returns an iterator over the keys and decoded values of the non-expired entries.
+// Entries are read one at a time without holding the lock while the loop body runs,
+// so the body may use the cache. Entries removed during the iteration are skipped.
+// Iterating doesn't count as access for metrics or eviction.
+func (c *BiCache) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		for _, key := range c.keySnapshot() {
+			value, found := c.iterValue(key)
+			if found && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the keys of the non-expired entries.
+func (c *BiCache) KeysSeq() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, key := range c.keySnapshot() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// TagsSeq returns an iterator over the distinct tags of the non-expired entries in sorted order.
+func (c *BiCache) TagsSeq() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		c.mu.RLock()
+		now := time.Now()
+		seen := make(map[string]struct{})
+		for key, entry := range c.cacheMap {
+			if c.live(c.externalKey(key), entry, now) {
+				for _, tag := range entry.Tags {
+					seen[tag] = struct{}{}
+				}
+			}
+		}
+		c.mu.RUnlock()
+
+		for _, tag := range sortedSet(seen) {
+			if !yield(tag) {
+				return
+			}
+		}
+	}
+}
+
+// NamespacesSeq returns an iterator over the distinct namespaces of the non-expired string keys in
+// sorted order. The namespace of a key is its prefix up to and including the first separator;
+// keys without the separator have none. The results can be passed to FilterNamespaces.
+func (c *BiCache) NamespacesSeq(separator string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		seen := make(map[string]struct{})
+		for _, key := range c.keySnapshot() {
+			if s, ok := key.(string); ok {
+				if i := strings.Index(s, separator); separator != "" && i >= 0 {
+					seen[s[:i+len(separator)]] = struct{}{}
+				}
+			}
+		}
+
+		for _, namespace := range sortedSet(seen) {
+			if !yield(namespace) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the keys and values of the non-expired entries of type K and V.
+func (c *TypedBiCache[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, value := range c.cache.All() {
+			typedKey, ok := key.(K)
+			if !ok {
+				continue
+			}
+			if typed, ok := typedValue[V](value, true); ok && !yield(typedKey, typed) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the keys of type K of the non-expired entries.
+func (c *TypedBiCache[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range c.cache.KeysSeq() {
+			if typedKey, ok := key.(K); ok && !yield(typedKey) {
+				return
+			}
+		}
+	}
+}
+
+// iterValue returns the decoded value of a non-expired entry without recording an access.
+func (c *BiCache) iterValue(key interface{}) (interface{}, bool) {
+	return c.peek(key, "iterate")
+}
+
+func sortedSet(set map[string]struct{}) []string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
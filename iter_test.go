@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package bicache
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBiCache_All(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetWithOptions("ref:a", 1, 0, WithTags("static"))
+	cache.SetWithOptions("ref:b", 2, 0, WithTags("static", "hot"))
+	cache.Set("user:1", 3, 0)
+	cache.Set("expired", 4, -time.Second)
+
+	all := maps.Collect(cache.All())
+	if len(all) != 3 || all["ref:a"] != 1 || all["ref:b"] != 2 || all["user:1"] != 3 {
+		t.Errorf("All test failed. Got: %v", all)
+	}
+
+	if keys := slices.Collect(cache.KeysSeq()); len(keys) != 3 {
+		t.Errorf("KeysSeq test failed. Expected: 3 keys, Got: %v", keys)
+	}
+	if tags := slices.Collect(cache.TagsSeq()); !slices.Equal(tags, []string{"hot", "static"}) {
+		t.Errorf("TagsSeq test failed. Expected: [hot static], Got: %v", tags)
+	}
+	if namespaces := slices.Collect(cache.NamespacesSeq(":")); !slices.Equal(namespaces, []string{"ref:", "user:"}) {
+		t.Errorf("NamespacesSeq test failed. Expected: [ref: user:], Got: %v", namespaces)
+	}
+
+	// Iterating doesn't count as hits
+	if metrics := cache.GetMetrics(); metrics.Hits != 0 {
+		t.Errorf("All test failed. Expected: 0 hits, Got: %v", metrics.Hits)
+	}
+
+	// The loop body may modify the cache
+	for key := range cache.All() {
+		cache.Delete(key)
+	}
+	if keys := slices.Collect(cache.KeysSeq()); len(keys) != 0 {
+		t.Errorf("All test (delete) failed. Expected: no keys, Got: %v", keys)
+	}
+}
+
+func TestTypedBiCache_All(t *testing.T) {
+	cache := NewTypedBiCache[string, int](10, time.Minute)
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+	cache.Untyped().Set(3, "other", 0)
+
+	all := maps.Collect(cache.All())
+	if len(all) != 2 || all["a"] != 1 || all["b"] != 2 {
+		t.Errorf("TypedAll test failed. Got: %v", all)
+	}
+	keys := slices.Sorted(cache.KeysSeq())
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("TypedKeysSeq test failed. Expected: [a b], Got: %v", keys)
+	}
+}
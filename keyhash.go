@@ -0,0 +1,118 @@
+package bicache
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/maphash"
+)
+
+// ErrNotEmpty is returned by settings that can only be changed while the cache holds no entries.
+var ErrNotEmpty = errors.New("bicache: cache is not empty")
+
+// HashedKey is the 128-bit hash a long string key is stored under when key hashing is enabled.
+// The hash is keyed with random seeds chosen by SetKeyHashing, so keys can't be crafted to
+// collide, and the same key hashes differently in every cache. Event handlers, Keys and
+// snapshots see it in place of the original key unless the original keys are kept.
+type HashedKey [16]byte
+
+// String returns the hash in hexadecimal.
+func (k HashedKey) String() string {
+	return hex.EncodeToString(k[:])
+}
+
+// keyHashing holds the key hashing settings. seeds key the two halves of the hash. keys maps
+// hashes back to the original keys if they are kept; it is guarded by the cache lock.
+type keyHashing struct {
+	minLength int
+	seeds     [2]maphash.Seed
+	keys      map[HashedKey]string
+}
+
+// SetKeyHashing stores string keys of at least minLength bytes under their 128-bit hash instead
+// of the key itself, reducing the memory used by long keys. Lookups, writes and deletes take the
+// original keys as before. If keepKeys is true, the original keys are kept in a reverse map so
+// that events, Keys, iterators and snapshots report them; otherwise they report HashedKey values,
+// and namespace invalidation only hides hashed keys from lookups instead of freeing them on cleanup.
+// A minLength of 0 or less disables key hashing. It returns ErrNotEmpty if the cache holds entries.
+func (c *BiCache) SetKeyHashing(minLength int, keepKeys bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.cacheMap) > 0 {
+		return ErrNotEmpty
+	}
+
+	if minLength <= 0 {
+		c.keyHashing.Store(nil)
+		return nil
+	}
+
+	hashing := &keyHashing{minLength: minLength, seeds: [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()}}
+	if keepKeys {
+		hashing.keys = make(map[HashedKey]string)
+	}
+	c.keyHashing.Store(hashing)
+	return nil
+}
+
+// mapKey returns the key an entry for key is stored under. It returns keys that are already
+// hashed, and all keys while key hashing is disabled, unchanged.
+func (c *BiCache) mapKey(key interface{}) interface{} {
+	hashing := c.keyHashing.Load()
+	if hashing == nil {
+		return key
+	}
+	s, ok := key.(string)
+	if !ok || len(s) < hashing.minLength {
+		return key
+	}
+	return hashing.hash(s)
+}
+
+// externalKey returns the original key of a stored key, if it is known. The caller must hold the lock.
+func (c *BiCache) externalKey(stored interface{}) interface{} {
+	hashed, ok := stored.(HashedKey)
+	if !ok {
+		return stored
+	}
+	if hashing := c.keyHashing.Load(); hashing != nil && hashing.keys != nil {
+		if key, exists := hashing.keys[hashed]; exists {
+			return key
+		}
+	}
+	return stored
+}
+
+// keepKey remembers the original key of a stored key if original keys are kept.
+// The caller must hold the write lock.
+func (c *BiCache) keepKey(key, stored interface{}) {
+	hashed, ok := stored.(HashedKey)
+	if !ok {
+		return
+	}
+	if hashing := c.keyHashing.Load(); hashing != nil && hashing.keys != nil {
+		if s, ok := key.(string); ok {
+			hashing.keys[hashed] = s
+		}
+	}
+}
+
+// forgetKey drops the original key of a stored key. The caller must hold the write lock.
+func (c *BiCache) forgetKey(stored interface{}) {
+	hashed, ok := stored.(HashedKey)
+	if !ok {
+		return
+	}
+	if hashing := c.keyHashing.Load(); hashing != nil && hashing.keys != nil {
+		delete(hashing.keys, hashed)
+	}
+}
+
+func (h *keyHashing) hash(key string) HashedKey {
+	var hashed HashedKey
+	for i, seed := range h.seeds {
+		binary.LittleEndian.PutUint64(hashed[i*8:], maphash.String(seed, key))
+	}
+	return hashed
+}
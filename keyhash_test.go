@@ -0,0 +1,82 @@
+package bicache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBiCache_KeyHashing(t *testing.T) {
+	longKey := "user:" + strings.Repeat("x", 100)
+
+	cache := NewBiCache(2, time.Minute)
+	if err := cache.SetKeyHashing(32, false); err != nil {
+		t.Fatalf("SetKeyHashing test failed. Unexpected error: %v", err)
+	}
+	cache.Set(longKey, "value1", 0)
+	cache.Set("short", "value2", 0)
+
+	if value, found := cache.Get(longKey); !found || value != "value1" {
+		t.Errorf("SetKeyHashing test failed. Expected: value1, Got: %v", value)
+	}
+	if !cache.Has(longKey) {
+		t.Errorf("SetKeyHashing test failed. Expected: long key to be found")
+	}
+	for _, key := range cache.Keys() {
+		if key == longKey {
+			t.Errorf("SetKeyHashing test failed. Expected: hashed key instead of %v", key)
+		}
+	}
+	if err := cache.SetKeyHashing(0, false); err != ErrNotEmpty {
+		t.Errorf("SetKeyHashing test failed. Expected: ErrNotEmpty, Got: %v", err)
+	}
+
+	cache.Delete(longKey)
+	if cache.Has(longKey) || cache.Len() != 1 {
+		t.Errorf("SetKeyHashing test failed. Expected: long key deleted")
+	}
+}
+
+func TestBiCache_KeyHashingKeepKeys(t *testing.T) {
+	longKey := strings.Repeat("k", 64)
+
+	cache := NewBiCache(1, time.Minute)
+	cache.SetKeyHashing(32, true)
+
+	evicted := make(chan interface{}, 1)
+	cache.AddCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
+		if event == CacheEventEvict {
+			evicted <- key
+		}
+	})
+
+	cache.Set(longKey, "value1", 0)
+	if keys := cache.Keys(); len(keys) != 1 || keys[0] != longKey {
+		t.Errorf("SetKeyHashing test failed. Expected: [%s], Got: %v", longKey, keys)
+	}
+
+	cache.Set("short", "value2", 0)
+	select {
+	case key := <-evicted:
+		if key != longKey {
+			t.Errorf("SetKeyHashing test failed. Expected: eviction of %s, Got: %v", longKey, key)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("SetKeyHashing test failed. Expected: an eviction event")
+	}
+}
+
+func TestBiCache_KeyHashingSeeded(t *testing.T) {
+	longKey := strings.Repeat("k", 64)
+
+	// The hash is keyed per cache, so colliding keys can't be computed ahead of time
+	first, second := NewBiCache(10, time.Minute), NewBiCache(10, time.Minute)
+	first.SetKeyHashing(32, false)
+	second.SetKeyHashing(32, false)
+	if first.mapKey(longKey) == second.mapKey(longKey) {
+		t.Errorf("SetKeyHashing seeded test failed. Expected: different hashes in different caches")
+	}
+	if first.mapKey(longKey) != first.mapKey(longKey) {
+		t.Errorf("SetKeyHashing seeded test failed. Expected: a stable hash within a cache")
+	}
+}
@@ -0,0 +1,129 @@
+package bicache
+
+import "time"
+
+// Len returns the number of non-expired entries. Expired entries that were not cleaned up
+// yet are not counted, unlike in the EntriesCount metric.
+func (c *BiCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	n := 0
+	for key, entry := range c.cacheMap {
+		if c.live(c.externalKey(key), entry, now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Has reports whether a non-expired entry is stored under key. Unlike Get, it doesn't
+// count as a hit or miss, record an access or call a loader.
+func (c *BiCache) Has(key interface{}) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	return exists && c.live(key, entry, time.Now())
+}
+
+// Keys returns the keys of the non-expired entries in no particular order.
+func (c *BiCache) Keys() []interface{} {
+	return c.keySnapshot()
+}
+
+// Peek returns the value stored under key like Get, but doesn't update its access time or
+// recency, count a hit or miss, or call a loader.
+func (c *BiCache) Peek(key interface{}) (interface{}, bool) {
+	return c.peek(key, "get")
+}
+
+// peek returns the decoded value of a non-expired entry without recording an access.
+// Decoding errors are reported for op.
+func (c *BiCache) peek(key interface{}, op string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, time.Now()) {
+		return nil, false
+	}
+
+	value, err := c.decodeStored(entry.Value)
+	if err != nil {
+		c.reportError(op, key, err)
+		return nil, false
+	}
+	return value, true
+}
+
+// keySnapshot returns the keys of the non-expired entries.
+func (c *BiCache) keySnapshot() []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]interface{}, 0, len(c.cacheMap))
+	for stored, entry := range c.cacheMap {
+		if key := c.externalKey(stored); c.live(key, entry, now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Peek returns the value stored under key without recording an access.
+func (c *ShardedBiCache) Peek(key interface{}) (interface{}, bool) {
+	return c.Shard(key).Peek(key)
+}
+
+// Len returns the number of non-expired entries across all shards.
+func (c *ShardedBiCache) Len() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Has reports whether a non-expired entry is stored under key.
+func (c *ShardedBiCache) Has(key interface{}) bool {
+	return c.Shard(key).Has(key)
+}
+
+// Keys returns the keys of the non-expired entries across all shards.
+func (c *ShardedBiCache) Keys() []interface{} {
+	var keys []interface{}
+	for _, shard := range c.shards {
+		keys = append(keys, shard.Keys()...)
+	}
+	return keys
+}
+
+// Peek is the typed variant of BiCache.Peek.
+func (c *TypedBiCache[K, V]) Peek(key K) (V, bool) {
+	value, found := c.cache.Peek(key)
+	return typedValue[V](value, found)
+}
+
+// Len returns the number of non-expired entries.
+func (c *TypedBiCache[K, V]) Len() int {
+	return c.cache.Len()
+}
+
+// Has reports whether a non-expired entry is stored under key.
+func (c *TypedBiCache[K, V]) Has(key K) bool {
+	return c.cache.Has(key)
+}
+
+// Keys returns the keys of type K of the non-expired entries.
+func (c *TypedBiCache[K, V]) Keys() []K {
+	var keys []K
+	for _, key := range c.cache.Keys() {
+		if typedKey, ok := key.(K); ok {
+			keys = append(keys, typedKey)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,49 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_LenHasKeys(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("expired", "value3", -time.Second)
+
+	if n := cache.Len(); n != 2 {
+		t.Errorf("Len test failed. Expected: 2, Got: %d", n)
+	}
+	if !cache.Has("key1") || cache.Has("expired") || cache.Has("missing") {
+		t.Errorf("Has test failed. Expected: only key1 to be found")
+	}
+	if keys := cache.Keys(); len(keys) != 2 {
+		t.Errorf("Keys test failed. Expected: 2 keys, Got: %v", keys)
+	}
+
+	if metrics := cache.GetMetrics(); metrics.Hits != 0 || metrics.Misses != 0 {
+		t.Errorf("Has test failed. Expected: no hits or misses, Got: %d hits, %d misses", metrics.Hits, metrics.Misses)
+	}
+}
+
+func TestBiCache_Peek(t *testing.T) {
+	cache := NewBiCache(2, time.Minute)
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+
+	if value, found := cache.Peek("key1"); !found || value != "value1" {
+		t.Errorf("Peek test failed. Expected: value1, Got: %v", value)
+	}
+	if _, found := cache.Peek("missing"); found {
+		t.Errorf("Peek test failed. Expected: missing key not found")
+	}
+
+	// Peeking doesn't make key1 recently used, so it is evicted first
+	cache.Set("key3", "value3", 0)
+	if cache.Has("key1") || !cache.Has("key2") {
+		t.Errorf("Peek test failed. Expected: key1 evicted and key2 kept")
+	}
+	if metrics := cache.GetMetrics(); metrics.Hits != 0 || metrics.Misses != 0 {
+		t.Errorf("Peek test failed. Expected: no hits or misses, Got: %d hits, %d misses", metrics.Hits, metrics.Misses)
+	}
+}
@@ -0,0 +1,123 @@
+package bicache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLeaseHeld is returned by AcquireLease when another holder's lease on the key has not expired.
+var ErrLeaseHeld = errors.New("bicache: lease is held")
+
+// Lease grants its holder the right to write a key until it expires. Token is a fencing token:
+// tokens increase with every lease issued by the cache, so writes carrying the token of a lease
+// that was superseded can be told apart from writes of the current holder.
+type Lease struct {
+	Key        interface{}
+	Token      uint64
+	Expiration time.Time
+}
+
+// FencingError is returned by writes whose fencing token is older than the newest lease issued
+// for the key or than the token the entry was written with.
+type FencingError struct {
+	Key     interface{}
+	Token   uint64
+	Current uint64
+}
+
+func (e *FencingError) Error() string {
+	return fmt.Sprintf("bicache: fencing token %d of entry %v is older than %d", e.Token, e.Key, e.Current)
+}
+
+// leaseRecord is the newest lease issued for a key.
+type leaseRecord struct {
+	token      uint64
+	expiration time.Time
+}
+
+// WithFencingToken makes the write carry the fencing token of a lease. The write fails with
+// a *FencingError if a newer lease was issued for the key since, or if the entry was written
+// with a newer token, so a holder whose lease expired during a pause can't overwrite newer data.
+// Writes without a token are not checked.
+func WithFencingToken(token uint64) SetOption {
+	return func(o *setOptions) {
+		o.fencingToken = token
+	}
+}
+
+// AcquireLease grants a lease on key for ttl with a new fencing token. It fails with ErrLeaseHeld
+// while an earlier lease on key has not expired or been released.
+func (c *BiCache) AcquireLease(key interface{}, ttl time.Duration) (Lease, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return Lease{}, ErrClosed
+	}
+
+	now := time.Now()
+	if record, exists := c.leases[key]; exists && now.Before(record.expiration) {
+		return Lease{}, ErrLeaseHeld
+	}
+
+	c.fencingTokens++
+	record := leaseRecord{token: c.fencingTokens, expiration: now.Add(ttl)}
+	if c.leases == nil {
+		c.leases = make(map[interface{}]leaseRecord)
+	}
+	c.leases[key] = record
+	return Lease{Key: key, Token: record.token, Expiration: record.expiration}, nil
+}
+
+// ReleaseLease ends lease early so the key can be leased again. Its token stays fenced off.
+// It reports whether lease was still the current lease of its key.
+func (c *BiCache) ReleaseLease(lease Lease) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record, exists := c.leases[lease.Key]
+	if !exists || record.token != lease.Token || !time.Now().Before(record.expiration) {
+		return false
+	}
+	record.expiration = time.Time{}
+	c.leases[lease.Key] = record
+	return true
+}
+
+// checkFencingToken returns a *FencingError if token is older than the newest lease on key
+// or than the token the entry stored under key was written with. The caller must hold the lock.
+func (c *BiCache) checkFencingToken(key interface{}, token uint64) error {
+	if token == 0 {
+		return nil
+	}
+
+	current := c.leases[key].token
+	if entry, exists := c.cacheMap[c.mapKey(key)]; exists && entry.fencingToken > current {
+		current = entry.fencingToken
+	}
+	if token < current {
+		return &FencingError{Key: key, Token: token, Current: current}
+	}
+	return nil
+}
+
+// cleanupLeases forgets the leases that expired. Entries written by their holders keep
+// their tokens, so stale holders still can't overwrite them.
+func (c *BiCache) cleanupLeases(now time.Time) {
+	for key, record := range c.leases {
+		if !now.Before(record.expiration) {
+			delete(c.leases, key)
+		}
+	}
+}
+
+// AcquireLease grants a lease on key from the shard of key.
+func (c *ShardedBiCache) AcquireLease(key interface{}, ttl time.Duration) (Lease, error) {
+	return c.Shard(key).AcquireLease(key, ttl)
+}
+
+// ReleaseLease ends lease early.
+func (c *ShardedBiCache) ReleaseLease(lease Lease) bool {
+	return c.Shard(lease.Key).ReleaseLease(lease)
+}
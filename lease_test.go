@@ -0,0 +1,72 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Lease(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	first, err := cache.AcquireLease("key1", time.Millisecond*20)
+	if err != nil || first.Token == 0 {
+		t.Fatalf("Lease test failed. Expected: a lease, Got: %+v, %v", first, err)
+	}
+	if _, err := cache.AcquireLease("key1", time.Minute); err != ErrLeaseHeld {
+		t.Errorf("Lease test failed. Expected: %v, Got: %v", ErrLeaseHeld, err)
+	}
+
+	// The first holder pauses past its lease and a second holder takes over
+	time.Sleep(time.Millisecond * 30)
+	second, err := cache.AcquireLease("key1", time.Minute)
+	if err != nil || second.Token <= first.Token {
+		t.Fatalf("Lease test failed. Expected: a newer token, Got: %+v, %v", second, err)
+	}
+
+	err = cache.SetWithOptions("key1", "stale", 0, WithFencingToken(first.Token))
+	var fencingErr *FencingError
+	if !errors.As(err, &fencingErr) || fencingErr.Current != second.Token {
+		t.Errorf("Lease test failed. Expected: a FencingError, Got: %v", err)
+	}
+	if err := cache.SetWithOptions("key1", "new", 0, WithFencingToken(second.Token)); err != nil {
+		t.Errorf("Lease test failed. Expected: write of the current holder, Got: %v", err)
+	}
+
+	// Releasing the lease keeps the written data fenced off from older tokens
+	if !cache.ReleaseLease(second) || cache.ReleaseLease(second) {
+		t.Errorf("Lease test failed. Expected: the lease released once")
+	}
+	cache.RunCleanup()
+	if err := cache.SetWithOptions("key1", "stale", 0, WithFencingToken(first.Token)); err == nil {
+		t.Errorf("Lease test failed. Expected: stale token rejected after release")
+	}
+	if value, _ := cache.Get("key1"); value != "new" {
+		t.Errorf("Lease test failed. Expected: new, Got: %v", value)
+	}
+
+	// Writes without a token are not checked
+	if err := cache.SetWithOptions("key1", "unfenced", 0); err != nil {
+		t.Errorf("Lease test failed. Expected: unfenced write, Got: %v", err)
+	}
+	if err := cache.SetWithOptions("key1", "stale", 0, WithFencingToken(first.Token)); err == nil {
+		t.Errorf("Lease test failed. Expected: token kept by unfenced writes")
+	}
+}
+
+func TestBiCache_FencingWriteThrough(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	written := 0
+	cache.SetWriter(func(key, value interface{}) error {
+		written++
+		return nil
+	})
+
+	stale, _ := cache.AcquireLease("key1", 0)
+	current, _ := cache.AcquireLease("key1", time.Minute)
+	cache.SetWithOptions("key1", "stale", 0, WithFencingToken(stale.Token))
+	cache.SetWithOptions("key1", "current", 0, WithFencingToken(current.Token))
+	if written != 1 {
+		t.Errorf("Fencing write-through test failed. Expected: only the current holder written, Got: %v writes", written)
+	}
+}
@@ -0,0 +1,214 @@
+package bicache
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// LoaderFunc loads the value for a missing key together with its expiration.
+type LoaderFunc func(key interface{}) (interface{}, time.Duration, error)
+
+// LoaderResult is a loaded value with the freshness and tags decided by the data source.
+type LoaderResult struct {
+	Value interface{}
+	TTL   time.Duration
+	Tags  []string
+	Token string
+}
+
+// ResultLoaderFunc loads the value for a missing key as a LoaderResult.
+type ResultLoaderFunc func(key interface{}) (LoaderResult, error)
+
+// ContextLoaderFunc is like ResultLoaderFunc and also receives the context of the Get that missed,
+// or of the Prefetch. Loads that continue in the background get a context that keeps
+// the values but is never canceled.
+type ContextLoaderFunc func(ctx context.Context, key interface{}) (LoaderResult, error)
+
+// loadFailure remembers a failed load so the loader is not called again until retryAt.
+type loadFailure struct {
+	retryAt time.Time
+	backoff time.Duration
+}
+
+// RegisterLoader makes Get read through to loader on misses for string keys starting with prefix.
+// The longest matching prefix wins, and the empty prefix also serves non-string keys.
+func (c *BiCache) RegisterLoader(prefix string, loader LoaderFunc) {
+	if loader == nil {
+		c.RegisterResultLoader(prefix, nil)
+		return
+	}
+
+	c.RegisterResultLoader(prefix, func(key interface{}) (LoaderResult, error) {
+		value, ttl, err := loader(key)
+		return LoaderResult{Value: value, TTL: ttl}, err
+	})
+}
+
+// SetLoader makes Get read through to loader on misses of any key, turning the cache into a
+// read-through cache. It registers loader for the empty prefix, so loaders registered for
+// longer prefixes still take precedence. A nil loader removes it.
+func (c *BiCache) SetLoader(loader LoaderFunc) {
+	c.RegisterLoader("", loader)
+}
+
+// RegisterResultLoader is like RegisterLoader for loaders that also decide the TTL and tags of the value.
+func (c *BiCache) RegisterResultLoader(prefix string, loader ResultLoaderFunc) {
+	if loader == nil {
+		c.RegisterContextLoader(prefix, nil)
+		return
+	}
+
+	c.RegisterContextLoader(prefix, func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		return loader(key)
+	})
+}
+
+// RegisterContextLoader is like RegisterResultLoader for loaders that use the caller's context.
+func (c *BiCache) RegisterContextLoader(prefix string, loader ContextLoaderFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if loader == nil {
+		delete(c.loaders, prefix)
+		return
+	}
+	c.loaders[prefix] = loader
+}
+
+// loaderFor returns the loader registered for key, if any.
+func (c *BiCache) loaderFor(key interface{}) ContextLoaderFunc {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.registeredLoader(key)
+}
+
+// registeredLoader is like loaderFor. The caller must hold the lock.
+func (c *BiCache) registeredLoader(key interface{}) ContextLoaderFunc {
+	var loader ContextLoaderFunc
+	if stringKey, ok := key.(string); ok {
+		longest := -1
+		for prefix, l := range c.loaders {
+			if len(prefix) > longest && strings.HasPrefix(stringKey, prefix) {
+				loader, longest = l, len(prefix)
+			}
+		}
+	} else {
+		loader = c.loaders[""]
+	}
+
+	// Keys without a registered loader fall back to the batch loader
+	if loader == nil && c.batcher != nil {
+		batcher := c.batcher
+		loader = func(ctx context.Context, key interface{}) (LoaderResult, error) {
+			return batcher.load(key)
+		}
+	}
+	return loader
+}
+
+// SetLoadFailureBackoff caches failed loads per key for initial, doubling the duration
+// on each consecutive failure up to max. A zero initial duration disables it.
+func (c *BiCache) SetLoadFailureBackoff(initial, max time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failureBackoff = initial
+	c.maxFailureBackoff = max
+	if initial <= 0 {
+		c.loadFailures = make(map[interface{}]loadFailure)
+	}
+}
+
+// load resolves a miss with loader and stores the result. Within a load scope,
+// each key is loaded only once.
+func (c *BiCache) load(ctx context.Context, key interface{}, loader ContextLoaderFunc) (CacheEntry, error) {
+	if scope := loadScopeFrom(ctx); scope != nil {
+		return scope.do(ctx, c, key, func() (CacheEntry, error) {
+			return c.loadAndStore(ctx, key, loader)
+		})
+	}
+	return c.loadAndStore(ctx, key, loader)
+}
+
+// loadAndStore calls loader for key and stores the result.
+func (c *BiCache) loadAndStore(ctx context.Context, key interface{}, loader ContextLoaderFunc) (CacheEntry, error) {
+	if err := c.negativeResult(key); err != nil {
+		return CacheEntry{}, err
+	}
+	if c.loadSuppressed(key) {
+		return CacheEntry{}, ErrLoadSuppressed
+	}
+
+	start := time.Now()
+	result, err := loader(ctx, key)
+	latency := time.Since(start)
+	if err != nil {
+		c.metrics.LoadError.Add(1)
+		c.mu.Lock()
+		c.recordLoadFailure(key)
+		c.recordNegative(key, err)
+		c.mu.Unlock()
+		return CacheEntry{}, err
+	}
+
+	c.mu.Lock()
+	delete(c.loadFailures, key)
+	c.mu.Unlock()
+
+	// Storing the value resolves the recorded miss and measures the miss penalty
+	if err := c.set(ctx, key, result.Value, result.TTL, WithTags(result.Tags...), WithToken(result.Token)); err != nil {
+		return CacheEntry{}, err
+	}
+
+	c.metrics.LoadSuccess.Add(1)
+	c.metrics.LoadLatency.Add(int64(latency))
+
+	now := time.Now()
+	entry := CacheEntry{Value: result.Value, Accessed: now, Tags: copyTags(result.Tags), Token: result.Token, created: now}
+	if result.TTL > 0 {
+		entry.Expiration = entry.Accessed.Add(result.TTL)
+	}
+	return entry, nil
+}
+
+// loadSuppressed reports whether a recent load failure of key is still cached.
+func (c *BiCache) loadSuppressed(key interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failure, exists := c.loadFailures[key]
+	if !exists || !time.Now().Before(failure.retryAt) {
+		return false
+	}
+
+	c.metrics.LoadSuppressed.Add(1)
+	return true
+}
+
+// recordLoadFailure caches a failed load of key with an exponentially increasing backoff.
+func (c *BiCache) recordLoadFailure(key interface{}) {
+	if c.failureBackoff <= 0 {
+		return
+	}
+
+	backoff := c.failureBackoff
+	if failure, exists := c.loadFailures[key]; exists {
+		backoff = failure.backoff * 2
+	}
+	if c.maxFailureBackoff > 0 && backoff > c.maxFailureBackoff {
+		backoff = c.maxFailureBackoff
+	}
+
+	c.loadFailures[key] = loadFailure{retryAt: time.Now().Add(backoff), backoff: backoff}
+}
+
+// cleanupLoadFailures forgets failures whose backoff has passed long enough ago to start over.
+func (c *BiCache) cleanupLoadFailures(now time.Time) {
+	for key, failure := range c.loadFailures {
+		if now.Sub(failure.retryAt) > failure.backoff {
+			delete(c.loadFailures, key)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package bicache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBiCache_RegisterLoader(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+
+	var userLoads int
+	cache.RegisterLoader("user:", func(key interface{}) (interface{}, time.Duration, error) {
+		userLoads++
+		return "loaded_" + key.(string), time.Second * 20, nil
+	})
+	cache.RegisterLoader("user:admin:", func(key interface{}) (interface{}, time.Duration, error) {
+		return "admin", time.Second * 20, nil
+	})
+	cache.RegisterLoader("order:", func(key interface{}) (interface{}, time.Duration, error) {
+		return nil, 0, errors.New("backend unavailable")
+	})
+
+	// A miss is read through the loader of the matching prefix
+	result, found := cache.Get("user:1")
+	if !found || result.(string) != "loaded_user:1" {
+		t.Errorf("RegisterLoader test failed. Expected: 'loaded_user:1', Got: '%v'", result)
+	}
+
+	// The loaded value is cached
+	cache.Get("user:1")
+	if userLoads != 1 {
+		t.Errorf("RegisterLoader test failed. Expected: 1 load, Got: %v", userLoads)
+	}
+
+	// The longest prefix wins
+	if result, _ := cache.Get("user:admin:1"); result != "admin" {
+		t.Errorf("RegisterLoader test (longest prefix) failed. Expected: 'admin', Got: '%v'", result)
+	}
+
+	// Loader errors are reported as misses
+	if result, found := cache.Get("order:1"); found {
+		t.Errorf("RegisterLoader test (error) failed. Expected: not found, Got: '%v'", result)
+	}
+
+	// Keys without a matching loader are plain misses
+	if result, found := cache.Get("product:1"); found {
+		t.Errorf("RegisterLoader test (no loader) failed. Expected: not found, Got: '%v'", result)
+	}
+
+	metrics := cache.GetMetrics()
+	if metrics.LoadSuccess != 2 || metrics.LoadError != 1 || metrics.ResolvedMisses != 2 {
+		t.Errorf("RegisterLoader test failed. Expected: LoadSuccess=2, LoadError=1, ResolvedMisses=2. Got: LoadSuccess=%v, LoadError=%v, ResolvedMisses=%v",
+			metrics.LoadSuccess, metrics.LoadError, metrics.ResolvedMisses)
+	}
+}
+
+func TestBiCache_RegisterResultLoader(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+	cache.RegisterResultLoader("page:", func(key interface{}) (LoaderResult, error) {
+		return LoaderResult{Value: "content", TTL: time.Minute, Tags: []string{"pages"}}, nil
+	})
+
+	// The TTL and tags come from the loader
+	entry, found := cache.GetEntry("page:home")
+	if !found || entry.Value != "content" {
+		t.Fatalf("RegisterResultLoader test failed. Expected: 'content', Got: '%v'", entry.Value)
+	}
+
+	info, found := cache.GetEntryInfo("page:home")
+	if !found || len(info.Tags) != 1 || info.Tags[0] != "pages" {
+		t.Errorf("RegisterResultLoader test (tags) failed. Expected: [pages], Got: '%v'", info.Tags)
+	}
+	if ttl := time.Until(info.Expiration); ttl < time.Second*50 || ttl > time.Minute {
+		t.Errorf("RegisterResultLoader test (TTL) failed. Expected: ~1m, Got: %v", ttl)
+	}
+}
+
+func TestBiCache_LoadFailureBackoff(t *testing.T) {
+	cache := NewBiCache(5, time.Minute)
+	cache.SetLoadFailureBackoff(time.Millisecond*100, time.Second)
+
+	var loads int
+	failing := true
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		loads++
+		if failing {
+			return nil, 0, errors.New("record not found")
+		}
+		return "value", time.Minute, nil
+	})
+
+	// The failure is cached, so the loader is called only once
+	cache.Get("key1")
+	cache.Get("key1")
+	if loads != 1 {
+		t.Errorf("LoadFailureBackoff test failed. Expected: 1 load, Got: %v", loads)
+	}
+
+	// After the backoff the loader is called again
+	time.Sleep(time.Millisecond * 150)
+	failing = false
+	if result, found := cache.Get("key1"); !found || result != "value" || loads != 2 {
+		t.Errorf("LoadFailureBackoff test (retry) failed. Expected: 'value' after 2 loads, Got: '%v' after %v loads", result, loads)
+	}
+
+	if metrics := cache.GetMetrics(); metrics.LoadSuppressed != 1 {
+		t.Errorf("LoadFailureBackoff test failed. Expected: LoadSuppressed=1, Got: %v", metrics.LoadSuppressed)
+	}
+}
+
+func TestBiCache_SetLoader(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetLoader(func(key interface{}) (interface{}, time.Duration, error) {
+		return fmt.Sprint("value-", key), time.Minute, nil
+	})
+
+	if value, found := cache.Get("key1"); !found || value != "value-key1" {
+		t.Errorf("SetLoader test failed. Expected: value-key1, Got: %v", value)
+	}
+	if value, found := cache.Get(42); !found || value != "value-42" {
+		t.Errorf("SetLoader test failed. Expected: value-42, Got: %v", value)
+	}
+
+	cache.SetLoader(nil)
+	if _, found := cache.Get("key2"); found {
+		t.Errorf("SetLoader test failed. Expected: miss after removing the loader")
+	}
+}
@@ -0,0 +1,71 @@
+package bicache
+
+import (
+	"context"
+	"sync"
+)
+
+// loadScopeKey is the context key of the load scope.
+type loadScopeKey struct{}
+
+// loadScope remembers the loads made within it, such as all loads of one request.
+type loadScope struct {
+	mu    sync.Mutex
+	loads map[scopedKey]*scopedLoad
+}
+
+// scopedKey identifies a key of a cache within a scope shared by several caches.
+type scopedKey struct {
+	cache *BiCache
+	key   interface{}
+}
+
+// scopedLoad is a load that is running or finished within a scope.
+type scopedLoad struct {
+	done  chan struct{}
+	entry CacheEntry
+	err   error
+}
+
+// WithLoadScope returns a context that deduplicates loads made with it or its children, for
+// example for the duration of one request. A key that misses more than once within the scope,
+// in parallel or one after the other, is loaded only once per cache; later lookups wait for
+// the first load and share its result or error, even if the value was not stored.
+func WithLoadScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadScopeKey{}, &loadScope{loads: make(map[scopedKey]*scopedLoad)})
+}
+
+func loadScopeFrom(ctx context.Context) *loadScope {
+	scope, _ := ctx.Value(loadScopeKey{}).(*loadScope)
+	return scope
+}
+
+// do returns the result of the load of key by cache within the scope, calling load if it is the first.
+func (s *loadScope) do(ctx context.Context, cache *BiCache, key interface{}, load func() (CacheEntry, error)) (CacheEntry, error) {
+	id := scopedKey{cache: cache, key: key}
+
+	s.mu.Lock()
+	if call, exists := s.loads[id]; exists {
+		s.mu.Unlock()
+		cache.metrics.LoadsDeduplicated.Add(1)
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return CacheEntry{}, ctx.Err()
+		}
+		entry := call.entry
+		entry.Tags = copyTags(entry.Tags)
+		return entry, call.err
+	}
+	call := &scopedLoad{done: make(chan struct{})}
+	s.loads[id] = call
+	s.mu.Unlock()
+
+	call.entry, call.err = load()
+	close(call.done)
+
+	entry := call.entry
+	entry.Tags = copyTags(entry.Tags)
+	return entry, call.err
+}
@@ -0,0 +1,36 @@
+package bicache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBiCache_LoadScope(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	loads := 0
+	// Loaded values expire right away, so every Get misses
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		loads++
+		return "loaded", -time.Second, nil
+	})
+
+	ctx := WithLoadScope(context.Background())
+	for i := 0; i < 3; i++ {
+		if value, found := cache.GetContext(ctx, "key1"); !found || value != "loaded" {
+			t.Errorf("Load scope test failed. Expected: loaded, Got: %v", value)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Load scope test failed. Expected: 1 load within the scope, Got: %d", loads)
+	}
+	if deduplicated := cache.GetMetrics().LoadsDeduplicated; deduplicated != 2 {
+		t.Errorf("Load scope test failed. Expected: 2 deduplicated loads, Got: %d", deduplicated)
+	}
+
+	// Another scope loads again
+	cache.GetContext(WithLoadScope(context.Background()), "key1")
+	if loads != 2 {
+		t.Errorf("Load scope test failed. Expected: 2 loads, Got: %d", loads)
+	}
+}
@@ -0,0 +1,76 @@
+package bicache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruList orders keys from most to least recently used. It has its own lock
+// because reads move keys to the front while holding only the cache read lock.
+type lruList struct {
+	mu       sync.Mutex
+	order    *list.List
+	elements map[interface{}]*list.Element
+}
+
+func newLRUList() *lruList {
+	return &lruList{
+		order:    list.New(),
+		elements: make(map[interface{}]*list.Element),
+	}
+}
+
+func (l *lruList) add(key interface{}) {
+	l.touch(key)
+}
+
+// touch moves key to the front, adding it if it is not tracked yet.
+func (l *lruList) touch(key interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, exists := l.elements[key]; exists {
+		l.order.MoveToFront(element)
+		return
+	}
+	l.elements[key] = l.order.PushFront(key)
+}
+
+func (l *lruList) remove(key interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, exists := l.elements[key]; exists {
+		l.order.Remove(element)
+		delete(l.elements, key)
+	}
+}
+
+func (l *lruList) setCapacity(capacity int) {}
+
+func (l *lruList) contains(key interface{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, exists := l.elements[key]
+	return exists
+}
+
+func (l *lruList) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+// victims returns up to n keys, least recently used first.
+func (l *lruList) victims(n int) []interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]interface{}, 0, n)
+	for element := l.order.Back(); element != nil && len(keys) < n; element = element.Prev() {
+		keys = append(keys, element.Value)
+	}
+	return keys
+}
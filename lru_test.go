@@ -0,0 +1,116 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_LRUEviction(t *testing.T) {
+	cache := NewBiCache(3, time.Minute)
+
+	evicted := make(chan interface{}, 10)
+	cache.SetCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
+		if event == CacheEventEvict {
+			evicted <- key
+		}
+	})
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+
+	// Reading key1 makes key2 the least recently used entry
+	cache.Get("key1")
+	cache.Set("key4", "value4", 0)
+
+	if _, found := cache.Get("key2"); found {
+		t.Errorf("LRUEviction test failed. Expected: key2 evicted")
+	}
+	for _, key := range []string{"key1", "key3", "key4"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("LRUEviction test failed. Expected: %s kept", key)
+		}
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 3 {
+		t.Errorf("LRUEviction test failed. Expected: 3 entries, Got: %v", metrics.EntriesCount)
+	}
+
+	select {
+	case key := <-evicted:
+		if key != "key2" {
+			t.Errorf("LRUEviction test failed. Expected: evict event for key2, Got: %v", key)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("LRUEviction test failed. Expected: evict event")
+	}
+}
+
+func TestBiCache_LRUEvictionSetCapacity(t *testing.T) {
+	cache := NewBiCache(3, time.Minute)
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+	cache.Set("key1", "value1", 0)
+
+	cache.SetCapacity(1)
+
+	if _, found := cache.Get("key1"); !found {
+		t.Errorf("LRUEvictionSetCapacity test failed. Expected: key1 kept")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 1 {
+		t.Errorf("LRUEvictionSetCapacity test failed. Expected: 1 entry, Got: %v", metrics.EntriesCount)
+	}
+}
+
+func TestBiCache_SLRUEviction(t *testing.T) {
+	cache := NewBiCache(4, time.Minute)
+	cache.SetEvictionPolicy(SLRUEviction)
+	cache.SetProtectedRatio(0.5)
+
+	// Entries read again are protected
+	cache.Set("hot1", "value", 0)
+	cache.Set("hot2", "value", 0)
+	cache.Get("hot1")
+	cache.Get("hot2")
+
+	// A scan of new keys only replaces entries on probation
+	for i := 0; i < 10; i++ {
+		cache.Set(i, "value", 0)
+	}
+
+	for _, key := range []string{"hot1", "hot2"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("SLRUEviction test failed. Expected: %s kept", key)
+		}
+	}
+	if _, found := cache.Get(9); !found {
+		t.Errorf("SLRUEviction test failed. Expected: last scanned key kept")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 4 {
+		t.Errorf("SLRUEviction test failed. Expected: 4 entries, Got: %v", metrics.EntriesCount)
+	}
+
+	// Under LRU the same scan pushes them out
+	cache = NewBiCache(4, time.Minute)
+	cache.Set("hot1", "value", 0)
+	cache.Get("hot1")
+	for i := 0; i < 10; i++ {
+		cache.Set(i, "value", 0)
+	}
+	if _, found := cache.Get("hot1"); found {
+		t.Errorf("SLRUEviction test (LRU) failed. Expected: hot1 evicted")
+	}
+}
+
+func TestBiCache_SetEvictionPolicyKeepsOrder(t *testing.T) {
+	cache := NewBiCache(3, time.Minute)
+	cache.Set("key1", "value", 0)
+	cache.Set("key2", "value", 0)
+	cache.Set("key3", "value", 0)
+
+	cache.SetEvictionPolicy(SLRUEviction)
+	if victims := cache.WouldEvict(1); len(victims) != 1 || victims[0] != "key1" {
+		t.Errorf("SetEvictionPolicy test failed. Expected: [key1], Got: %v", victims)
+	}
+}
@@ -0,0 +1,287 @@
+package bicache
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentRemovalsSize is the number of removals kept per cache for the status page.
+const recentRemovalsSize = 20
+
+// Removal is a recently removed entry shown on the status page.
+type Removal struct {
+	Key  string
+	Time time.Time
+}
+
+// CacheStatus is a summary of a registered cache.
+type CacheStatus struct {
+	Name             string
+	Capacity         int
+	Entries          int64
+	FillRatio        float64
+	HitRate          float64
+	GlobalExpiration time.Duration
+	CleanupInterval  time.Duration
+	Admission        string
+	EvictionPolicy   string
+	Metrics          CacheMetrics
+	RecentRemovals   []Removal
+}
+
+// Manager keeps a registry of named caches and serves a human-readable status page for them.
+type Manager struct {
+	mu       sync.RWMutex
+	caches   map[string]*BiCache
+	removals map[string]*removalLog
+}
+
+// removalLog is a ring buffer of recent removals.
+type removalLog struct {
+	mu       sync.Mutex
+	removals []Removal
+	next     int
+}
+
+func (l *removalLog) add(removal Removal) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.removals) < recentRemovalsSize {
+		l.removals = append(l.removals, removal)
+		return
+	}
+	l.removals[l.next] = removal
+	l.next = (l.next + 1) % recentRemovalsSize
+}
+
+// recent returns the removals, newest first.
+func (l *removalLog) recent() []Removal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	recent := make([]Removal, 0, len(l.removals))
+	for i := len(l.removals) - 1; i >= 0; i-- {
+		recent = append(recent, l.removals[(l.next+i)%len(l.removals)])
+	}
+	return recent
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		caches:   make(map[string]*BiCache),
+		removals: make(map[string]*removalLog),
+	}
+}
+
+// Register adds a cache to the registry under a unique name.
+func (m *Manager) Register(name string, cache *BiCache) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.caches[name]; exists {
+		return fmt.Errorf("bicache: cache %q is already registered", name)
+	}
+
+	log := &removalLog{}
+	cache.AddCacheEventHandler(func(event CacheEvent, key interface{}, entry CacheEntry) {
+		switch event {
+		case CacheEventSet:
+		case CacheEventClear:
+			log.add(Removal{Key: "*", Time: time.Now()})
+		default:
+			log.add(Removal{Key: fmt.Sprint(key), Time: time.Now()})
+		}
+	})
+
+	m.caches[name] = cache
+	m.removals[name] = log
+	return nil
+}
+
+// Unregister removes a cache from the registry.
+func (m *Manager) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.caches, name)
+	delete(m.removals, name)
+}
+
+// Cache returns the cache registered under name.
+func (m *Manager) Cache(name string) (*BiCache, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cache, exists := m.caches[name]
+	return cache, exists
+}
+
+// Names returns the names of the registered caches in sorted order.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.caches))
+	for name := range m.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Status returns a summary of every registered cache, sorted by name.
+func (m *Manager) Status() []CacheStatus {
+	var statuses []CacheStatus
+	for _, name := range m.Names() {
+		m.mu.RLock()
+		cache, exists := m.caches[name]
+		log := m.removals[name]
+		m.mu.RUnlock()
+
+		if !exists {
+			continue
+		}
+
+		status := cache.status()
+		status.Name = name
+		status.RecentRemovals = log.recent()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// PublishExpvar publishes the status of the registered caches as an expvar variable.
+func (m *Manager) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return m.Status()
+	}))
+}
+
+var statusTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head><title>bicache status</title></head>
+<body>
+<h1>bicache status</h1>
+{{range .}}
+<h2>{{.Name}}</h2>
+<table border="1">
+<tr><th>Entries</th><td>{{.Entries}} / {{.Capacity}} ({{printf "%.1f" .FillPercent}}%)</td></tr>
+<tr><th>Hit rate</th><td>{{printf "%.1f" .HitPercent}}% ({{.Metrics.Hits}} hits, {{.Metrics.Misses}} misses)</td></tr>
+<tr><th>Sets</th><td>{{.Metrics.SetSuccess}} ok, {{.Metrics.SetError}} errors</td></tr>
+<tr><th>Global expiration</th><td>{{.GlobalExpiration}}</td></tr>
+<tr><th>Cleanup interval</th><td>{{.CleanupInterval}}</td></tr>
+<tr><th>Admission</th><td>{{.Admission}}</td></tr>
+<tr><th>Eviction</th><td>{{.EvictionPolicy}}</td></tr>
+</table>
+<h3>Recent removals</h3>
+<table border="1">
+<tr><th>Key</th><th>Time</th></tr>
+{{range .RecentRemovals}}<tr><td>{{.Key}}</td><td>{{.Time.Format "2006-01-02 15:04:05.000"}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>No caches registered.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// RunCleanup runs the cleanup of the cache registered under name and reports what it did.
+func (m *Manager) RunCleanup(name string) (CleanupReport, bool) {
+	cache, exists := m.Cache(name)
+	if !exists {
+		return CleanupReport{}, false
+	}
+	return cache.RunCleanup(), true
+}
+
+// ServeHTTP renders the status page of the registered caches. A POST with a cleanup parameter
+// naming a cache runs its cleanup and responds with the CleanupReport as JSON.
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		m.serveCleanup(w, r)
+		return
+	}
+
+	type view struct {
+		CacheStatus
+		FillPercent float64
+		HitPercent  float64
+	}
+
+	var views []view
+	for _, status := range m.Status() {
+		views = append(views, view{
+			CacheStatus: status,
+			FillPercent: status.FillRatio * 100,
+			HitPercent:  status.HitRate * 100,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusTemplate.Execute(w, views); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (m *Manager) serveCleanup(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("cleanup")
+	report, exists := m.RunCleanup(name)
+	if !exists {
+		http.Error(w, fmt.Sprintf("bicache: cache %q is not registered", name), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// HitRate returns the share of lookups that were hits.
+func (m CacheMetrics) HitRate() float64 {
+	if m.Hits+m.Misses == 0 {
+		return 0
+	}
+	return float64(m.Hits) / float64(m.Hits+m.Misses)
+}
+
+// String returns the name of the admission policy.
+func (a Admission) String() string {
+	switch a {
+	case AdmitAll:
+		return "all"
+	case CostBenefitAdmission:
+		return "cost-benefit"
+	case TinyLFUAdmission:
+		return "tinylfu"
+	default:
+		return "unknown"
+	}
+}
+
+// status returns the configuration and fill level of the cache.
+func (c *BiCache) status() CacheStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	metrics := c.metrics.snapshot()
+	status := CacheStatus{
+		Capacity:         c.capacity,
+		Entries:          int64(len(c.cacheMap)),
+		GlobalExpiration: c.globalExpiration,
+		CleanupInterval:  c.cleanupInterval,
+		Admission:        c.admission.String(),
+		EvictionPolicy:   c.evictionPolicy.String(),
+		Metrics:          metrics,
+		HitRate:          metrics.HitRate(),
+	}
+	if c.capacity > 0 {
+		status.FillRatio = float64(status.Entries) / float64(c.capacity)
+	}
+	return status
+}
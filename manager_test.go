@@ -0,0 +1,80 @@
+package bicache
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager_Register(t *testing.T) {
+	manager := NewManager()
+	users := NewBiCache(10, time.Second)
+
+	if err := manager.Register("users", users); err != nil {
+		t.Fatalf("Manager test failed. Unexpected error: %v", err)
+	}
+	if err := manager.Register("users", NewBiCache(10, time.Second)); err == nil {
+		t.Errorf("Manager test failed. Expected: error for a duplicate name")
+	}
+	if cache, found := manager.Cache("users"); !found || cache != users {
+		t.Errorf("Manager test failed. Expected: registered cache")
+	}
+
+	manager.Unregister("users")
+	if names := manager.Names(); len(names) != 0 {
+		t.Errorf("Manager test failed. Expected: no caches, Got: %v", names)
+	}
+}
+
+func TestManager_ServeHTTP(t *testing.T) {
+	manager := NewManager()
+	cache := NewBiCache(4, time.Second)
+	manager.Register("sessions", cache)
+
+	cache.Set("session:1", "value", time.Minute)
+	cache.Set("session:2", "value", time.Minute)
+	cache.Get("session:1")
+	cache.Get("session:3")
+	cache.Delete("session:2")
+
+	// Wait for the asynchronous event handlers
+	time.Sleep(time.Millisecond * 50)
+
+	status := manager.Status()
+	if len(status) != 1 || status[0].Entries != 1 || status[0].HitRate != 0.5 || status[0].FillRatio != 0.25 {
+		t.Fatalf("Manager status test failed. Got: %+v", status)
+	}
+	if len(status[0].RecentRemovals) != 1 || status[0].RecentRemovals[0].Key != "session:2" {
+		t.Errorf("Manager status test (removals) failed. Got: %+v", status[0].RecentRemovals)
+	}
+
+	recorder := httptest.NewRecorder()
+	manager.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/bicache", nil))
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, "sessions") || !strings.Contains(body, "session:2") || !strings.Contains(body, "50.0%") {
+		t.Errorf("Manager ServeHTTP test failed. Got: %v", body)
+	}
+}
+
+func TestManager_RunCleanup(t *testing.T) {
+	manager := NewManager()
+	cache := NewBiCache(10, time.Hour)
+	manager.Register("sessions", cache)
+
+	cache.Set("session:1", "value", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	manager.ServeHTTP(recorder, httptest.NewRequest("POST", "/debug/bicache?cleanup=sessions", nil))
+	if body := recorder.Body.String(); !strings.Contains(body, `"Expired":1`) {
+		t.Errorf("Manager RunCleanup test failed. Got: %v", body)
+	}
+
+	recorder = httptest.NewRecorder()
+	manager.ServeHTTP(recorder, httptest.NewRequest("POST", "/debug/bicache?cleanup=missing", nil))
+	if recorder.Code != 404 {
+		t.Errorf("Manager RunCleanup test failed. Expected: 404, Got: %v", recorder.Code)
+	}
+}
@@ -0,0 +1,291 @@
+package bicache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchEntry is an entry written by SetMany, with its own TTL.
+type BatchEntry struct {
+	Key   interface{}
+	Value interface{}
+	// TTL is the expiration of the entry, as passed to Set.
+	TTL time.Duration
+}
+
+// getManyLoads is the number of misses GetMany loads at the same time.
+const getManyLoads = 16
+
+// GetMany looks up keys like Get and returns the values found together with the keys that
+// were not, in the order they were requested. Repeated keys are looked up once. The cached
+// entries are read under a single lock acquisition, and the misses that have a loader are then
+// loaded up to 16 at a time, so a batch loader receives them together. Unlike Get, the lookups
+// are not traced, shadow-read or used for predictions.
+func (c *BiCache) GetMany(keys []interface{}) (map[interface{}]interface{}, []interface{}) {
+	ctx := context.Background()
+	keys = uniqueKeys(keys)
+	found := make(map[interface{}]interface{}, len(keys))
+	if c.closed.Load() {
+		return found, keys
+	}
+
+	start := time.Now()
+	reads := make([]entryRead, len(keys))
+	loaders := make([]ContextLoaderFunc, len(keys))
+	c.mu.RLock()
+	for i, key := range keys {
+		if c.sketch != nil {
+			c.sketch.add(c.mapKey(key))
+		}
+		reads[i] = c.readEntry(key, start)
+		if reads[i].err == ErrNotFound {
+			loaders[i] = c.registeredLoader(key)
+		}
+	}
+	c.mu.RUnlock()
+
+	type load struct {
+		key    interface{}
+		loader ContextLoaderFunc
+	}
+	var loads []load
+	for i, key := range keys {
+		entry, err := c.finishRead(key, reads[i])
+		if err == nil {
+			found[key] = entry.Value
+			if expired(entry, time.Now()) {
+				c.refreshAsync(ctx, key, entry)
+			} else {
+				c.refreshEarly(ctx, key, entry)
+			}
+			continue
+		}
+		if loaders[i] != nil && c.shed(ctx, key) == nil {
+			loads = append(loads, load{key: key, loader: loaders[i]})
+		}
+	}
+
+	workers := getManyLoads
+	if len(loads) < workers {
+		workers = len(loads)
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan load)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := range queue {
+				if entry, err := c.load(ctx, l.key, l.loader); err == nil {
+					mu.Lock()
+					found[l.key] = entry.Value
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, l := range loads {
+		queue <- l
+	}
+	close(queue)
+	wg.Wait()
+
+	return found, missingKeys(keys, found)
+}
+
+// SetMany stores entries in order, each with its own TTL, and returns the errors of the ones
+// that could not be stored by key, or nil if all were stored. The entries are first passed to
+// the writer, if one is set, and then stored under a single lock acquisition.
+func (c *BiCache) SetMany(entries []BatchEntry, opts ...SetOption) map[interface{}]error {
+	var errs map[interface{}]error
+	fail := func(key interface{}, err error) {
+		if errs == nil {
+			errs = make(map[interface{}]error)
+		}
+		errs[key] = err
+	}
+
+	ctx := context.Background()
+	written := make([]BatchEntry, 0, len(entries))
+	for _, entry := range entries {
+		if ok, err := c.writeThrough(ctx, entry.Key, entry.Value, entry.TTL, opts); !ok {
+			if err != nil {
+				fail(entry.Key, err)
+			}
+			continue
+		}
+		written = append(written, entry)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	options := newSetOptions(append(opts[:len(opts):len(opts)], withThrough))
+	for _, entry := range written {
+		if c.closed.Load() {
+			fail(entry.Key, ErrClosed)
+			continue
+		}
+		if err := c.store(ctx, entry.Key, entry.Value, entry.TTL, options); err != nil {
+			fail(entry.Key, err)
+		}
+	}
+	return errs
+}
+
+// DeleteMany deletes keys like Delete under a single lock acquisition and returns how many
+// of them held a live entry.
+func (c *BiCache) DeleteMany(keys []interface{}) int {
+	ctx := context.Background()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return 0
+	}
+
+	now := time.Now()
+	deleted := 0
+	for _, key := range uniqueKeys(keys) {
+		if entry, exists := c.cacheMap[c.mapKey(key)]; exists && c.live(key, entry, now) {
+			deleted++
+		}
+		c.delete(ctx, key)
+	}
+	return deleted
+}
+
+// uniqueKeys returns keys without repetitions, in the order they first appear.
+func uniqueKeys(keys []interface{}) []interface{} {
+	unique := make([]interface{}, 0, len(keys))
+	seen := make(map[interface{}]struct{}, len(keys))
+	for _, key := range keys {
+		if _, exists := seen[key]; !exists {
+			seen[key] = struct{}{}
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// missingKeys returns the keys not in found, in order.
+func missingKeys(keys []interface{}, found map[interface{}]interface{}) []interface{} {
+	var missing []interface{}
+	for _, key := range keys {
+		if _, exists := found[key]; !exists {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// GetMany looks up keys like BiCache.GetMany, taking the lock of each shard once.
+func (c *ShardedBiCache) GetMany(keys []interface{}) (map[interface{}]interface{}, []interface{}) {
+	keys = uniqueKeys(keys)
+	found := make(map[interface{}]interface{}, len(keys))
+	for shard, shardKeys := range c.keysByShard(keys) {
+		shardFound, _ := shard.GetMany(shardKeys)
+		for key, value := range shardFound {
+			found[key] = value
+		}
+	}
+	return found, missingKeys(keys, found)
+}
+
+// SetMany stores entries like BiCache.SetMany, taking the lock of each shard once.
+func (c *ShardedBiCache) SetMany(entries []BatchEntry, opts ...SetOption) map[interface{}]error {
+	byShard := make(map[*BiCache][]BatchEntry)
+	for _, entry := range entries {
+		shard := c.Shard(entry.Key)
+		byShard[shard] = append(byShard[shard], entry)
+	}
+
+	var errs map[interface{}]error
+	for shard, shardEntries := range byShard {
+		for key, err := range shard.SetMany(shardEntries, opts...) {
+			if errs == nil {
+				errs = make(map[interface{}]error)
+			}
+			errs[key] = err
+		}
+	}
+	return errs
+}
+
+// DeleteMany deletes keys like BiCache.DeleteMany, taking the lock of each shard once.
+func (c *ShardedBiCache) DeleteMany(keys []interface{}) int {
+	deleted := 0
+	for shard, shardKeys := range c.keysByShard(keys) {
+		deleted += shard.DeleteMany(shardKeys)
+	}
+	return deleted
+}
+
+// keysByShard groups keys by the shard they are stored in.
+func (c *ShardedBiCache) keysByShard(keys []interface{}) map[*BiCache][]interface{} {
+	byShard := make(map[*BiCache][]interface{})
+	for _, key := range keys {
+		shard := c.Shard(key)
+		byShard[shard] = append(byShard[shard], key)
+	}
+	return byShard
+}
+
+// GetMany is the typed variant of BiCache.GetMany. Values of other types are reported as missing.
+func (c *TypedBiCache[K, V]) GetMany(keys []K) (map[K]V, []K) {
+	untyped := make([]interface{}, len(keys))
+	for i, key := range keys {
+		untyped[i] = key
+	}
+
+	found, _ := c.cache.GetMany(untyped)
+	typed := make(map[K]V, len(found))
+	var missing []K
+	seen := make(map[K]struct{}, len(keys))
+	for _, key := range keys {
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if value, ok := typedValue[V](found[key], hasKey(found, key)); ok {
+			typed[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return typed, missing
+}
+
+// SetMany is the typed variant of BiCache.SetMany, storing entries with the same TTL.
+func (c *TypedBiCache[K, V]) SetMany(entries map[K]V, expiration time.Duration, opts ...SetOption) map[K]error {
+	batch := make([]BatchEntry, 0, len(entries))
+	for key, value := range entries {
+		batch = append(batch, BatchEntry{Key: key, Value: value, TTL: expiration})
+	}
+
+	var errs map[K]error
+	for key, err := range c.cache.SetMany(batch, opts...) {
+		if errs == nil {
+			errs = make(map[K]error)
+		}
+		errs[key.(K)] = err
+	}
+	return errs
+}
+
+// DeleteMany is the typed variant of BiCache.DeleteMany.
+func (c *TypedBiCache[K, V]) DeleteMany(keys []K) int {
+	untyped := make([]interface{}, len(keys))
+	for i, key := range keys {
+		untyped[i] = key
+	}
+	return c.cache.DeleteMany(untyped)
+}
+
+func hasKey(m map[interface{}]interface{}, key interface{}) bool {
+	_, exists := m[key]
+	return exists
+}
@@ -0,0 +1,190 @@
+package bicache
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_GetMany(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+	cache.Set("key3", "value3", 0)
+
+	found, missing := cache.GetMany([]interface{}{"key1", "key2", "key3", "key4", "key2"})
+	if len(found) != 2 || found["key1"] != "value1" || found["key3"] != "value3" {
+		t.Errorf("GetMany test failed. Expected: key1 and key3, Got: %v", found)
+	}
+	if len(missing) != 2 || missing[0] != "key2" || missing[1] != "key4" {
+		t.Errorf("GetMany test failed. Expected: missing [key2 key4], Got: %v", missing)
+	}
+}
+
+func TestBiCache_SetMany(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetWithOptions("key3", "old", 0, WithReadOnly())
+
+	errs := cache.SetMany([]BatchEntry{
+		{Key: "key1", Value: "value1", TTL: time.Minute},
+		{Key: "key2", Value: "value2", TTL: time.Hour},
+		{Key: "key3", Value: "value3"},
+	})
+	if len(errs) != 1 || errs["key3"] == nil {
+		t.Errorf("SetMany test failed. Expected: an error for key3, Got: %v", errs)
+	}
+
+	_, info1, _ := cache.GetWithInfo("key1")
+	_, info2, _ := cache.GetWithInfo("key2")
+	if remaining := time.Until(info1.Expiration); remaining > time.Minute || remaining < 50*time.Second {
+		t.Errorf("SetMany test failed. Expected: key1 expiring in 1m, Got: %v", remaining)
+	}
+	if remaining := time.Until(info2.Expiration); remaining > time.Hour || remaining < 59*time.Minute {
+		t.Errorf("SetMany test failed. Expected: key2 expiring in 1h, Got: %v", remaining)
+	}
+}
+
+func TestBiCache_GetManyBatchLoader(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+
+	var calls atomic.Int32
+	cache.SetBatchLoader(func(keys []interface{}) (map[interface{}]LoaderResult, error) {
+		calls.Add(1)
+		results := make(map[interface{}]LoaderResult)
+		for _, key := range keys {
+			if key != "key4" {
+				results[key] = LoaderResult{Value: "loaded"}
+			}
+		}
+		return results, nil
+	}, 20*time.Millisecond)
+
+	found, missing := cache.GetMany([]interface{}{"key1", "key2", "key3", "key4"})
+	if len(found) != 3 || found["key1"] != "value1" || found["key2"] != "loaded" || found["key3"] != "loaded" {
+		t.Errorf("GetMany batch loader test failed. Expected: key1 to key3, Got: %v", found)
+	}
+	if len(missing) != 1 || missing[0] != "key4" {
+		t.Errorf("GetMany batch loader test failed. Expected: missing [key4], Got: %v", missing)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("GetMany batch loader test failed. Expected: 1 batch load, Got: %d", n)
+	}
+	if !cache.Has("key2") {
+		t.Errorf("GetMany batch loader test failed. Expected: loaded values stored")
+	}
+}
+
+func TestBiCache_SetManyWriter(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	written := make(map[interface{}]interface{})
+	cache.SetWriter(func(key, value interface{}) error {
+		if key == "key2" {
+			return errors.New("rejected")
+		}
+		written[key] = value
+		return nil
+	})
+
+	errs := cache.SetMany([]BatchEntry{
+		{Key: "key1", Value: "value1"},
+		{Key: "key2", Value: "value2"},
+	})
+	if len(errs) != 1 || errs["key2"] == nil {
+		t.Errorf("SetMany writer test failed. Expected: an error for key2, Got: %v", errs)
+	}
+	if written["key1"] != "value1" || !cache.Has("key1") {
+		t.Errorf("SetMany writer test failed. Expected: key1 written and cached")
+	}
+	if cache.Has("key2") {
+		t.Errorf("SetMany writer test failed. Expected: key2 not cached")
+	}
+}
+
+func TestBiCache_DeleteMany(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+
+	if deleted := cache.DeleteMany([]interface{}{"key1", "key2", "key4", "key1"}); deleted != 2 {
+		t.Errorf("DeleteMany test failed. Expected: 2, Got: %d", deleted)
+	}
+	if cache.Has("key1") || cache.Has("key2") || !cache.Has("key3") {
+		t.Errorf("DeleteMany test failed. Expected: only key3 left")
+	}
+	if deletions := cache.GetMetrics().Deletions; deletions != 2 {
+		t.Errorf("DeleteMany test failed. Expected: 2 deletions, Got: %d", deletions)
+	}
+}
+
+func TestShardedBiCache_Many(t *testing.T) {
+	cache := NewShardedBiCache(4, 100, time.Minute)
+	var entries []BatchEntry
+	var keys []interface{}
+	for i := 0; i < 20; i++ {
+		entries = append(entries, BatchEntry{Key: i, Value: i * 2})
+		keys = append(keys, i)
+	}
+	if errs := cache.SetMany(entries); errs != nil {
+		t.Fatalf("ShardedBiCache Many test failed. Expected: no errors, Got: %v", errs)
+	}
+
+	if deleted := cache.DeleteMany([]interface{}{0, 1, 2}); deleted != 3 {
+		t.Errorf("ShardedBiCache Many test failed. Expected: 3 deleted, Got: %d", deleted)
+	}
+
+	found, missing := cache.GetMany(append(keys, 20))
+	if len(found) != 17 || found[10] != 20 {
+		t.Errorf("ShardedBiCache Many test failed. Expected: 17 found, Got: %v", found)
+	}
+	if len(missing) != 4 || missing[0] != 0 || missing[1] != 1 || missing[2] != 2 || missing[3] != 20 {
+		t.Errorf("ShardedBiCache Many test failed. Expected: missing [0 1 2 20], Got: %v", missing)
+	}
+}
+
+func TestTypedBiCache_Many(t *testing.T) {
+	cache := NewTypedBiCache[string, int](10, time.Minute)
+	if errs := cache.SetMany(map[string]int{"a": 1, "b": 2}, 0); errs != nil {
+		t.Fatalf("TypedBiCache Many test failed. Expected: no errors, Got: %v", errs)
+	}
+
+	found, missing := cache.GetMany([]string{"a", "b", "c"})
+	if len(found) != 2 || found["a"] != 1 || found["b"] != 2 {
+		t.Errorf("TypedBiCache Many test failed. Expected: a and b, Got: %v", found)
+	}
+	if len(missing) != 1 || missing[0] != "c" {
+		t.Errorf("TypedBiCache Many test failed. Expected: missing [c], Got: %v", missing)
+	}
+	if deleted := cache.DeleteMany([]string{"a", "c"}); deleted != 1 {
+		t.Errorf("TypedBiCache Many test failed. Expected: 1 deleted, Got: %d", deleted)
+	}
+}
+
+func TestBiCache_GetManyLoadConcurrency(t *testing.T) {
+	cache := NewBiCache(100, time.Minute)
+	var running, peak atomic.Int32
+	cache.SetLoader(func(key interface{}) (interface{}, time.Duration, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := peak.Load()
+			if n <= current || peak.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 5)
+		return key, 0, nil
+	})
+
+	keys := make([]interface{}, 50)
+	for i := range keys {
+		keys[i] = i
+	}
+	if found, _ := cache.GetMany(keys); len(found) != 50 {
+		t.Errorf("GetMany concurrency test failed. Expected: 50 loaded, Got: %d", len(found))
+	}
+	if n := peak.Load(); n > getManyLoads {
+		t.Errorf("GetMany concurrency test failed. Expected: at most %d loads at a time, Got: %d", getManyLoads, n)
+	}
+}
@@ -0,0 +1,152 @@
+package bicache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// metricCounters holds the metrics of a cache as atomic counters, so that they can be updated
+// without the write lock. Fields are named after the CacheMetrics fields they are reported as.
+type metricCounters struct {
+	Hits              atomic.Int64
+	Misses            atomic.Int64
+	SetSuccess        atomic.Int64
+	SetError          atomic.Int64
+	ValidationError   atomic.Int64
+	EntriesCount      atomic.Int64
+	HitLatency        atomic.Int64
+	MissPenalty       atomic.Int64
+	ResolvedMisses    atomic.Int64
+	AdmissionRejected atomic.Int64
+	LoadSuccess       atomic.Int64
+	LoadError         atomic.Int64
+	LoadSuppressed    atomic.Int64
+	PredictionsLoaded atomic.Int64
+	PredictionHits    atomic.Int64
+	Revalidations     atomic.Int64
+	RevalidationsSame atomic.Int64
+	EarlyRefreshes    atomic.Int64
+	LoadLatency       atomic.Int64
+	DecodeError       atomic.Int64
+	TotalWeight       atomic.Int64
+	MemoryBytes       atomic.Int64
+	Evictions         atomic.Int64
+	Expirations       atomic.Int64
+	ShadowReads       atomic.Int64
+	ShadowMismatches  atomic.Int64
+	DualWrites        atomic.Int64
+	DualWriteErrors   atomic.Int64
+	DualWriteLag      atomic.Int64
+	WriteBehinds      atomic.Int64
+	WriteBehindErrors atomic.Int64
+	Inserts           atomic.Int64
+	Overwrites        atomic.Int64
+	Deletions         atomic.Int64
+	EvictedLifetime   atomic.Int64
+	ExpiredLifetime   atomic.Int64
+	DeletedLifetime   atomic.Int64
+	OverwriteLifetime atomic.Int64
+	LoadShed          atomic.Int64
+	RefreshesDropped  atomic.Int64
+	LoadsDeduplicated atomic.Int64
+	RefreshesAhead    atomic.Int64
+	StaleServed       atomic.Int64
+	NegativeHits      atomic.Int64
+}
+
+// snapshot returns the current values of the counters. The entry count, weight and memory
+// are consistent with each other while the caller holds the read or write lock.
+func (m *metricCounters) snapshot() CacheMetrics {
+	return CacheMetrics{
+		Hits:              m.Hits.Load(),
+		Misses:            m.Misses.Load(),
+		SetSuccess:        m.SetSuccess.Load(),
+		SetError:          m.SetError.Load(),
+		ValidationError:   m.ValidationError.Load(),
+		EntriesCount:      m.EntriesCount.Load(),
+		HitLatency:        time.Duration(m.HitLatency.Load()),
+		MissPenalty:       time.Duration(m.MissPenalty.Load()),
+		ResolvedMisses:    m.ResolvedMisses.Load(),
+		AdmissionRejected: m.AdmissionRejected.Load(),
+		LoadSuccess:       m.LoadSuccess.Load(),
+		LoadError:         m.LoadError.Load(),
+		LoadSuppressed:    m.LoadSuppressed.Load(),
+		PredictionsLoaded: m.PredictionsLoaded.Load(),
+		PredictionHits:    m.PredictionHits.Load(),
+		Revalidations:     m.Revalidations.Load(),
+		RevalidationsSame: m.RevalidationsSame.Load(),
+		EarlyRefreshes:    m.EarlyRefreshes.Load(),
+		LoadLatency:       time.Duration(m.LoadLatency.Load()),
+		DecodeError:       m.DecodeError.Load(),
+		TotalWeight:       m.TotalWeight.Load(),
+		MemoryBytes:       m.MemoryBytes.Load(),
+		Evictions:         m.Evictions.Load(),
+		Expirations:       m.Expirations.Load(),
+		ShadowReads:       m.ShadowReads.Load(),
+		ShadowMismatches:  m.ShadowMismatches.Load(),
+		DualWrites:        m.DualWrites.Load(),
+		DualWriteErrors:   m.DualWriteErrors.Load(),
+		DualWriteLag:      time.Duration(m.DualWriteLag.Load()),
+		WriteBehinds:      m.WriteBehinds.Load(),
+		WriteBehindErrors: m.WriteBehindErrors.Load(),
+		Inserts:           m.Inserts.Load(),
+		Overwrites:        m.Overwrites.Load(),
+		Deletions:         m.Deletions.Load(),
+		EvictedLifetime:   time.Duration(m.EvictedLifetime.Load()),
+		ExpiredLifetime:   time.Duration(m.ExpiredLifetime.Load()),
+		DeletedLifetime:   time.Duration(m.DeletedLifetime.Load()),
+		OverwriteLifetime: time.Duration(m.OverwriteLifetime.Load()),
+		LoadShed:          m.LoadShed.Load(),
+		RefreshesDropped:  m.RefreshesDropped.Load(),
+		LoadsDeduplicated: m.LoadsDeduplicated.Load(),
+		RefreshesAhead:    m.RefreshesAhead.Load(),
+		StaleServed:       m.StaleServed.Load(),
+		NegativeHits:      m.NegativeHits.Load(),
+	}
+}
+
+// restore adds the cumulative metrics of a previous run to the counters. Gauges are left alone,
+// and hits of the hot tier are restored as plain hits.
+func (m *metricCounters) restore(metrics CacheMetrics) {
+	m.Hits.Add(metrics.Hits)
+	m.Misses.Add(metrics.Misses)
+	m.SetSuccess.Add(metrics.SetSuccess)
+	m.SetError.Add(metrics.SetError)
+	m.ValidationError.Add(metrics.ValidationError)
+	m.HitLatency.Add(int64(metrics.HitLatency))
+	m.MissPenalty.Add(int64(metrics.MissPenalty))
+	m.ResolvedMisses.Add(metrics.ResolvedMisses)
+	m.AdmissionRejected.Add(metrics.AdmissionRejected)
+	m.LoadSuccess.Add(metrics.LoadSuccess)
+	m.LoadError.Add(metrics.LoadError)
+	m.LoadSuppressed.Add(metrics.LoadSuppressed)
+	m.PredictionsLoaded.Add(metrics.PredictionsLoaded)
+	m.PredictionHits.Add(metrics.PredictionHits)
+	m.Revalidations.Add(metrics.Revalidations)
+	m.RevalidationsSame.Add(metrics.RevalidationsSame)
+	m.EarlyRefreshes.Add(metrics.EarlyRefreshes)
+	m.LoadLatency.Add(int64(metrics.LoadLatency))
+	m.DecodeError.Add(metrics.DecodeError)
+	m.Evictions.Add(metrics.Evictions)
+	m.Expirations.Add(metrics.Expirations)
+	m.ShadowReads.Add(metrics.ShadowReads)
+	m.ShadowMismatches.Add(metrics.ShadowMismatches)
+	m.DualWrites.Add(metrics.DualWrites)
+	m.DualWriteErrors.Add(metrics.DualWriteErrors)
+	m.DualWriteLag.Add(int64(metrics.DualWriteLag))
+	m.WriteBehinds.Add(metrics.WriteBehinds)
+	m.WriteBehindErrors.Add(metrics.WriteBehindErrors)
+	m.Inserts.Add(metrics.Inserts)
+	m.Overwrites.Add(metrics.Overwrites)
+	m.Deletions.Add(metrics.Deletions)
+	m.EvictedLifetime.Add(int64(metrics.EvictedLifetime))
+	m.ExpiredLifetime.Add(int64(metrics.ExpiredLifetime))
+	m.DeletedLifetime.Add(int64(metrics.DeletedLifetime))
+	m.OverwriteLifetime.Add(int64(metrics.OverwriteLifetime))
+	m.LoadShed.Add(metrics.LoadShed)
+	m.RefreshesDropped.Add(metrics.RefreshesDropped)
+	m.LoadsDeduplicated.Add(metrics.LoadsDeduplicated)
+	m.RefreshesAhead.Add(metrics.RefreshesAhead)
+	m.StaleServed.Add(metrics.StaleServed)
+	m.NegativeHits.Add(metrics.NegativeHits)
+}
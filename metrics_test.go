@@ -0,0 +1,54 @@
+package bicache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_EvictionAndExpirationMetrics(t *testing.T) {
+	cache := NewBiCache(2, time.Minute)
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+	if metrics := cache.GetMetrics(); metrics.Evictions != 1 || metrics.Expirations != 0 {
+		t.Errorf("Metrics test (evict) failed. Expected: 1 eviction, Got: %+v", metrics)
+	}
+
+	cache.Set("key4", "value4", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	cache.Get("key4")
+	if metrics := cache.GetMetrics(); metrics.Expirations != 1 {
+		t.Errorf("Metrics test (expire) failed. Expected: 1 expiration, Got: %v", metrics.Expirations)
+	}
+
+	// Deletes are neither evictions nor expirations
+	cache.Delete("key3")
+	if metrics := cache.GetMetrics(); metrics.Evictions != 2 || metrics.Expirations != 1 {
+		t.Errorf("Metrics test (delete) failed. Expected: 2 evictions and 1 expiration, Got: %+v", metrics)
+	}
+}
+
+func TestBiCache_ConcurrentMetrics(t *testing.T) {
+	cache := NewBiCache(50, time.Minute)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				cache.Set(i%20, i, 0)
+				cache.Get(i % 40)
+				cache.GetMetrics()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	metrics := cache.GetMetrics()
+	if metrics.SetSuccess != 2000 || metrics.Hits+metrics.Misses != 2000 {
+		t.Errorf("ConcurrentMetrics test failed. Expected: 2000 sets and lookups, Got: %v and %v", metrics.SetSuccess, metrics.Hits+metrics.Misses)
+	}
+}
@@ -0,0 +1,75 @@
+package bicache
+
+import (
+	"errors"
+	"time"
+)
+
+// negativeEntry remembers a load that found no value or failed.
+type negativeEntry struct {
+	err       error
+	expiresAt time.Time
+}
+
+// SetNegativeCaching remembers loads for which the loader returned ErrNotFound for notFoundTTL,
+// so lookups of the key miss right away instead of calling the loader again. If errorTTL is
+// positive, loads that failed with another error are remembered for errorTTL and return the same
+// error. Writing the key forgets it. Misses answered this way are counted in the NegativeHits metric.
+// A notFoundTTL and errorTTL of 0 disable negative caching.
+func (c *BiCache) SetNegativeCaching(notFoundTTL, errorTTL time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.notFoundTTL = notFoundTTL
+	c.errorTTL = errorTTL
+	if notFoundTTL <= 0 && errorTTL <= 0 {
+		c.negatives = nil
+	}
+}
+
+// negativeResult returns the remembered result of loading key, or nil if there is none.
+func (c *BiCache) negativeResult(key interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	negative, exists := c.negatives[key]
+	if !exists || !time.Now().Before(negative.expiresAt) {
+		return nil
+	}
+
+	c.metrics.NegativeHits.Add(1)
+	return negative.err
+}
+
+// recordNegative remembers a failed load of key if negative caching applies to err.
+// The caller must hold the write lock.
+func (c *BiCache) recordNegative(key interface{}, err error) {
+	ttl := c.errorTTL
+	if errors.Is(err, ErrNotFound) {
+		ttl = c.notFoundTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	if c.negatives == nil {
+		c.negatives = make(map[interface{}]negativeEntry)
+	}
+	c.negatives[key] = negativeEntry{err: err, expiresAt: time.Now().Add(ttl)}
+}
+
+// cleanupNegatives forgets the negative entries that expired.
+func (c *BiCache) cleanupNegatives(now time.Time) {
+	for key, negative := range c.negatives {
+		if !now.Before(negative.expiresAt) {
+			delete(c.negatives, key)
+		}
+	}
+}
+
+// SetNegativeCaching enables negative caching on every shard.
+func (c *ShardedBiCache) SetNegativeCaching(notFoundTTL, errorTTL time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetNegativeCaching(notFoundTTL, errorTTL)
+	}
+}
@@ -0,0 +1,86 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_NegativeCaching(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetNegativeCaching(time.Millisecond*50, 0)
+
+	loads := 0
+	errBackend := errors.New("backend unavailable")
+	cache.SetLoader(func(key interface{}) (interface{}, time.Duration, error) {
+		loads++
+		if key == "broken" {
+			return nil, 0, errBackend
+		}
+		return nil, 0, ErrNotFound
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetWithError("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Negative caching test failed. Expected: %v, Got: %v", ErrNotFound, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Negative caching test failed. Expected: 1 load, Got: %v", loads)
+	}
+
+	// Errors are not cached unless an error TTL is set
+	cache.Get("broken")
+	cache.Get("broken")
+	if loads != 3 {
+		t.Errorf("Negative caching test failed. Expected: errors not cached, Got: %v loads", loads)
+	}
+
+	// Writing the key forgets the negative entry
+	cache.Set("missing", "value", 0)
+	if value, found := cache.Get("missing"); !found || value != "value" {
+		t.Errorf("Negative caching test failed. Expected: value after Set, Got: %v", value)
+	}
+	cache.Delete("missing")
+	cache.Get("missing")
+	if loads != 4 {
+		t.Errorf("Negative caching test failed. Expected: reloaded after Set, Got: %v loads", loads)
+	}
+
+	// The negative entry expires
+	time.Sleep(time.Millisecond * 60)
+	cache.Get("missing")
+	if loads != 5 {
+		t.Errorf("Negative caching test failed. Expected: reloaded after the TTL, Got: %v loads", loads)
+	}
+	if metrics := cache.GetMetrics(); metrics.NegativeHits != 2 {
+		t.Errorf("Negative caching test failed. Expected: NegativeHits=2, Got: %v", metrics.NegativeHits)
+	}
+}
+
+func TestBiCache_NegativeCachingErrors(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetNegativeCaching(0, time.Minute)
+
+	loads := 0
+	errBackend := errors.New("backend unavailable")
+	cache.SetLoader(func(key interface{}) (interface{}, time.Duration, error) {
+		loads++
+		return nil, 0, errBackend
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetWithError("key1"); err != errBackend {
+			t.Errorf("Negative caching errors test failed. Expected: %v, Got: %v", errBackend, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Negative caching errors test failed. Expected: 1 load, Got: %v", loads)
+	}
+
+	cache.SetNegativeCaching(0, 0)
+	cache.Get("key1")
+	if loads != 2 {
+		t.Errorf("Negative caching errors test failed. Expected: disabled, Got: %v loads", loads)
+	}
+}
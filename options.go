@@ -0,0 +1,119 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// SetOption configures a single entry stored with SetWithOptions.
+type SetOption func(*setOptions)
+
+type setOptions struct {
+	readOnly bool
+	force    bool
+	metadata map[string]string
+	tags     []string
+	token    string
+	softTTL  time.Duration
+	writer   string
+	// fencingToken is the token of the lease the write is made under, 0 if none
+	fencingToken uint64
+	// through is set for writes of callers, which go through to the backing store, unlike loaded values
+	through bool
+	// restored is set for entries restored from a snapshot, which are not copied to the dual-write store
+	restored bool
+	// softExpiration is the soft expiration of an entry restored from a snapshot
+	softExpiration time.Time
+	// uncounted is set for entries restored together with the metrics that already count them
+	uncounted bool
+}
+
+func newSetOptions(opts []SetOption) setOptions {
+	var options setOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithReadOnly marks the entry as read-only so later writes to the same key fail with a ReadOnlyError.
+func WithReadOnly() SetOption {
+	return func(o *setOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithForce overwrites the entry even if the existing one is read-only.
+func WithForce() SetOption {
+	return func(o *setOptions) {
+		o.force = true
+	}
+}
+
+// WithMetadata attaches small annotations, such as provenance information, to the entry.
+func WithMetadata(metadata map[string]string) SetOption {
+	return func(o *setOptions) {
+		o.metadata = copyMetadata(metadata)
+	}
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
+
+// WithTags labels the entry with tags.
+func WithTags(tags ...string) SetOption {
+	return func(o *setOptions) {
+		o.tags = copyTags(tags)
+	}
+}
+
+func copyTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return append([]string(nil), tags...)
+}
+
+// WithToken stores a revalidation token, such as an ETag or version, with the entry.
+func WithToken(token string) SetOption {
+	return func(o *setOptions) {
+		o.token = token
+	}
+}
+
+// WithSoftTTL marks the entry as stale after ttl while it is still served until it expires.
+func WithSoftTTL(ttl time.Duration) SetOption {
+	return func(o *setOptions) {
+		o.softTTL = ttl
+	}
+}
+
+// WithWriter records the component that writes the entry, overriding the writer of the context.
+func WithWriter(writer string) SetOption {
+	return func(o *setOptions) {
+		o.writer = writer
+	}
+}
+
+type writerKey struct{}
+
+// ContextWithWriter returns a context that records writer as the component writing entries
+// with SetContext, including entries loaded on a GetContext miss.
+func ContextWithWriter(ctx context.Context, writer string) context.Context {
+	return context.WithValue(ctx, writerKey{}, writer)
+}
+
+// writerFromContext returns the writer recorded in ctx, if any.
+func writerFromContext(ctx context.Context) string {
+	writer, _ := ctx.Value(writerKey{}).(string)
+	return writer
+}
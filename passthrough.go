@@ -0,0 +1,27 @@
+package bicache
+
+// SetPassthrough turns passthrough mode on or off at runtime. In passthrough mode the cache stores
+// nothing: its entries are removed, every Get misses and is loaded if a loader is registered, and
+// writes are dropped after reaching the writer and write-behind store. It rules the cache out while
+// investigating an incident without changing code. The Passthrough metric reports the mode.
+func (c *BiCache) SetPassthrough(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.passthrough.Store(enabled)
+	if enabled {
+		c.clearEntries()
+	}
+}
+
+// Passthrough reports whether the cache is in passthrough mode.
+func (c *BiCache) Passthrough() bool {
+	return c.passthrough.Load()
+}
+
+// SetPassthrough turns passthrough mode on or off on every shard.
+func (c *ShardedBiCache) SetPassthrough(enabled bool) {
+	for _, shard := range c.shards {
+		shard.SetPassthrough(enabled)
+	}
+}
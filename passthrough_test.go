@@ -0,0 +1,56 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_Passthrough(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "cached", 0)
+
+	written := 0
+	cache.SetWriter(func(key, value interface{}) error {
+		written++
+		return nil
+	})
+	cache.RegisterLoader("loaded:", func(key interface{}) (interface{}, time.Duration, error) {
+		return "source", 0, nil
+	})
+
+	cache.SetPassthrough(true)
+	if !cache.Passthrough() || !cache.GetMetrics().Passthrough {
+		t.Errorf("Passthrough test failed. Expected: passthrough reported")
+	}
+	if _, found := cache.Get("key1"); found {
+		t.Errorf("Passthrough test failed. Expected: cached entry not served")
+	}
+
+	// Writes reach the writer but are not cached, loads are served but not cached
+	cache.Set("key2", "value2", 0)
+	if written != 1 || cache.Has("key2") {
+		t.Errorf("Passthrough test failed. Expected: write forwarded and dropped, Got: %d writes", written)
+	}
+	if value, _ := cache.Get("loaded:1"); value != "source" || cache.Has("loaded:1") {
+		t.Errorf("Passthrough test failed. Expected: source loaded and not cached, Got: %v", value)
+	}
+	if count := cache.GetMetrics().EntriesCount; count != 0 {
+		t.Errorf("Passthrough test failed. Expected: 0 entries, Got: %d", count)
+	}
+
+	cache.SetPassthrough(false)
+	cache.Set("key2", "value2", 0)
+	if value, _ := cache.Get("key2"); value != "value2" || cache.GetMetrics().Passthrough {
+		t.Errorf("Passthrough test failed. Expected: value2 cached again, Got: %v", value)
+	}
+}
+
+func TestShardedBiCache_Passthrough(t *testing.T) {
+	cache := NewShardedBiCache(4, 40, time.Minute)
+	cache.Set("key1", "value1", 0)
+
+	cache.SetPassthrough(true)
+	if _, found := cache.Get("key1"); found || !cache.GetMetrics().Passthrough {
+		t.Errorf("Sharded passthrough test failed. Expected: miss in passthrough mode")
+	}
+}
@@ -0,0 +1,80 @@
+package bicache
+
+import "time"
+
+// missPenaltyWindow is how long a miss waits to be resolved by a Set of the same key.
+const missPenaltyWindow = time.Minute
+
+// defaultPendingMisses bounds the tracked misses of a cache without a capacity.
+const defaultPendingMisses = 10000
+
+// AverageHitLatency returns the mean time spent serving a hit.
+func (m CacheMetrics) AverageHitLatency() time.Duration {
+	if m.Hits == 0 {
+		return 0
+	}
+	return m.HitLatency / time.Duration(m.Hits)
+}
+
+// AverageMissPenalty returns the mean time it took to resolve a miss.
+func (m CacheMetrics) AverageMissPenalty() time.Duration {
+	if m.ResolvedMisses == 0 {
+		return 0
+	}
+	return m.MissPenalty / time.Duration(m.ResolvedMisses)
+}
+
+// LatencySaved estimates the total latency avoided by serving hits instead of resolving misses.
+func (m CacheMetrics) LatencySaved() time.Duration {
+	saved := m.AverageMissPenalty() - m.AverageHitLatency()
+	if saved <= 0 {
+		return 0
+	}
+	return saved * time.Duration(m.Hits)
+}
+
+// recordMiss remembers when a miss happened so the following Set can measure how long it took to resolve.
+func (c *BiCache) recordMiss(key interface{}) {
+	c.missMu.Lock()
+	defer c.missMu.Unlock()
+
+	// Keep the tracking bounded, misses that are never resolved are dropped in cleanup
+	limit := c.capacity
+	if limit == 0 {
+		limit = defaultPendingMisses
+	}
+	if _, exists := c.pendingMisses[key]; !exists && len(c.pendingMisses) < limit {
+		c.pendingMisses[key] = time.Now()
+	}
+}
+
+// resolveMiss returns how long ago key missed, if it did.
+func (c *BiCache) resolveMiss(key interface{}) (time.Duration, bool) {
+	c.missMu.Lock()
+	defer c.missMu.Unlock()
+
+	missed, exists := c.pendingMisses[key]
+	if !exists {
+		return 0, false
+	}
+	delete(c.pendingMisses, key)
+	return time.Since(missed), true
+}
+
+// recordMissPenalty adds a resolved miss to the metrics.
+func (c *BiCache) recordMissPenalty(penalty time.Duration) {
+	c.metrics.MissPenalty.Add(int64(penalty))
+	c.metrics.ResolvedMisses.Add(1)
+}
+
+// cleanupPendingMisses drops misses that were not resolved within missPenaltyWindow.
+func (c *BiCache) cleanupPendingMisses(now time.Time) {
+	c.missMu.Lock()
+	defer c.missMu.Unlock()
+
+	for key, missed := range c.pendingMisses {
+		if now.Sub(missed) > missPenaltyWindow {
+			delete(c.pendingMisses, key)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_MissPenalty(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+
+	// Miss the key and resolve it after a delay
+	cache.Get("key1")
+	time.Sleep(time.Millisecond * 50)
+	cache.Set("key1", "value1", time.Second*20)
+
+	// Hit the key a few times
+	for i := 0; i < 3; i++ {
+		cache.Get("key1")
+	}
+
+	metrics := cache.GetMetrics()
+	if metrics.ResolvedMisses != 1 || metrics.AverageMissPenalty() < time.Millisecond*50 {
+		t.Errorf("MissPenalty test failed. Expected: ResolvedMisses=1, penalty>=50ms. Got: ResolvedMisses=%v, penalty=%v",
+			metrics.ResolvedMisses, metrics.AverageMissPenalty())
+	}
+
+	// Three hits should have saved roughly three miss penalties
+	if metrics.LatencySaved() < time.Millisecond*100 {
+		t.Errorf("MissPenalty test (latency saved) failed. Expected: >=100ms, Got: %v", metrics.LatencySaved())
+	}
+}
+
+func TestBiCache_PendingMissesUnbounded(t *testing.T) {
+	cache := NewBiCache(0, time.Minute)
+	defer cache.Close()
+
+	// Misses of a cache without a capacity must not be tracked without limit
+	for i := 0; i < defaultPendingMisses+100; i++ {
+		cache.Get(i)
+	}
+
+	cache.missMu.Lock()
+	pending := len(cache.pendingMisses)
+	cache.missMu.Unlock()
+	if pending != defaultPendingMisses {
+		t.Errorf("PendingMisses test failed. Expected: %d, Got: %d", defaultPendingMisses, pending)
+	}
+}
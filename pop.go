@@ -0,0 +1,38 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// Pop returns the value stored under key and deletes it under one lock, so of several goroutines
+// popping the same key only one gets the value. It suits one-shot tokens and handing work over
+// between goroutines. Loaders are not called, and the deletion reaches the dual-write and
+// write-behind stores like Delete.
+func (c *BiCache) Pop(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return nil, false
+	}
+
+	value, found := c.liveValue(key, time.Now())
+	if !found {
+		c.metrics.Misses.Add(1)
+		return nil, false
+	}
+	c.delete(context.Background(), key)
+	return value, true
+}
+
+// Pop returns and deletes the value stored under key.
+func (c *ShardedBiCache) Pop(key interface{}) (interface{}, bool) {
+	return c.Shard(key).Pop(key)
+}
+
+// Pop is the typed variant of BiCache.Pop.
+func (c *TypedBiCache[K, V]) Pop(key K) (V, bool) {
+	value, found := c.cache.Pop(key)
+	return typedValue[V](value, found)
+}
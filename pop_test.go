@@ -0,0 +1,42 @@
+package bicache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Pop(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+
+	if value, found := cache.Pop("key1"); !found || value != "value1" {
+		t.Errorf("Pop test failed. Expected: value1, Got: %v, %v", value, found)
+	}
+	if _, found := cache.Pop("key1"); found || cache.Has("key1") {
+		t.Errorf("Pop test failed. Expected: key1 deleted")
+	}
+	if metrics := cache.GetMetrics(); metrics.Hits != 1 || metrics.Misses != 1 || metrics.Deletions != 1 {
+		t.Errorf("Pop test failed. Expected: 1 hit, 1 miss and 1 deletion, Got: %d, %d, %d", metrics.Hits, metrics.Misses, metrics.Deletions)
+	}
+
+	// Only one of the racing goroutines gets the token
+	cache.Set("token", "secret", 0)
+	var wg sync.WaitGroup
+	var popped atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, found := cache.Pop("token"); found {
+				popped.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if popped.Load() != 1 {
+		t.Errorf("Pop test failed. Expected: 1 pop, Got: %d", popped.Load())
+	}
+}
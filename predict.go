@@ -0,0 +1,94 @@
+package bicache
+
+import "context"
+
+// predictionConcurrency bounds the number of background prediction loads.
+const predictionConcurrency = 4
+
+// PredictNextFunc returns keys that are likely to be requested after key.
+type PredictNextFunc func(key interface{}) []interface{}
+
+// SetPredictNext registers a hook called on hits whose predicted keys are loaded in the background.
+func (c *BiCache) SetPredictNext(predict PredictNextFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.predictNext = predict
+}
+
+// PredictionHitRate returns the share of predicted loads that were later hit.
+func (m CacheMetrics) PredictionHitRate() float64 {
+	if m.PredictionsLoaded == 0 {
+		return 0
+	}
+	return float64(m.PredictionHits) / float64(m.PredictionsLoaded)
+}
+
+// predict loads the keys predicted after a hit on key in the background.
+func (c *BiCache) predict(ctx context.Context, key interface{}) {
+	c.mu.RLock()
+	predict := c.predictNext
+	c.mu.RUnlock()
+
+	if predict == nil {
+		return
+	}
+
+	// Drop the prediction if too many are already running
+	select {
+	case c.predictSem <- struct{}{}:
+	default:
+		return
+	}
+
+	ctx = withoutCancel(ctx)
+	go func() {
+		defer func() { <-c.predictSem }()
+
+		for _, next := range predict(key) {
+			if c.contains(next) {
+				continue
+			}
+			loader := c.loaderFor(next)
+			if loader == nil {
+				continue
+			}
+			if _, err := c.load(ctx, next, loader); err != nil {
+				if err != ErrLoadSuppressed {
+					c.reportError("predict", next, err)
+				}
+				continue
+			}
+
+			c.predictMu.Lock()
+			c.predicted[next] = struct{}{}
+			c.predictMu.Unlock()
+
+			c.metrics.PredictionsLoaded.Add(1)
+		}
+	}()
+}
+
+// predictedHit counts the first hit on a key that was loaded by a prediction.
+func (c *BiCache) predictedHit(key interface{}) {
+	c.predictMu.Lock()
+	_, predicted := c.predicted[key]
+	delete(c.predicted, key)
+	c.predictMu.Unlock()
+
+	if predicted {
+		c.metrics.PredictionHits.Add(1)
+	}
+}
+
+// cleanupPredicted forgets predicted keys that are no longer cached.
+func (c *BiCache) cleanupPredicted() {
+	c.predictMu.Lock()
+	defer c.predictMu.Unlock()
+
+	for key := range c.predicted {
+		if _, exists := c.cacheMap[c.mapKey(key)]; !exists {
+			delete(c.predicted, key)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_PredictNext(t *testing.T) {
+	cache := NewBiCache(10, time.Second)
+	cache.RegisterLoader("page:", func(key interface{}) (interface{}, time.Duration, error) {
+		return "content of " + key.(string), time.Minute, nil
+	})
+	cache.SetPredictNext(func(key interface{}) []interface{} {
+		if key == "page:1" {
+			return []interface{}{"page:2", "page:3"}
+		}
+		return nil
+	})
+
+	// A hit on the first page prefetches the next pages
+	cache.Set("page:1", "content of page:1", time.Minute)
+	cache.Get("page:1")
+
+	// Wait for the background prediction
+	time.Sleep(time.Millisecond * 100)
+
+	if result, found := cache.Get("page:2"); !found || result != "content of page:2" {
+		t.Errorf("PredictNext test failed. Expected: 'content of page:2', Got: '%v'", result)
+	}
+
+	metrics := cache.GetMetrics()
+	if metrics.PredictionsLoaded != 2 || metrics.PredictionHits != 1 || metrics.PredictionHitRate() != 0.5 {
+		t.Errorf("PredictNext test failed. Expected: PredictionsLoaded=2, PredictionHits=1, rate=0.5. Got: PredictionsLoaded=%v, PredictionHits=%v, rate=%v",
+			metrics.PredictionsLoaded, metrics.PredictionHits, metrics.PredictionHitRate())
+	}
+}
@@ -0,0 +1,119 @@
+package bicache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PrefetchOptions configures Prefetch.
+type PrefetchOptions struct {
+	// Concurrency is the number of keys loaded in parallel, 1 if not set.
+	Concurrency int
+	// Progress is called after each key with the number of keys done so far.
+	Progress func(done, total int)
+}
+
+// PrefetchResult summarizes a Prefetch call.
+type PrefetchResult struct {
+	Requested int
+	Skipped   int
+	Loaded    int
+	Failed    int
+	Errors    map[interface{}]error
+}
+
+// Prefetch warms the cache by loading keys through their loaders with a bounded worker pool.
+// Keys that are already cached or repeated are skipped.
+func (c *BiCache) Prefetch(ctx context.Context, keys []interface{}, opts PrefetchOptions) PrefetchResult {
+	result := PrefetchResult{Requested: len(keys), Errors: make(map[interface{}]error)}
+
+	// Deduplicate keys and skip the ones that are already cached
+	seen := make(map[interface{}]struct{}, len(keys))
+	var pending []interface{}
+	for _, key := range keys {
+		if _, exists := seen[key]; exists {
+			result.Skipped++
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if c.contains(key) {
+			result.Skipped++
+			continue
+		}
+		pending = append(pending, key)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := result.Skipped
+	report := func(key interface{}, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err != nil {
+			result.Failed++
+			result.Errors[key] = err
+		} else {
+			result.Loaded++
+		}
+		done++
+		if opts.Progress != nil {
+			opts.Progress(done, len(keys))
+		}
+	}
+
+	queue := make(chan interface{})
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				report(key, c.prefetch(ctx, key))
+			}
+		}()
+	}
+
+	for i, key := range pending {
+		select {
+		case queue <- key:
+		case <-ctx.Done():
+			// Report the keys that were never dispatched
+			for _, key := range pending[i:] {
+				report(key, ctx.Err())
+			}
+			close(queue)
+			wg.Wait()
+			return result
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return result
+}
+
+// prefetch loads a single key through its loader.
+func (c *BiCache) prefetch(ctx context.Context, key interface{}) error {
+	loader := c.loaderFor(key)
+	if loader == nil {
+		return ErrNoLoader
+	}
+	_, err := c.load(ctx, key, loader)
+	return err
+}
+
+// contains reports whether key holds a non-expired entry without touching the metrics.
+func (c *BiCache) contains(key interface{}) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	return exists && c.live(key, entry, time.Now())
+}
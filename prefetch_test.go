@@ -0,0 +1,60 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Prefetch(t *testing.T) {
+	cache := NewBiCache(10, time.Second)
+
+	var loads int32
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		if key == "bad" {
+			return nil, 0, errors.New("load failed")
+		}
+		return "value", time.Minute, nil
+	})
+
+	// One key is already cached and one is repeated
+	cache.Set("a", "cached", time.Minute)
+
+	var progress int
+	result := cache.Prefetch(context.Background(), []interface{}{"a", "b", "c", "c", "bad"}, PrefetchOptions{
+		Concurrency: 2,
+		Progress: func(done, total int) {
+			progress = done
+		},
+	})
+
+	if result.Requested != 5 || result.Skipped != 2 || result.Loaded != 2 || result.Failed != 1 || result.Errors["bad"] == nil {
+		t.Errorf("Prefetch test failed. Expected: Requested=5, Skipped=2, Loaded=2, Failed=1, Got: %+v", result)
+	}
+	if loads != 3 || progress != 5 {
+		t.Errorf("Prefetch test failed. Expected: 3 loads and progress 5, Got: %v loads and progress %v", loads, progress)
+	}
+
+	// Prefetched keys are served from the cache
+	if result, found := cache.Get("b"); !found || result != "value" {
+		t.Errorf("Prefetch test (cached) failed. Expected: 'value', Got: '%v'", result)
+	}
+}
+
+func TestBiCache_PrefetchCanceled(t *testing.T) {
+	cache := NewBiCache(10, time.Second)
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		return "value", time.Minute, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := cache.Prefetch(ctx, []interface{}{"a", "b"}, PrefetchOptions{})
+	if result.Loaded+result.Failed != 2 {
+		t.Errorf("Prefetch canceled test failed. Expected: all keys reported, Got: %+v", result)
+	}
+}
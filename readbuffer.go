@@ -0,0 +1,92 @@
+package bicache
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// readStripes is the number of independently locked buffers that reads are recorded in.
+	readStripes = 16
+	// readStripeSize is the number of reads a stripe holds before they are applied to the eviction policy.
+	readStripeSize = 64
+)
+
+// readAccess is a read of key at a point in time.
+type readAccess struct {
+	key interface{}
+	at  time.Time
+}
+
+// readBuffer collects reads so that hits only take the eviction policy's lock once per batch
+// instead of on every read. Reads are spread over stripes at random to avoid a shared lock.
+type readBuffer struct {
+	stripes [readStripes]readStripe
+}
+
+type readStripe struct {
+	mu    sync.Mutex
+	reads []readAccess
+	_     [40]byte // keeps stripes on separate cache lines
+}
+
+// record adds a read and returns the reads of its stripe if the stripe is full.
+func (b *readBuffer) record(key interface{}, at time.Time) []readAccess {
+	stripe := &b.stripes[rand.Intn(readStripes)]
+	stripe.mu.Lock()
+	defer stripe.mu.Unlock()
+
+	stripe.reads = append(stripe.reads, readAccess{key: key, at: at})
+	if len(stripe.reads) < readStripeSize {
+		return nil
+	}
+
+	reads := stripe.reads
+	stripe.reads = make([]readAccess, 0, readStripeSize)
+	return reads
+}
+
+// drain returns the reads of all stripes.
+func (b *readBuffer) drain() []readAccess {
+	var reads []readAccess
+	for i := range b.stripes {
+		stripe := &b.stripes[i]
+		stripe.mu.Lock()
+		reads = append(reads, stripe.reads...)
+		stripe.reads = stripe.reads[:0]
+		stripe.mu.Unlock()
+	}
+	return reads
+}
+
+// recordRead records a read of key for the eviction policy. The caller must hold the read lock.
+func (c *BiCache) recordRead(key interface{}, at time.Time) {
+	if reads := c.reads.record(key, at); reads != nil {
+		c.applyReads(reads)
+	}
+}
+
+// applyReads passes buffered reads to the eviction policy in the order they happened, skipping
+// keys that were removed since. The caller must hold the read or write lock.
+func (c *BiCache) applyReads(reads []readAccess) {
+	sort.SliceStable(reads, func(i, j int) bool {
+		return reads[i].at.Before(reads[j].at)
+	})
+	for _, read := range reads {
+		key := c.mapKey(read.key)
+		if _, exists := c.cacheMap[key]; exists {
+			c.eviction.touch(key)
+		}
+	}
+}
+
+// victims returns up to n keys in eviction order after applying the buffered reads.
+// The caller must hold the read or write lock.
+func (c *BiCache) victims(n int) []interface{} {
+	if reads := c.reads.drain(); len(reads) > 0 {
+		c.applyReads(reads)
+	}
+	return c.eviction.victims(n)
+}
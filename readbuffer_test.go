@@ -0,0 +1,53 @@
+package bicache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBiCache_BufferedReadsKeepOrder(t *testing.T) {
+	cache := NewBiCache(3, time.Minute)
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+
+	// Reads are buffered but applied in order before the next eviction
+	cache.Get("key2")
+	cache.Get("key1")
+	cache.Get("key3")
+	if victims := cache.WouldEvict(3); len(victims) != 3 || victims[0] != "key2" || victims[1] != "key1" {
+		t.Errorf("BufferedReadsKeepOrder test failed. Expected: [key2 key1 key3], Got: %v", victims)
+	}
+
+	cache.Set("key4", "value4", 0)
+	if _, found := cache.Get("key2"); found {
+		t.Errorf("BufferedReadsKeepOrder test failed. Expected: key2 evicted")
+	}
+}
+
+func TestBiCache_ParallelReads(t *testing.T) {
+	cache := NewBiCache(100, time.Minute)
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i, 0)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.Get(i % 100)
+				if i%50 == 0 {
+					cache.Set(100+g, i, 0)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if metrics := cache.GetMetrics(); metrics.Hits+metrics.Misses != 8000 {
+		t.Errorf("ParallelReads test failed. Expected: 8000 lookups, Got: %v", metrics.Hits+metrics.Misses)
+	}
+}
@@ -0,0 +1,138 @@
+package bicache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Reconfigure applies the settings of cfg to a live cache at once, under a single lock, and evicts
+// entries if the cache is over its new capacity. The compression settings are left unchanged,
+// because stored values depend on them, and so are the weigher and the error handler if cfg
+// leaves them nil and the decode failure behavior if cfg leaves it at DecodeFailureKeep, as
+// configs loaded from files do. A new weigher weighs the stored entries again. It returns the
+// errors of cfg.Validate without applying anything.
+func (c *BiCache) Reconfigure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	if cfg.CleanupInterval != c.cleanupInterval {
+		c.cleanupInterval = cfg.CleanupInterval
+		c.cleanupTicker.Reset(cfg.CleanupInterval)
+	}
+	c.globalExpiration = cfg.GlobalExpiration
+
+	protectedRatio := cfg.ProtectedRatio
+	if protectedRatio == 0 {
+		protectedRatio = defaultProtectedRatio
+	}
+	evictionSamples := cfg.EvictionSamples
+	if evictionSamples == 0 {
+		evictionSamples = defaultEvictionSamples
+	}
+	c.capacity = cfg.Capacity
+	c.zeroCapacity = cfg.ZeroCapacity
+	c.evictionSamples = evictionSamples
+	if cfg.EvictionPolicy != c.evictionPolicy || protectedRatio != c.protectedRatio {
+		c.evictionPolicy = cfg.EvictionPolicy
+		c.protectedRatio = protectedRatio
+		c.resetEvictor()
+	} else {
+		c.eviction.setCapacity(cfg.Capacity)
+		if s, ok := c.eviction.(*sampled); ok {
+			s.samples = evictionSamples
+		}
+	}
+
+	if cfg.Admission != c.admission {
+		c.admission = cfg.Admission
+		c.window = newLRUList()
+		if cfg.Admission == TinyLFUAdmission && c.sketch == nil {
+			c.sketch = newCountMinSketch(c.capacity, c.hasher)
+		}
+	}
+
+	if cfg.Weigher != nil {
+		c.weigher = cfg.Weigher
+		c.reweigh()
+	}
+	c.maxWeight = cfg.MaxWeight
+	c.maxMemory = cfg.MaxMemory
+	c.measure()
+	if cfg.DecodeFailure != DecodeFailureKeep {
+		c.decodeFailure = cfg.DecodeFailure
+	}
+	if cfg.ErrorHandler != nil {
+		c.errorHandler.Store(cfg.ErrorHandler)
+	}
+
+	if c.storesNothing() {
+		c.clearEntries()
+	} else if c.overCapacity() {
+		c.cleanup()
+		c.evict(nil)
+	}
+	return nil
+}
+
+// Reconfigure reconfigures every shard with an even share of cfg.Capacity. The number of shards
+// can't be changed; a cfg.Shards other than zero or the current number is an error.
+func (c *ShardedBiCache) Reconfigure(cfg Config) error {
+	if cfg.Shards != 0 && cfg.Shards != len(c.shards) {
+		return &ConfigError{Field: "Shards", Reason: fmt.Sprintf("can't be changed from %d at runtime", len(c.shards))}
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	cfg.Capacity = (cfg.Capacity + len(c.shards) - 1) / len(c.shards)
+	for _, shard := range c.shards {
+		if err := shard.Reconfigure(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchConfig checks the config file at path every interval until ctx is done, and reconfigures
+// cache with LoadConfig each time the file was modified. Errors loading or applying the file
+// are passed to onError, if set, and leave the cache unchanged.
+func WatchConfig(ctx context.Context, cache *BiCache, path string, interval time.Duration, onError func(error)) {
+	var modified time.Time
+	if info, err := os.Stat(path); err == nil {
+		modified = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(modified) {
+			continue
+		}
+		modified = info.ModTime()
+
+		cfg, err := LoadConfig(path)
+		if err == nil {
+			err = cache.Reconfigure(cfg)
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
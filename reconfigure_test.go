@@ -0,0 +1,102 @@
+package bicache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Reconfigure(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i, 0)
+	}
+
+	err := cache.Reconfigure(Config{Capacity: 4, CleanupInterval: time.Second, EvictionPolicy: ARCEviction})
+	if err != nil {
+		t.Fatalf("Reconfigure test failed. Unexpected error: %v", err)
+	}
+
+	status := cache.status()
+	if status.Entries != 4 || status.Capacity != 4 || status.CleanupInterval != time.Second || status.EvictionPolicy != "arc" {
+		t.Errorf("Reconfigure test failed. Got: %+v", status)
+	}
+
+	// Invalid configs are not applied
+	if err := cache.Reconfigure(Config{Capacity: -1, CleanupInterval: time.Second}); err == nil {
+		t.Errorf("Reconfigure test failed. Expected: error for a negative capacity")
+	}
+	if status := cache.status(); status.Capacity != 4 {
+		t.Errorf("Reconfigure test failed. Expected: capacity 4 kept, Got: %v", status.Capacity)
+	}
+}
+
+func TestBiCache_ReconfigureWeigher(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetWeigher(func(key, value interface{}) int64 { return int64(len(value.(string))) })
+	cache.Set("a", "xx", 0)
+	cache.Set("b", "xxx", 0)
+
+	// Configs without a weigher keep the current one
+	if err := cache.Reconfigure(Config{Capacity: 10, CleanupInterval: time.Minute}); err != nil {
+		t.Fatalf("Reconfigure weigher test failed. Unexpected error: %v", err)
+	}
+	cache.Set("c", "x", 0)
+	if weight := cache.GetMetrics().TotalWeight; weight != 6 {
+		t.Errorf("Reconfigure weigher test failed. Expected: weight 6, Got: %d", weight)
+	}
+
+	// A new weigher weighs the stored entries again
+	weigher := func(key, value interface{}) int64 { return 10 * int64(len(value.(string))) }
+	if err := cache.Reconfigure(Config{Capacity: 10, CleanupInterval: time.Minute, Weigher: weigher}); err != nil {
+		t.Fatalf("Reconfigure weigher test failed. Unexpected error: %v", err)
+	}
+	if weight := cache.GetMetrics().TotalWeight; weight != 60 {
+		t.Errorf("Reconfigure weigher test failed. Expected: weight 60, Got: %d", weight)
+	}
+}
+
+func TestBiCache_ReconfigureDecodeFailure(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetDecodeFailure(DecodeFailureDelete)
+
+	// Configs that leave the decode failure behavior unset keep the current one
+	if err := cache.Reconfigure(Config{Capacity: 10, CleanupInterval: time.Minute}); err != nil {
+		t.Fatalf("Reconfigure decode failure test failed. Unexpected error: %v", err)
+	}
+	if cache.decodeFailure != DecodeFailureDelete {
+		t.Errorf("Reconfigure decode failure test failed. Expected: %v, Got: %v", DecodeFailureDelete, cache.decodeFailure)
+	}
+
+	if err := cache.Reconfigure(Config{Capacity: 10, CleanupInterval: time.Minute, DecodeFailure: DecodeFailureError}); err != nil {
+		t.Fatalf("Reconfigure decode failure test failed. Unexpected error: %v", err)
+	}
+	if cache.decodeFailure != DecodeFailureError {
+		t.Errorf("Reconfigure decode failure test failed. Expected: %v, Got: %v", DecodeFailureError, cache.decodeFailure)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	os.WriteFile(path, []byte(`{"capacity": 10, "cleanup_interval": "1m"}`), 0o644)
+
+	cache := NewBiCache(10, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go WatchConfig(ctx, cache, path, 10*time.Millisecond, nil)
+
+	// Make sure the modification time moves forward on coarse file systems
+	time.Sleep(20 * time.Millisecond)
+	os.WriteFile(path, []byte(`{"capacity": 3, "cleanup_interval": "1m"}`), 0o644)
+	os.Chtimes(path, time.Now().Add(time.Second), time.Now().Add(time.Second))
+
+	deadline := time.Now().Add(time.Second)
+	for cache.status().Capacity != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("WatchConfig test failed. Expected: capacity 3 after the file changed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
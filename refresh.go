@@ -0,0 +1,96 @@
+package bicache
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// SetEarlyExpiration enables probabilistic early recomputation (XFetch) of entries that have a loader.
+// Each hit refreshes the entry in the background with a probability growing as it approaches expiry,
+// scaled by beta and by how long the entry took to load. A beta of 0 disables it.
+func (c *BiCache) SetEarlyExpiration(beta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.earlyBeta = beta
+}
+
+// SetRefreshAhead makes hits on entries that have a loader and expire within window reload them
+// in the background, so keys that are read often are replaced before they expire and never miss.
+// Entries stored with a token are revalidated instead if a revalidator is set. A window of 0 disables it.
+func (c *BiCache) SetRefreshAhead(window time.Duration) {
+	c.refreshAhead.Store(int64(window))
+}
+
+// shouldRefreshEarly implements the XFetch check: now - delta * beta * ln(rand) >= expiry.
+func shouldRefreshEarly(entry CacheEntry, beta float64, now time.Time) bool {
+	if beta <= 0 || entry.cost <= 0 || entry.Expiration.IsZero() {
+		return false
+	}
+
+	gap := -float64(entry.cost) * beta * math.Log(1-rand.Float64())
+	return !now.Add(time.Duration(gap)).Before(entry.Expiration)
+}
+
+// refreshEarly refreshes key in the background if it expires within the refresh-ahead window
+// or the XFetch check picks this hit.
+func (c *BiCache) refreshEarly(ctx context.Context, key interface{}, entry CacheEntry) {
+	if c.refreshAheadOf(ctx, key, entry) {
+		return
+	}
+
+	c.mu.RLock()
+	beta := c.earlyBeta
+	c.mu.RUnlock()
+
+	if !shouldRefreshEarly(entry, beta, time.Now()) {
+		return
+	}
+
+	if c.refreshAsync(ctx, key, entry) {
+		c.metrics.EarlyRefreshes.Add(1)
+	}
+}
+
+// refreshAheadOf refreshes key in the background if it expires within the refresh-ahead window.
+// It reports whether the entry is within the window.
+func (c *BiCache) refreshAheadOf(ctx context.Context, key interface{}, entry CacheEntry) bool {
+	window := time.Duration(c.refreshAhead.Load())
+	if window <= 0 || entry.Expiration.IsZero() || time.Until(entry.Expiration) > window {
+		return false
+	}
+
+	if c.refreshAsync(ctx, key, entry) {
+		c.metrics.RefreshesAhead.Add(1)
+	}
+	return true
+}
+
+// refreshAsync reloads key, currently stored as entry, in the background unless a refresh of it
+// is already running or queued. It reports whether the refresh was started or queued.
+func (c *BiCache) refreshAsync(ctx context.Context, key interface{}, entry CacheEntry) bool {
+	revalidate := c.revalidates(entry)
+	loader := c.loaderFor(key)
+	if loader == nil && !revalidate {
+		return false
+	}
+
+	job := refreshJob{
+		ctx:        withoutCancel(ctx),
+		key:        key,
+		loader:     loader,
+		revalidate: revalidate,
+		frequency:  c.refreshFrequency(key),
+		expiration: entry.Expiration,
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if _, running := c.refreshing[key]; running {
+		return false
+	}
+	return c.queueRefresh(job)
+}
@@ -0,0 +1,97 @@
+package bicache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_EarlyExpiration(t *testing.T) {
+	cache := NewBiCache(5, time.Minute)
+	cache.SetEarlyExpiration(1)
+
+	var loads int32
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(time.Millisecond * 50)
+		return "value", time.Millisecond * 200, nil
+	})
+
+	// Load the entry, it took a quarter of its TTL to compute
+	cache.Get("key1")
+
+	// Close to expiry, hits should trigger a single background refresh
+	time.Sleep(time.Millisecond * 170)
+	for i := 0; i < 20; i++ {
+		if _, found := cache.Get("key1"); !found {
+			t.Fatalf("EarlyExpiration test failed. Expected: hit while refreshing")
+		}
+	}
+	time.Sleep(time.Millisecond * 100)
+
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Errorf("EarlyExpiration test failed. Expected: 2 loads, Got: %v", n)
+	}
+	if metrics := cache.GetMetrics(); metrics.EarlyRefreshes != 1 {
+		t.Errorf("EarlyExpiration test failed. Expected: EarlyRefreshes=1, Got: %v", metrics.EarlyRefreshes)
+	}
+}
+
+func TestShouldRefreshEarly(t *testing.T) {
+	now := time.Now()
+	entry := CacheEntry{Expiration: now.Add(time.Hour), cost: time.Millisecond}
+
+	// Far from expiry the refresh is practically never triggered
+	for i := 0; i < 100; i++ {
+		if shouldRefreshEarly(entry, 1, now) {
+			t.Fatalf("ShouldRefreshEarly test failed. Expected: no refresh an hour before expiry")
+		}
+	}
+
+	// Without a beta or a measured cost it is disabled
+	entry.Expiration = now
+	if shouldRefreshEarly(entry, 0, now) || shouldRefreshEarly(CacheEntry{Expiration: now}, 1, now) {
+		t.Errorf("ShouldRefreshEarly test failed. Expected: disabled without beta or cost")
+	}
+}
+
+func TestBiCache_RefreshAhead(t *testing.T) {
+	cache := NewBiCache(5, time.Minute)
+	cache.SetRefreshAhead(time.Millisecond * 100)
+
+	var loads int32
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		return "value", time.Millisecond * 200, nil
+	})
+
+	cache.Get("key1")
+
+	// Outside the window hits don't refresh
+	cache.Get("key1")
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Errorf("RefreshAhead test failed. Expected: 1 load, Got: %v", n)
+	}
+
+	// Within the window hits trigger a single background refresh
+	time.Sleep(time.Millisecond * 150)
+	for i := 0; i < 20; i++ {
+		if _, found := cache.Get("key1"); !found {
+			t.Fatalf("RefreshAhead test failed. Expected: hit while refreshing")
+		}
+	}
+	time.Sleep(time.Millisecond * 20)
+
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Errorf("RefreshAhead test failed. Expected: 2 loads, Got: %v", n)
+	}
+	if metrics := cache.GetMetrics(); metrics.RefreshesAhead != 1 {
+		t.Errorf("RefreshAhead test failed. Expected: RefreshesAhead=1, Got: %v", metrics.RefreshesAhead)
+	}
+
+	// The refreshed entry outlives the original expiry
+	time.Sleep(time.Millisecond * 100)
+	if _, found := cache.Peek("key1"); !found {
+		t.Errorf("RefreshAhead test failed. Expected: refreshed entry")
+	}
+}
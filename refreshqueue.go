@@ -0,0 +1,152 @@
+package bicache
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// refreshQueueSize is the maximum number of refreshes waiting for a free slot.
+const refreshQueueSize = 1024
+
+// refreshJob is a background refresh waiting to run.
+type refreshJob struct {
+	ctx    context.Context
+	key    interface{}
+	loader ContextLoaderFunc
+	// revalidate is set for entries that are revalidated instead of reloaded
+	revalidate bool
+	frequency  int
+	expiration time.Time
+}
+
+// before reports whether job should run before other: more frequently read keys first,
+// and of equally hot keys the one expiring first.
+func (job refreshJob) before(other refreshJob) bool {
+	if job.frequency != other.frequency {
+		return job.frequency > other.frequency
+	}
+	return job.expiration.Before(other.expiration)
+}
+
+// refreshQueue is a heap of pending refreshes, the most urgent first.
+type refreshQueue []refreshJob
+
+func (q refreshQueue) Len() int            { return len(q) }
+func (q refreshQueue) Less(i, j int) bool  { return q[i].before(q[j]) }
+func (q refreshQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *refreshQueue) Push(x interface{}) { *q = append(*q, x.(refreshJob)) }
+
+func (q *refreshQueue) Pop() interface{} {
+	old := *q
+	job := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return job
+}
+
+// last returns the index of the least urgent refresh.
+func (q refreshQueue) last() int {
+	last := 0
+	for i := range q {
+		if q[last].before(q[i]) {
+			last = i
+		}
+	}
+	return last
+}
+
+// SetRefreshConcurrency limits the number of background refreshes running at the same time to limit.
+// Further refreshes wait in a queue that runs the keys read most often according to the frequency
+// sketch first and, of equally hot keys, the ones expiring first. When the queue is full, the least
+// urgent refresh is dropped. A limit of 0 or less runs every refresh right away.
+func (c *BiCache) SetRefreshConcurrency(limit int) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.refreshLimit = limit
+	c.startRefreshWorkers()
+}
+
+// queueRefresh runs job now or queues it, depending on the concurrency limit. It reports whether
+// the refresh was accepted. The caller must hold refreshMu.
+func (c *BiCache) queueRefresh(job refreshJob) bool {
+	if c.refreshLimit <= 0 {
+		c.refreshing[job.key] = struct{}{}
+		go c.runRefresh(job)
+		return true
+	}
+
+	if len(c.refreshQueue) >= refreshQueueSize {
+		last := c.refreshQueue.last()
+		if !job.before(c.refreshQueue[last]) {
+			c.metrics.RefreshesDropped.Add(1)
+			return false
+		}
+		dropped := heap.Remove(&c.refreshQueue, last).(refreshJob)
+		delete(c.refreshing, dropped.key)
+		c.metrics.RefreshesDropped.Add(1)
+	}
+
+	c.refreshing[job.key] = struct{}{}
+	heap.Push(&c.refreshQueue, job)
+	c.startRefreshWorkers()
+	return true
+}
+
+// startRefreshWorkers starts workers for the queued refreshes up to the concurrency limit.
+// The caller must hold refreshMu.
+func (c *BiCache) startRefreshWorkers() {
+	for len(c.refreshQueue) > c.refreshWorkers && (c.refreshLimit <= 0 || c.refreshWorkers < c.refreshLimit) {
+		c.refreshWorkers++
+		go c.refreshWorker()
+	}
+}
+
+// refreshWorker runs queued refreshes, most urgent first, until the queue is empty,
+// the concurrency limit was lowered or the cache is closed.
+func (c *BiCache) refreshWorker() {
+	for {
+		c.refreshMu.Lock()
+		if c.closed.Load() {
+			for _, job := range c.refreshQueue {
+				delete(c.refreshing, job.key)
+			}
+			c.refreshQueue = nil
+		}
+		if len(c.refreshQueue) == 0 || (c.refreshLimit > 0 && c.refreshWorkers > c.refreshLimit) {
+			c.refreshWorkers--
+			c.refreshMu.Unlock()
+			return
+		}
+		job := heap.Pop(&c.refreshQueue).(refreshJob)
+		c.refreshMu.Unlock()
+
+		c.runRefresh(job)
+	}
+}
+
+// runRefresh reloads or revalidates the key of job and reports failures.
+func (c *BiCache) runRefresh(job refreshJob) {
+	defer func() {
+		c.refreshMu.Lock()
+		delete(c.refreshing, job.key)
+		c.refreshMu.Unlock()
+	}()
+
+	if job.revalidate {
+		// The entry may have been removed in the meantime
+		if err := c.Revalidate(job.key); err != nil && err != ErrNotFound {
+			c.reportError("refresh", job.key, err)
+		}
+		return
+	}
+	if _, err := c.load(job.ctx, job.key, job.loader); err != nil && err != ErrLoadSuppressed {
+		c.reportError("refresh", job.key, err)
+	}
+}
+
+// refreshFrequency returns the estimated access frequency of key, or 0 without a frequency sketch.
+func (c *BiCache) refreshFrequency(key interface{}) int {
+	frequency, _ := c.EstimateFrequency(key)
+	return frequency
+}
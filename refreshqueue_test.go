@@ -0,0 +1,63 @@
+package bicache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBiCache_RefreshConcurrency(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetRefreshConcurrency(1)
+
+	release := make(chan struct{})
+	loaded := make(chan interface{}, 3)
+	loader := func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		if key == "blocking" {
+			<-release
+		}
+		loaded <- key
+		return LoaderResult{Value: key}, nil
+	}
+
+	now := time.Now()
+	cache.refreshMu.Lock()
+	cache.queueRefresh(refreshJob{ctx: context.Background(), key: "blocking", loader: loader})
+	cache.refreshMu.Unlock()
+
+	// Wait for the only worker to pick up the blocking refresh
+	for deadline := time.Now().Add(time.Second); ; {
+		cache.refreshMu.Lock()
+		queued := len(cache.refreshQueue)
+		cache.refreshMu.Unlock()
+		if queued == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cache.refreshMu.Lock()
+	cache.queueRefresh(refreshJob{ctx: context.Background(), key: "cold", loader: loader, frequency: 1, expiration: now})
+	cache.queueRefresh(refreshJob{ctx: context.Background(), key: "hot-later", loader: loader, frequency: 5, expiration: now.Add(time.Minute)})
+	cache.queueRefresh(refreshJob{ctx: context.Background(), key: "hot-soon", loader: loader, frequency: 5, expiration: now.Add(time.Second)})
+	cache.refreshMu.Unlock()
+	close(release)
+
+	var order []interface{}
+	for i := 0; i < 4; i++ {
+		select {
+		case key := <-loaded:
+			order = append(order, key)
+		case <-time.After(time.Second):
+			t.Fatalf("Refresh queue test failed. Expected: 4 refreshes, Got: %v", order)
+		}
+	}
+
+	expected := []interface{}{"blocking", "hot-soon", "hot-later", "cold"}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("Refresh queue test failed. Expected: %v, Got: %v", expected, order)
+			break
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package bicache
+
+import (
+	"strings"
+	"time"
+)
+
+// Rename atomically moves the entry stored under oldKey to newKey, keeping its value, expiration,
+// tags and metadata. An entry already stored under newKey is replaced, unless it is read-only.
+// It returns ErrNotFound if oldKey holds no live entry. Only the cache is changed, the dual-write
+// and write-behind stores are not told about the move.
+func (c *BiCache) Rename(oldKey, newKey interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return ErrClosed
+	}
+
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(oldKey)]
+	if !exists || !c.live(oldKey, entry, now) {
+		return ErrNotFound
+	}
+	if c.mapKey(oldKey) == c.mapKey(newKey) {
+		return nil
+	}
+	if err := c.checkRenameTarget(newKey, now); err != nil {
+		return err
+	}
+
+	c.dropEntry(c.mapKey(oldKey))
+	c.moveEntry(oldKey, newKey, entry)
+	return nil
+}
+
+// MoveNamespace atomically renames every live entry whose string key starts with namespace so
+// that its key starts with to instead, like Rename. Nothing is moved if any of the new keys holds
+// a read-only entry. It returns the number of moved entries.
+func (c *BiCache) MoveNamespace(namespace, to string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return 0, ErrClosed
+	}
+	if namespace == to {
+		return 0, nil
+	}
+
+	type move struct {
+		oldKey, newKey string
+		entry          CacheEntry
+	}
+
+	now := time.Now()
+	var moves []move
+	for stored, entry := range c.cacheMap {
+		key, ok := c.externalKey(stored).(string)
+		if !ok || !strings.HasPrefix(key, namespace) || !c.live(key, entry, now) {
+			continue
+		}
+		moves = append(moves, move{oldKey: key, newKey: to + strings.TrimPrefix(key, namespace), entry: entry})
+	}
+
+	// Keys that are moved themselves don't block the move
+	sources := make(map[interface{}]struct{}, len(moves))
+	for _, m := range moves {
+		sources[c.mapKey(m.oldKey)] = struct{}{}
+	}
+	for _, m := range moves {
+		if _, moved := sources[c.mapKey(m.newKey)]; moved {
+			continue
+		}
+		if err := c.checkRenameTarget(m.newKey, now); err != nil {
+			return 0, err
+		}
+	}
+
+	// Drop all old keys first so moves onto keys of the namespace don't overwrite each other
+	for _, m := range moves {
+		c.dropEntry(c.mapKey(m.oldKey))
+	}
+	for _, m := range moves {
+		c.moveEntry(m.oldKey, m.newKey, m.entry)
+	}
+	return len(moves), nil
+}
+
+// checkRenameTarget refuses to replace a live read-only entry stored under key.
+// The caller must hold the write lock.
+func (c *BiCache) checkRenameTarget(key interface{}, now time.Time) error {
+	if current, exists := c.cacheMap[c.mapKey(key)]; exists && current.ReadOnly && c.live(key, current, now) {
+		c.audit(AuditReadOnlyViolation, key, "entry is read-only", "")
+		return &ReadOnlyError{Key: key}
+	}
+	return nil
+}
+
+// moveEntry stores entry, already dropped from oldKey, under newKey. The caller must hold the write lock.
+func (c *BiCache) moveEntry(oldKey, newKey interface{}, entry CacheEntry) {
+	if _, exists := c.cacheMap[c.mapKey(newKey)]; exists {
+		c.removeEntry(newKey, removalDeleted)
+	}
+
+	// A new generation keeps the entry from being treated as invalidated in its new namespace
+	c.generation++
+	entry.generation = c.generation
+	entry.memory = 0
+	c.storeEntry(newKey, entry)
+	delete(c.negatives, newKey)
+
+	c.emit(CacheEventDelete, oldKey, CacheEntry{})
+	c.emit(CacheEventSet, newKey, entry)
+}
+
+// Rename is the typed variant of BiCache.Rename.
+func (c *TypedBiCache[K, V]) Rename(oldKey, newKey K) error {
+	return c.cache.Rename(oldKey, newKey)
+}
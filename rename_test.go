@@ -0,0 +1,84 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Rename(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetWithOptions("old", "value", time.Hour, WithTags("tag1"), WithMetadata(map[string]string{"owner": "team"}))
+	before, _ := cache.TTL("old")
+
+	if err := cache.Rename("old", "new"); err != nil {
+		t.Fatalf("Rename test failed. Expected: no error, Got: %v", err)
+	}
+	if cache.Has("old") {
+		t.Errorf("Rename test failed. Expected: old key removed")
+	}
+	if value, _ := cache.Get("new"); value != "value" {
+		t.Errorf("Rename test failed. Expected: value, Got: %v", value)
+	}
+	if after, _ := cache.TTL("new"); after > before || before-after > time.Second {
+		t.Errorf("Rename test failed. Expected: TTL about %v, Got: %v", before, after)
+	}
+	if keys := cache.KeysByTag("tag1"); len(keys) != 1 || keys[0] != "new" {
+		t.Errorf("Rename test failed. Expected: [new], Got: %v", keys)
+	}
+	if info, _ := cache.GetEntryInfo("new"); info.Metadata["owner"] != "team" {
+		t.Errorf("Rename test failed. Expected: metadata kept, Got: %v", info.Metadata)
+	}
+	if deletions := cache.GetMetrics().Deletions; deletions != 0 {
+		t.Errorf("Rename test failed. Expected: 0 deletions, Got: %d", deletions)
+	}
+
+	if err := cache.Rename("missing", "other"); err != ErrNotFound {
+		t.Errorf("Rename test failed. Expected: %v, Got: %v", ErrNotFound, err)
+	}
+
+	cache.SetWithOptions("locked", "kept", 0, WithReadOnly())
+	var readOnlyErr *ReadOnlyError
+	if err := cache.Rename("new", "locked"); !errors.As(err, &readOnlyErr) {
+		t.Errorf("Rename test failed. Expected: a ReadOnlyError, Got: %v", err)
+	}
+	if value, _ := cache.Get("new"); value != "value" {
+		t.Errorf("Rename test failed. Expected: value, Got: %v", value)
+	}
+}
+
+func TestBiCache_MoveNamespace(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("v1:a", "a", 0)
+	cache.Set("v1:b", "b", 0)
+	cache.Set("v2:b", "old", 0)
+	cache.Set("other", "other", 0)
+
+	// Entries of an invalidated namespace are not moved, and moved entries are not invalidated
+	cache.InvalidateNamespace("v2:")
+	cache.Set("v2:c", "c", 0)
+
+	moved, err := cache.MoveNamespace("v1:", "v2:")
+	if err != nil || moved != 2 {
+		t.Fatalf("MoveNamespace test failed. Expected: 2 moved, Got: %d, %v", moved, err)
+	}
+	for key, expected := range map[string]interface{}{"v2:a": "a", "v2:b": "b", "v2:c": "c", "other": "other"} {
+		if value, _ := cache.Get(key); value != expected {
+			t.Errorf("MoveNamespace test failed. Expected: %v for %s, Got: %v", expected, key, value)
+		}
+	}
+	if cache.Has("v1:a") || cache.Has("v1:b") {
+		t.Errorf("MoveNamespace test failed. Expected: old keys removed")
+	}
+
+	// A read-only target blocks the whole move
+	cache.Set("v3:a", "a", 0)
+	cache.SetWithOptions("v4:a", "locked", 0, WithReadOnly())
+	cache.Set("v3:b", "b", 0)
+	if moved, err := cache.MoveNamespace("v3:", "v4:"); err == nil || moved != 0 {
+		t.Errorf("MoveNamespace test failed. Expected: error, Got: %d, %v", moved, err)
+	}
+	if !cache.Has("v3:a") || !cache.Has("v3:b") || cache.Has("v4:b") {
+		t.Errorf("MoveNamespace test failed. Expected: nothing moved")
+	}
+}
@@ -0,0 +1,78 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// RevalidateFunc checks whether the value behind key changed since token was issued.
+// If it did, it returns the new value and token.
+type RevalidateFunc func(key interface{}, token string) (changed bool, newValue interface{}, newToken string, err error)
+
+// SetRevalidator registers the function used by Revalidate. Background refreshes of entries
+// stored with a token revalidate them instead of reloading them, even without a loader.
+func (c *BiCache) SetRevalidator(revalidate RevalidateFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.revalidate = revalidate
+}
+
+// Revalidate checks the entry stored under key against its source of truth using its token.
+// Unchanged entries only get their TTL extended, changed ones are replaced with the new value.
+func (c *BiCache) Revalidate(key interface{}) error {
+	c.mu.RLock()
+	revalidate := c.revalidate
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	c.mu.RUnlock()
+
+	if revalidate == nil {
+		return ErrNoRevalidator
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	changed, newValue, newToken, err := revalidate(key, entry.Token)
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		// The new value comes from the source of truth, so it is neither written through nor
+		// refused because the entry is read-only
+		opts := []SetOption{WithToken(newToken), WithMetadata(entry.Metadata), WithTags(entry.Tags...), WithWriter(entry.Writer), WithForce()}
+		if entry.ReadOnly {
+			opts = append(opts, WithReadOnly())
+		}
+		err := c.set(context.Background(), key, newValue, entry.ttl, opts...)
+		if err == nil {
+			c.metrics.Revalidations.Add(1)
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Only bump the TTL if the entry was not replaced in the meantime
+	current, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || current.Token != entry.Token {
+		return nil
+	}
+	if current.ttl > 0 {
+		current.Expiration = time.Now().Add(current.ttl)
+	}
+	c.storeEntry(key, current)
+	c.metrics.Revalidations.Add(1)
+	c.metrics.RevalidationsSame.Add(1)
+	return nil
+}
+
+// revalidates reports whether refreshes of entry revalidate it instead of reloading it.
+func (c *BiCache) revalidates(entry CacheEntry) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.revalidate != nil && entry.Token != ""
+}
@@ -0,0 +1,98 @@
+package bicache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Revalidate(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+
+	version := "v1"
+	cache.SetRevalidator(func(key interface{}, token string) (bool, interface{}, string, error) {
+		if token == version {
+			return false, nil, "", nil
+		}
+		return true, "value_" + version, version, nil
+	})
+
+	cache.SetWithOptions("key1", "value_v1", time.Millisecond*300, WithToken("v1"), WithMetadata(map[string]string{"source": "db"}))
+
+	// Unchanged data only extends the TTL
+	time.Sleep(time.Millisecond * 200)
+	if err := cache.Revalidate("key1"); err != nil {
+		t.Fatalf("Revalidate test failed. Unexpected error: %v", err)
+	}
+	time.Sleep(time.Millisecond * 200)
+	if result, found := cache.Get("key1"); !found || result != "value_v1" {
+		t.Errorf("Revalidate test (unchanged) failed. Expected: 'value_v1', Got: '%v'", result)
+	}
+
+	// Changed data replaces the value and token but keeps the metadata
+	version = "v2"
+	if err := cache.Revalidate("key1"); err != nil {
+		t.Fatalf("Revalidate test failed. Unexpected error: %v", err)
+	}
+	info, _ := cache.GetEntryInfo("key1")
+	result, _ := cache.Get("key1")
+	if result != "value_v2" || info.Token != "v2" || info.Metadata["source"] != "db" {
+		t.Errorf("Revalidate test (changed) failed. Expected: 'value_v2' with token v2, Got: '%v' with token %v", result, info.Token)
+	}
+
+	metrics := cache.GetMetrics()
+	if metrics.Revalidations != 2 || metrics.RevalidationsSame != 1 {
+		t.Errorf("Revalidate test failed. Expected: Revalidations=2, RevalidationsSame=1. Got: Revalidations=%v, RevalidationsSame=%v",
+			metrics.Revalidations, metrics.RevalidationsSame)
+	}
+
+	// Missing keys cannot be revalidated
+	if err := cache.Revalidate("key2"); err != ErrNotFound {
+		t.Errorf("Revalidate test (missing key) failed. Expected: '%v', Got: '%v'", ErrNotFound, err)
+	}
+}
+
+func TestBiCache_RefreshAheadRevalidates(t *testing.T) {
+	cache := NewBiCache(5, time.Minute)
+	cache.SetRefreshAhead(time.Millisecond * 100)
+
+	var revalidations atomic.Int32
+	cache.SetRevalidator(func(key interface{}, token string) (bool, interface{}, string, error) {
+		revalidations.Add(1)
+		if key == "changed" {
+			return true, "new", "v2", nil
+		}
+		return false, nil, "", nil
+	})
+	written := 0
+	cache.SetWriter(func(key, value interface{}) error {
+		written++
+		return nil
+	})
+
+	cache.SetWithOptions("same", "value", time.Millisecond*150, WithToken("v1"))
+	cache.SetWithOptions("changed", "old", time.Millisecond*150, WithToken("v1"), WithReadOnly())
+	written = 0
+
+	// Hits within the window revalidate the entries without a loader
+	time.Sleep(time.Millisecond * 80)
+	cache.Get("same")
+	cache.Get("changed")
+	time.Sleep(time.Millisecond * 20)
+	if n := revalidations.Load(); n != 2 {
+		t.Fatalf("RefreshAhead revalidation test failed. Expected: 2 revalidations, Got: %d", n)
+	}
+
+	// The unchanged entry got its TTL extended, the changed one its new value
+	time.Sleep(time.Millisecond * 100)
+	if value, found := cache.Peek("same"); !found || value != "value" {
+		t.Errorf("RefreshAhead revalidation test failed. Expected: value, Got: %v", value)
+	}
+	info, _ := cache.GetEntryInfo("changed")
+	if value, _ := cache.Peek("changed"); value != "new" || info.Token != "v2" || !info.ReadOnly {
+		t.Errorf("RefreshAhead revalidation test failed. Expected: read-only new with token v2, Got: %v, %+v", value, info)
+	}
+	if written != 0 {
+		t.Errorf("RefreshAhead revalidation test failed. Expected: revalidated value not written, Got: %d writes", written)
+	}
+}
@@ -0,0 +1,110 @@
+package bicache
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+)
+
+// rotatingFile is a buffered file that is rotated once it grows past maxBytes. Rotated files are
+// renamed to path.1, path.2 and so on, path.1 being the most recent, and at most maxFiles of them
+// are kept. If maxAge is set, rotated files older than it are removed as well.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+	maxAge   time.Duration
+
+	file   *os.File
+	writer *bufio.Writer
+	size   int64
+}
+
+// openRotatingFile opens or creates the file at path for appending.
+func openRotatingFile(path string, maxBytes int64, maxFiles int, maxAge time.Duration) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxBytes: maxBytes, maxFiles: maxFiles, maxAge: maxAge}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file for appending.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.writer = bufio.NewWriter(file)
+	f.size = info.Size()
+	return nil
+}
+
+// rotateIfFull rotates the file if writing n more bytes to it would exceed maxBytes.
+func (f *rotatingFile) rotateIfFull(n int) error {
+	if f.size > 0 && f.size+int64(n) > f.maxBytes {
+		return f.rotate()
+	}
+	return nil
+}
+
+func (f *rotatingFile) write(data []byte) error {
+	n, err := f.writer.Write(data)
+	f.size += int64(n)
+	return err
+}
+
+func (f *rotatingFile) flush() error {
+	return f.writer.Flush()
+}
+
+// close flushes and closes the file.
+func (f *rotatingFile) close() error {
+	if err := f.writer.Flush(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
+// rotate closes the file, shifts the rotated files by one, dropping the oldest, and opens a new file.
+func (f *rotatingFile) rotate() error {
+	if err := f.writer.Flush(); err != nil {
+		return err
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxFiles))
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	renameErr := os.Rename(f.path, f.path+".1")
+	f.removeExpired()
+	if err := f.open(); err != nil {
+		return err
+	}
+	return renameErr
+}
+
+// removeExpired removes the rotated files last written more than maxAge ago.
+func (f *rotatingFile) removeExpired() {
+	if f.maxAge <= 0 {
+		return
+	}
+	for i := 1; i <= f.maxFiles; i++ {
+		name := fmt.Sprintf("%s.%d", f.path, i)
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > f.maxAge {
+			os.Remove(name)
+		}
+	}
+}
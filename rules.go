@@ -0,0 +1,115 @@
+package bicache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"time"
+)
+
+// RuleCompileFunc compiles the source of a detection rule, such as a Sigma rule, into an artifact
+// of any type, such as a query or a matcher.
+type RuleCompileFunc func(ctx context.Context, source []byte) (interface{}, error)
+
+// RuleCacheConfig configures a RuleCache.
+type RuleCacheConfig struct {
+	// Capacity is the maximum number of compiled rules, 10000 if not set.
+	Capacity int
+	// PollInterval is how often the files rules depend on are checked for changes, one second if not set.
+	PollInterval time.Duration
+	// OnInvalidate is called with a changed or removed file and the number of compiled rules
+	// invalidated because of it.
+	OnInvalidate func(path string, invalidated int)
+}
+
+// RuleCache is a cache preset for compiled detection rules. Compiled rules are keyed by the hash
+// of their source, so changing a rule compiles it again, and are invalidated when a file they
+// depend on, such as a field mapping or processing pipeline, changes.
+type RuleCache struct {
+	// Cache stores the compiled rules, keyed by RuleHash of their source.
+	Cache   *BiCache
+	config  RuleCacheConfig
+	watcher *fileWatcher
+}
+
+// NewRuleCache returns a RuleCache configured by config and starts watching the files
+// compiled rules depend on. Close stops the watcher.
+func NewRuleCache(config RuleCacheConfig) *RuleCache {
+	if config.Capacity <= 0 {
+		config.Capacity = 10000
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+
+	c := &RuleCache{
+		// Compiled rules never expire, so cleanup rarely finds anything
+		Cache:  NewBiCache(config.Capacity, time.Hour),
+		config: config,
+	}
+	c.watcher = newFileWatcher(config.PollInterval, c.changed)
+	return c
+}
+
+// RuleHash returns the key a rule with the given source is cached under.
+func RuleHash(source []byte) string {
+	sum := sha256.Sum256(source)
+	return hex.EncodeToString(sum[:])
+}
+
+// Compile returns the compiled rule for source, calling compile on a miss. Concurrent misses of
+// the same rule compile it once. The compiled rule is invalidated when one of the files in
+// dependencies changes or is removed. Compile errors are returned and not cached.
+func (c *RuleCache) Compile(ctx context.Context, source []byte, dependencies []string, compile RuleCompileFunc) (interface{}, error) {
+	tags := make([]string, 0, len(dependencies))
+	for _, path := range dependencies {
+		tags = append(tags, ruleFileTag(path))
+	}
+
+	entry, _, err := c.Cache.lookupWith(ctx, RuleHash(source), func(ctx context.Context, key interface{}) (LoaderResult, error) {
+		// Watch the files before compiling, so changes to them while the rule compiles are not missed
+		c.watcher.add(dependencies...)
+		compiled, err := compile(ctx, source)
+		return LoaderResult{Value: compiled, Tags: tags}, err
+	})
+	return entry.Value, err
+}
+
+// CompileFile reads the rule at path and compiles it like Compile. The compiled rule is invalidated
+// when the rule file or one of the files in dependencies changes.
+func (c *RuleCache) CompileFile(ctx context.Context, path string, dependencies []string, compile RuleCompileFunc) (interface{}, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Compile(ctx, source, append([]string{path}, dependencies...), compile)
+}
+
+// Invalidate removes the compiled rules that depend on the file at path and returns how many were removed.
+func (c *RuleCache) Invalidate(path string) int {
+	keys := c.Cache.KeysByTag(ruleFileTag(path))
+	for _, key := range keys {
+		c.Cache.Delete(key)
+	}
+	return len(keys)
+}
+
+// Close stops the watcher and the cache.
+func (c *RuleCache) Close() {
+	c.watcher.stop()
+	c.Cache.Close()
+}
+
+// changed invalidates the rules depending on a changed file. The file is watched again
+// once a rule depending on it is compiled.
+func (c *RuleCache) changed(path string) {
+	invalidated := c.Invalidate(path)
+	if c.config.OnInvalidate != nil {
+		c.config.OnInvalidate(path, invalidated)
+	}
+}
+
+func ruleFileTag(path string) string {
+	return "file:" + path
+}
@@ -0,0 +1,82 @@
+package bicache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleCache_Compile(t *testing.T) {
+	cache := NewRuleCache(RuleCacheConfig{PollInterval: time.Hour})
+	defer cache.Close()
+
+	compiles := 0
+	compile := func(ctx context.Context, source []byte) (interface{}, error) {
+		compiles++
+		return strings.ToUpper(string(source)), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		compiled, err := cache.Compile(context.Background(), []byte("detection: a"), nil, compile)
+		if err != nil || compiled != "DETECTION: A" {
+			t.Errorf("Rule compile test failed. Expected: DETECTION: A, Got: %v, %v", compiled, err)
+		}
+	}
+	cache.Compile(context.Background(), []byte("detection: b"), nil, compile)
+	if compiles != 2 {
+		t.Errorf("Rule compile test failed. Expected: 2 compilations, Got: %v", compiles)
+	}
+	if !cache.Cache.Has(RuleHash([]byte("detection: b"))) {
+		t.Errorf("Rule compile test failed. Expected: rule cached by hash")
+	}
+}
+
+func TestRuleCache_Invalidation(t *testing.T) {
+	dir := t.TempDir()
+	rule := filepath.Join(dir, "rule.yml")
+	mapping := filepath.Join(dir, "mapping.yml")
+	os.WriteFile(rule, []byte("detection: a"), 0o644)
+	os.WriteFile(mapping, []byte("field: a"), 0o644)
+
+	invalidations := make(chan string, 2)
+	cache := NewRuleCache(RuleCacheConfig{
+		PollInterval: 10 * time.Millisecond,
+		OnInvalidate: func(path string, invalidated int) {
+			if invalidated == 1 {
+				invalidations <- path
+			}
+		},
+	})
+	defer cache.Close()
+
+	compiles := 0
+	compile := func(ctx context.Context, source []byte) (interface{}, error) {
+		compiles++
+		return string(source), nil
+	}
+	if _, err := cache.CompileFile(context.Background(), rule, []string{mapping}, compile); err != nil {
+		t.Fatalf("Rule invalidation test failed. Got: %v", err)
+	}
+
+	// Change the size so the change is seen even with a coarse modification time
+	os.WriteFile(mapping, []byte("field: changed"), 0o644)
+	select {
+	case path := <-invalidations:
+		if path != mapping {
+			t.Errorf("Rule invalidation test failed. Expected: %v, Got: %v", mapping, path)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Rule invalidation test failed. Expected: the rule to be invalidated")
+	}
+
+	cache.CompileFile(context.Background(), rule, []string{mapping}, compile)
+	if compiles != 2 {
+		t.Errorf("Rule invalidation test failed. Expected: recompiled once, Got: %v compilations", compiles)
+	}
+	if invalidated := cache.Invalidate(rule); invalidated != 1 {
+		t.Errorf("Rule invalidation test failed. Expected: 1 rule invalidated, Got: %v", invalidated)
+	}
+}
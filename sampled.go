@@ -0,0 +1,85 @@
+package bicache
+
+import (
+	"sort"
+	"time"
+)
+
+// defaultEvictionSamples is the number of entries sampled eviction compares by default.
+const defaultEvictionSamples = 5
+
+// sampled evicts the least recently accessed of a few randomly sampled entries, like Redis'
+// approximated LRU, or with frequency set the one read least often per unit of weight, like its
+// approximated LFU. It keeps no per-key state; accesses are recorded on the entries themselves and
+// in the frequency sketch. It reads the cache map, so it relies on the cache lock instead of its own.
+type sampled struct {
+	cache     *BiCache
+	samples   int
+	frequency bool
+}
+
+func (s *sampled) add(key interface{})      {}
+func (s *sampled) remove(key interface{})   {}
+func (s *sampled) setCapacity(capacity int) {}
+
+// touch records an access of key, for example when eviction gives a hot entry another chance.
+func (s *sampled) touch(key interface{}) {
+	if entry, exists := s.cache.cacheMap[key]; exists {
+		entry.touch(time.Now())
+	}
+}
+
+// victims samples entries, relying on the random start of map iteration, and returns
+// up to n of them, least frequently read per unit of weight or least recently accessed first.
+func (s *sampled) victims(n int) []interface{} {
+	type candidate struct {
+		key        interface{}
+		score      float64
+		lastAccess int64
+	}
+
+	size := s.samples
+	if n > size {
+		size = n
+	}
+	candidates := make([]candidate, 0, size)
+	for key, entry := range s.cache.cacheMap {
+		if len(candidates) >= size {
+			break
+		}
+		c := candidate{key: key, lastAccess: entry.lastAccessed().UnixNano()}
+		if s.frequency {
+			c.score = s.score(key, entry)
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].lastAccess < candidates[j].lastAccess
+	})
+
+	keys := make([]interface{}, 0, n)
+	for _, c := range candidates {
+		if len(keys) >= n {
+			break
+		}
+		keys = append(keys, c.key)
+	}
+	return keys
+}
+
+// score returns the estimated read frequency of an entry per unit of its weight.
+func (s *sampled) score(key interface{}, entry CacheEntry) float64 {
+	weight := entry.weight
+	if weight < 1 {
+		weight = 1
+	}
+	var frequency int
+	if s.cache.sketch != nil {
+		frequency = s.cache.sketch.estimate(key)
+	}
+	return float64(frequency+1) / float64(weight)
+}
@@ -0,0 +1,89 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_SampledEviction(t *testing.T) {
+	cache := NewBiCache(4, time.Minute)
+	cache.SetEvictionPolicy(SampledEviction)
+
+	// Sampling every entry makes the choice deterministic
+	cache.SetEvictionSamples(5)
+
+	for _, key := range []string{"key1", "key2", "key3", "key4"} {
+		cache.Set(key, "value", 0)
+		time.Sleep(time.Millisecond)
+	}
+	cache.Get("key1")
+	cache.Set("key5", "value", 0)
+
+	if _, found := cache.Get("key2"); found {
+		t.Errorf("SampledEviction test failed. Expected: key2 evicted")
+	}
+	if _, found := cache.Get("key1"); !found {
+		t.Errorf("SampledEviction test failed. Expected: key1 kept")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 4 {
+		t.Errorf("SampledEviction test failed. Expected: 4 entries, Got: %v", metrics.EntriesCount)
+	}
+}
+
+func TestBiCache_SampledLFUEviction(t *testing.T) {
+	cache := NewBiCache(4, time.Minute)
+	cache.SetEvictionPolicy(SampledLFUEviction)
+	cache.SetEvictionSamples(5)
+
+	for _, key := range []string{"key1", "key2", "key3", "key4"} {
+		cache.Set(key, "value", 0)
+	}
+	// key1 is the oldest but read most often, key3 is read least
+	for i := 0; i < 3; i++ {
+		cache.Get("key1")
+		cache.Get("key2")
+		cache.Get("key4")
+	}
+	cache.Set("key5", "value", 0)
+
+	if _, found := cache.Peek("key3"); found {
+		t.Errorf("SampledLFUEviction test failed. Expected: key3 evicted")
+	}
+	if _, found := cache.Peek("key1"); !found {
+		t.Errorf("SampledLFUEviction test failed. Expected: key1 kept")
+	}
+
+	// Of equally read entries, the heaviest goes first
+	cache = NewBiCache(0, time.Minute)
+	cache.SetEvictionPolicy(SampledLFUEviction)
+	cache.SetWeigher(func(key, value interface{}) int64 { return int64(len(value.(string))) })
+	cache.SetMaxWeight(10)
+	cache.Set("small", "a", 0)
+	cache.Set("large", "aaaaaaa", 0)
+	cache.Set("new", "aaa", 0)
+
+	if _, found := cache.Peek("large"); found {
+		t.Errorf("SampledLFUEviction test failed. Expected: large evicted")
+	}
+	if _, found := cache.Peek("small"); !found {
+		t.Errorf("SampledLFUEviction test failed. Expected: small kept")
+	}
+}
+
+func TestBiCache_GlobalExpirationAfterRead(t *testing.T) {
+	cache := NewBiCache(4, time.Minute)
+	cache.SetGlobalExpiration(50 * time.Millisecond)
+	cache.Set("key", "value", 0)
+
+	// Reads extend the idle time
+	time.Sleep(30 * time.Millisecond)
+	cache.Get("key")
+	time.Sleep(30 * time.Millisecond)
+
+	cache.mu.Lock()
+	cache.cleanup()
+	cache.mu.Unlock()
+	if _, found := cache.Get("key"); !found {
+		t.Errorf("GlobalExpirationAfterRead test failed. Expected: key kept")
+	}
+}
@@ -0,0 +1,76 @@
+package bicache
+
+import (
+	"context"
+	"reflect"
+)
+
+// shadowConcurrency bounds the number of shadow reads running in the background.
+const shadowConcurrency = 8
+
+// ShadowReadFunc reads key from the cache or backend being migrated away from.
+// It reports whether the key was found there.
+type ShadowReadFunc func(ctx context.Context, key interface{}) (interface{}, bool, error)
+
+// ShadowMismatch is a read whose result differs between the cache and the shadow.
+type ShadowMismatch struct {
+	Key         interface{}
+	Value       interface{}
+	Found       bool
+	ShadowValue interface{}
+	ShadowFound bool
+}
+
+// ShadowMismatchFunc is called with each mismatch found by shadow reads.
+type ShadowMismatchFunc func(mismatch ShadowMismatch)
+
+// SetShadowRead enables shadow reads for migrations: every read is repeated in the background
+// against read, and onMismatch is called when the results differ in presence or value.
+// Shadow reads are dropped while too many are running, and their errors are passed to the
+// error handler instead of being compared. A nil read disables shadow reads.
+func (c *BiCache) SetShadowRead(read ShadowReadFunc, onMismatch ShadowMismatchFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shadowRead = read
+	c.shadowMismatch = onMismatch
+}
+
+// shadow compares the result of a read of key with the shadow in the background.
+func (c *BiCache) shadow(ctx context.Context, key interface{}, value interface{}, found bool) {
+	c.mu.RLock()
+	read, onMismatch := c.shadowRead, c.shadowMismatch
+	c.mu.RUnlock()
+
+	if read == nil {
+		return
+	}
+
+	// Drop the shadow read if too many are already running
+	select {
+	case c.shadowSem <- struct{}{}:
+	default:
+		return
+	}
+
+	ctx = withoutCancel(ctx)
+	go func() {
+		defer func() { <-c.shadowSem }()
+
+		shadowValue, shadowFound, err := read(ctx, key)
+		if err != nil {
+			c.reportError("shadow", key, err)
+			return
+		}
+
+		c.metrics.ShadowReads.Add(1)
+		if found == shadowFound && (!found || reflect.DeepEqual(value, shadowValue)) {
+			return
+		}
+
+		c.metrics.ShadowMismatches.Add(1)
+		if onMismatch != nil {
+			onMismatch(ShadowMismatch{Key: key, Value: value, Found: found, ShadowValue: shadowValue, ShadowFound: shadowFound})
+		}
+	}()
+}
@@ -0,0 +1,55 @@
+package bicache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBiCache_ShadowRead(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+
+	legacy := map[interface{}]interface{}{"key1": "value1", "key2": "stale", "key3": "value3"}
+	mismatches := make(chan ShadowMismatch, 10)
+	cache.SetShadowRead(func(ctx context.Context, key interface{}) (interface{}, bool, error) {
+		value, found := legacy[key]
+		return value, found, nil
+	}, func(mismatch ShadowMismatch) {
+		mismatches <- mismatch
+	})
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+
+	cache.Get("key1")
+	select {
+	case mismatch := <-mismatches:
+		t.Errorf("ShadowRead test failed. Expected: no mismatch, Got: %+v", mismatch)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cache.Get("key2")
+	select {
+	case mismatch := <-mismatches:
+		if mismatch.Key != "key2" || mismatch.Value != "value2" || mismatch.ShadowValue != "stale" {
+			t.Errorf("ShadowRead test failed. Expected: key2 value2 vs stale, Got: %+v", mismatch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ShadowRead test failed. Expected: value mismatch")
+	}
+
+	// A key missing from the cache but present in the shadow is a mismatch too
+	cache.Get("key3")
+	select {
+	case mismatch := <-mismatches:
+		if mismatch.Found || !mismatch.ShadowFound {
+			t.Errorf("ShadowRead test failed. Expected: found only in shadow, Got: %+v", mismatch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ShadowRead test failed. Expected: presence mismatch")
+	}
+
+	if metrics := cache.GetMetrics(); metrics.ShadowReads != 3 || metrics.ShadowMismatches != 2 {
+		t.Errorf("ShadowRead test failed. Expected: 3 reads and 2 mismatches, Got: %v and %v", metrics.ShadowReads, metrics.ShadowMismatches)
+	}
+}
@@ -0,0 +1,217 @@
+package bicache
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardSelectorFunc returns the shard, in [0, shards), that stores key. Returning the same
+// shard for related keys co-locates them; spreading a hot prefix over several shards
+// reduces contention on it.
+type ShardSelectorFunc func(key interface{}, shards int) int
+
+// selectShard places key on one of shards with selector, or by its hash if selector is nil.
+func selectShard(hasher keyHasher, selector ShardSelectorFunc, key interface{}, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+
+	if selector == nil {
+		return int(hasher.hash(key) % uint64(shards))
+	}
+
+	shard := selector(key, shards) % shards
+	if shard < 0 {
+		shard += shards
+	}
+	return shard
+}
+
+// ShardedBiCache splits its entries over several BiCaches selected by a key hash, each with its
+// own lock, capacity and cleanup, so that goroutines working on different shards do not contend.
+type ShardedBiCache struct {
+	shards   []*BiCache
+	hasher   keyHasher
+	selector atomic.Value
+	// placementMu serializes changes of the shard selector
+	placementMu sync.Mutex
+}
+
+// NewShardedBiCache creates a cache of the given number of shards sharing capacity evenly.
+// A capacity of 0 doesn't limit the number of entries, and a negative capacity is treated as 0.
+func NewShardedBiCache(shards int, capacity int, cleanupInterval time.Duration) *ShardedBiCache {
+	capacity = clampCapacity(capacity)
+	if shards < 1 {
+		shards = 1
+	}
+
+	perShard := (capacity + shards - 1) / shards
+	cache := &ShardedBiCache{
+		shards: make([]*BiCache, shards),
+		hasher: newKeyHasher(),
+	}
+	for i := range cache.shards {
+		cache.shards[i] = NewBiCache(perShard, cleanupInterval)
+	}
+	return cache
+}
+
+// SetShardSelector replaces the default hash-based shard placement with selector. Out-of-range
+// results are wrapped into range, and a nil selector restores the default. It may be called while
+// the cache is in use: the live entries whose shard changes are moved to their new shards, and
+// may miss while they are moved. Writes racing with the change can be left in their old shard,
+// where they are no longer found and expire, and so are entries stored under hashed keys whose
+// original keys are not kept.
+func (c *ShardedBiCache) SetShardSelector(selector ShardSelectorFunc) {
+	c.placementMu.Lock()
+	defer c.placementMu.Unlock()
+
+	c.selector.Store(selector)
+	for i, shard := range c.shards {
+		moved := shard.takeEntries(func(key interface{}) bool {
+			return c.shardIndex(key) != i
+		})
+		for key, entry := range moved {
+			c.Shard(key).adoptEntry(key, entry)
+		}
+	}
+}
+
+// shardIndex returns the index of the shard that stores key.
+func (c *ShardedBiCache) shardIndex(key interface{}) int {
+	selector, _ := c.selector.Load().(ShardSelectorFunc)
+	return selectShard(c.hasher, selector, key, len(c.shards))
+}
+
+// takeEntries removes the live entries whose keys move reports true for and returns them by
+// their original keys, for another shard to adopt. Entries whose original key is unknown stay.
+func (c *BiCache) takeEntries(move func(key interface{}) bool) map[interface{}]CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	taken := make(map[interface{}]CacheEntry)
+	for stored, entry := range c.cacheMap {
+		key := c.externalKey(stored)
+		if _, hashed := key.(HashedKey); hashed || !c.live(key, entry, now) || !move(key) {
+			continue
+		}
+		c.dropEntry(stored)
+		taken[key] = entry
+	}
+	return taken
+}
+
+// adoptEntry stores an entry taken from another shard, unless key was written here meanwhile.
+func (c *BiCache) adoptEntry(key interface{}, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed.Load() {
+		return
+	}
+	if _, exists := c.cacheMap[c.mapKey(key)]; exists {
+		return
+	}
+
+	// Generations are per shard, a new one keeps the entry from being treated as invalidated here
+	c.generation++
+	entry.generation = c.generation
+	entry.memory = 0
+	c.storeEntry(key, entry)
+	delete(c.negatives, key)
+	if c.overCapacity() {
+		c.cleanup()
+		c.evict(key)
+	}
+}
+
+// Shards returns the shards, for configuration and features without a sharded variant.
+// Settings such as policies and handlers have to be applied to every shard.
+func (c *ShardedBiCache) Shards() []*BiCache {
+	return c.shards
+}
+
+// Shard returns the shard that stores key.
+func (c *ShardedBiCache) Shard(key interface{}) *BiCache {
+	return c.shards[c.shardIndex(key)]
+}
+
+func (c *ShardedBiCache) Get(key interface{}) (interface{}, bool) {
+	return c.Shard(key).Get(key)
+}
+
+// GetEntry is the sharded variant of BiCache.GetEntry.
+func (c *ShardedBiCache) GetEntry(key interface{}) (CacheEntry, bool) {
+	return c.Shard(key).GetEntry(key)
+}
+
+// GetWithError is the sharded variant of BiCache.GetWithError.
+func (c *ShardedBiCache) GetWithError(key interface{}) (interface{}, error) {
+	return c.Shard(key).GetWithError(key)
+}
+
+// GetContext is the sharded variant of BiCache.GetContext.
+func (c *ShardedBiCache) GetContext(ctx context.Context, key interface{}) (interface{}, bool) {
+	return c.Shard(key).GetContext(ctx, key)
+}
+
+func (c *ShardedBiCache) Set(key interface{}, value interface{}, expiration time.Duration) {
+	c.Shard(key).Set(key, value, expiration)
+}
+
+// SetWithOptions is the sharded variant of BiCache.SetWithOptions.
+func (c *ShardedBiCache) SetWithOptions(key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) error {
+	return c.Shard(key).SetWithOptions(key, value, expiration, opts...)
+}
+
+// SetContext is the sharded variant of BiCache.SetContext.
+func (c *ShardedBiCache) SetContext(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) error {
+	return c.Shard(key).SetContext(ctx, key, value, expiration, opts...)
+}
+
+func (c *ShardedBiCache) Delete(key interface{}) {
+	c.Shard(key).Delete(key)
+}
+
+// GetMetrics returns the sum of the metrics of all shards.
+func (c *ShardedBiCache) GetMetrics() CacheMetrics {
+	var metrics CacheMetrics
+	for _, shard := range c.shards {
+		metrics = metrics.add(shard.GetMetrics())
+	}
+	return metrics
+}
+
+// add returns the field-wise sum of two metrics. All metrics are counters or totals, except for
+// flags, which are set if they are set in either.
+func (m CacheMetrics) add(other CacheMetrics) CacheMetrics {
+	sum := reflect.ValueOf(&m).Elem()
+	addend := reflect.ValueOf(other)
+	for i := 0; i < sum.NumField(); i++ {
+		field := sum.Field(i)
+		if field.Kind() == reflect.Bool {
+			field.SetBool(field.Bool() || addend.Field(i).Bool())
+			continue
+		}
+		field.SetInt(field.Int() + addend.Field(i).Int())
+	}
+	return m
+}
+
+// SetLoader makes Get read through to loader on misses of any key in every shard.
+func (c *ShardedBiCache) SetLoader(loader LoaderFunc) {
+	for _, shard := range c.shards {
+		shard.SetLoader(loader)
+	}
+}
+
+// SetRefreshAhead sets the refresh-ahead window of every shard.
+func (c *ShardedBiCache) SetRefreshAhead(window time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetRefreshAhead(window)
+	}
+}
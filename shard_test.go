@@ -0,0 +1,113 @@
+package bicache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectShard(t *testing.T) {
+	hasher := newKeyHasher()
+
+	// The default placement is stable and in range
+	shard := selectShard(hasher, nil, "key", 8)
+	if shard < 0 || shard >= 8 || selectShard(hasher, nil, "key", 8) != shard {
+		t.Errorf("ShardSelector test (default) failed. Got: %v", shard)
+	}
+
+	// Related keys can be co-located by their prefix
+	selector := func(key interface{}, shards int) int {
+		if strings.HasPrefix(key.(string), "order:") {
+			return 3
+		}
+		return -1
+	}
+	if a, b := selectShard(hasher, selector, "order:1", 8), selectShard(hasher, selector, "order:2", 8); a != 3 || b != 3 {
+		t.Errorf("ShardSelector test failed. Expected: 3 and 3, Got: %v and %v", a, b)
+	}
+
+	// Out-of-range results are wrapped into range
+	if shard := selectShard(hasher, selector, "user:1", 8); shard != 7 {
+		t.Errorf("ShardSelector test (wrap) failed. Expected: 7, Got: %v", shard)
+	}
+}
+
+func TestShardedBiCache(t *testing.T) {
+	cache := NewShardedBiCache(4, 8, time.Minute)
+	if len(cache.Shards()) != 4 {
+		t.Fatalf("ShardedBiCache test failed. Expected: 4 shards, Got: %v", len(cache.Shards()))
+	}
+
+	for i := 0; i < 8; i++ {
+		cache.Set(i, i*10, 0)
+	}
+	for i := 0; i < 8; i++ {
+		if value, found := cache.Get(i); found && value != i*10 {
+			t.Errorf("ShardedBiCache test failed. Expected: %v, Got: %v", i*10, value)
+		}
+	}
+
+	// Each key lives only in its own shard
+	for i, shard := range cache.Shards() {
+		for key := 0; key < 8; key++ {
+			if _, found := shard.Get(key); found && cache.Shard(key) != shard {
+				t.Errorf("ShardedBiCache test failed. Key %v found in shard %v", key, i)
+			}
+		}
+	}
+
+	cache.Delete(3)
+	if _, found := cache.Get(3); found {
+		t.Errorf("ShardedBiCache test (delete) failed. Expected: not found")
+	}
+
+	metrics := cache.GetMetrics()
+	var entries int64
+	for _, shard := range cache.Shards() {
+		entries += shard.GetMetrics().EntriesCount
+	}
+	if metrics.EntriesCount != entries || metrics.SetSuccess != 8 {
+		t.Errorf("ShardedBiCache test (metrics) failed. Expected: %v entries and 8 sets, Got: %v and %v", entries, metrics.EntriesCount, metrics.SetSuccess)
+	}
+}
+
+func TestShardedBiCache_ShardSelector(t *testing.T) {
+	cache := NewShardedBiCache(4, 100, time.Minute)
+	cache.SetShardSelector(func(key interface{}, shards int) int {
+		return 2
+	})
+
+	cache.Set("key1", "value1", 0)
+	if _, found := cache.Shards()[2].Get("key1"); !found {
+		t.Errorf("ShardedBiCache selector test failed. Expected: key1 in shard 2")
+	}
+}
+
+func TestShardedBiCache_ShardSelectorMovesEntries(t *testing.T) {
+	cache := NewShardedBiCache(4, 100, time.Minute)
+	for i := 0; i < 20; i++ {
+		cache.Set(i, i, 0)
+	}
+
+	// Changing the selector while the cache is in use moves the entries to their new shard
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			cache.Get(i % 20)
+		}
+	}()
+	cache.SetShardSelector(func(key interface{}, shards int) int {
+		return 1
+	})
+	<-done
+
+	for i := 0; i < 20; i++ {
+		if value, found := cache.Shards()[1].Get(i); !found || value != i {
+			t.Errorf("ShardedBiCache selector move test failed. Expected: %v in shard 1, Got: %v", i, value)
+		}
+	}
+	if entries := cache.GetMetrics().EntriesCount; entries != 20 {
+		t.Errorf("ShardedBiCache selector move test failed. Expected: 20 entries, Got: %v", entries)
+	}
+}
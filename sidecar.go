@@ -0,0 +1,198 @@
+package bicache
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// sidecarMaxBodySize bounds the values written over HTTP when the cache has no maximum value size.
+const sidecarMaxBodySize = 32 << 20
+
+// Sidecar serves a cache over HTTP, for running it as a sidecar container next to an application:
+//
+//	GET, PUT and DELETE /cache/{key}  read, write and delete string keys; PUT takes a ttl parameter
+//	GET /metrics                      the cache metrics as JSON
+//	GET /healthz                      200 while the process is up
+//	GET /readyz                       200 once warmup completed, 503 before
+//
+// Values written over HTTP are stored as []byte. Bodies larger than the maximum value size of the
+// cache, or 32 MiB without one, are refused with 413. Reads answer 404 only on misses, failed loads
+// are server errors. With an auth token set, /cache/ and /metrics require an
+// "Authorization: Bearer <token>" header and failed attempts are reported to the cache's audit handler.
+type Sidecar struct {
+	cache     *BiCache
+	ready     atomic.Bool
+	mux       *http.ServeMux
+	authToken atomic.Value
+}
+
+// NewSidecar returns a sidecar serving cache. It is not ready until Warmup completed.
+func NewSidecar(cache *BiCache) *Sidecar {
+	s := &Sidecar{cache: cache, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/cache/", s.authorized(s.serveCache))
+	s.mux.HandleFunc("/metrics", s.authorized(s.serveMetrics))
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	s.mux.HandleFunc("/readyz", s.serveReady)
+	return s
+}
+
+// Warmup runs warmup, for example importing a snapshot or prefetching keys, and marks the sidecar
+// ready if it succeeds. A nil warmup only marks it ready.
+func (s *Sidecar) Warmup(ctx context.Context, warmup func(ctx context.Context, cache *BiCache) error) error {
+	if warmup != nil {
+		if err := warmup(ctx, s.cache); err != nil {
+			return err
+		}
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+// SetAuthToken requires requests to /cache/ and /metrics to carry token as a bearer token.
+// An empty token turns authentication off.
+func (s *Sidecar) SetAuthToken(token string) {
+	s.authToken.Store(token)
+}
+
+// authorized wraps handler to refuse requests without the auth token, if one is set.
+func (s *Sidecar) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, _ := s.authToken.Load().(string)
+		if token == "" {
+			handler(w, r)
+			return
+		}
+
+		given, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			reason := "invalid token"
+			if !found {
+				reason = "missing token"
+			}
+			s.cache.audit(AuditAuthFailed, nil, fmt.Sprintf("%s %s: %s", r.Method, r.URL.Path, reason), r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// Ready reports whether warmup completed.
+func (s *Sidecar) Ready() bool {
+	return s.ready.Load()
+}
+
+func (s *Sidecar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Sidecar) serveReady(w http.ResponseWriter, r *http.Request) {
+	if !s.Ready() {
+		http.Error(w, "warming up", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Sidecar) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.cache.GetMetrics())
+}
+
+func (s *Sidecar) serveCache(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/cache/")
+	if key == "" {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, err := s.cache.GetWithError(key)
+		if err != nil {
+			// Only misses are 404, failed loads and a closed cache are errors of the sidecar
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, ErrNotFound):
+				status = http.StatusNotFound
+			case errors.Is(err, ErrClosed), errors.Is(err, ErrLoadShed):
+				status = http.StatusServiceUnavailable
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		writeSidecarValue(w, value)
+	case http.MethodPut:
+		var ttl time.Duration
+		if param := r.URL.Query().Get("ttl"); param != "" {
+			var err error
+			if ttl, err = time.ParseDuration(param); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize()))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, ErrValueTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.cache.SetContext(r.Context(), key, body, ttl); errors.Is(err, ErrValueTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.cache.DeleteContext(r.Context(), key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// maxBodySize returns the size of the largest body read from a PUT: the maximum value size of
+// the cache, or sidecarMaxBodySize without one.
+func (s *Sidecar) maxBodySize() int64 {
+	s.cache.mu.RLock()
+	defer s.cache.mu.RUnlock()
+
+	if s.cache.maxValueSize > 0 {
+		return s.cache.maxValueSize
+	}
+	return sidecarMaxBodySize
+}
+
+// writeSidecarValue writes bytes and strings as is and other values as JSON.
+func writeSidecarValue(w http.ResponseWriter, value interface{}) {
+	switch v := value.(type) {
+	case []byte:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(v)
+	case string:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, v)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			http.Error(w, fmt.Sprintf("bicache: encoding value: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSidecar(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	sidecar := NewSidecar(cache)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		sidecar.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	if code := serve("GET", "/readyz", "").Code; code != http.StatusServiceUnavailable {
+		t.Errorf("Sidecar test failed. Expected: 503 before warmup, Got: %v", code)
+	}
+
+	err := sidecar.Warmup(context.Background(), func(ctx context.Context, cache *BiCache) error {
+		cache.Set("warm", "value", 0)
+		return nil
+	})
+	if err != nil || serve("GET", "/readyz", "").Code != http.StatusOK {
+		t.Errorf("Sidecar test failed. Expected: ready after warmup, Got: %v", err)
+	}
+
+	if body := serve("GET", "/cache/warm", "").Body.String(); body != "value" {
+		t.Errorf("Sidecar test (warm) failed. Expected: value, Got: %v", body)
+	}
+
+	serve("PUT", "/cache/key1?ttl=1m", "payload")
+	if body := serve("GET", "/cache/key1", "").Body.String(); body != "payload" {
+		t.Errorf("Sidecar test (put) failed. Expected: payload, Got: %v", body)
+	}
+
+	serve("DELETE", "/cache/key1", "")
+	if code := serve("GET", "/cache/key1", "").Code; code != http.StatusNotFound {
+		t.Errorf("Sidecar test (delete) failed. Expected: 404, Got: %v", code)
+	}
+}
+
+func TestSidecar_AuthToken(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	audits := make(chan AuditEvent, 1)
+	cache.SetAuditHandler(func(event AuditEvent) {
+		audits <- event
+	})
+	sidecar := NewSidecar(cache)
+	sidecar.SetAuthToken("secret")
+
+	request := httptest.NewRequest("PUT", "/cache/key1", strings.NewReader("value"))
+	recorder := httptest.NewRecorder()
+	sidecar.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Sidecar auth test failed. Expected: 401 without a token, Got: %v", recorder.Code)
+	}
+	select {
+	case event := <-audits:
+		if event.Type != AuditAuthFailed || event.Source != request.RemoteAddr {
+			t.Errorf("Sidecar auth test failed. Got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Sidecar auth test failed. Expected: an audit event")
+	}
+
+	request = httptest.NewRequest("PUT", "/cache/key1", strings.NewReader("value"))
+	request.Header.Set("Authorization", "Bearer secret")
+	recorder = httptest.NewRecorder()
+	sidecar.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Sidecar auth test failed. Expected: 204 with the token, Got: %v", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	sidecar.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Sidecar auth test failed. Expected: health check without a token, Got: %v", recorder.Code)
+	}
+}
+
+func TestSidecar_Errors(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetMaxValueSize(8)
+	cache.RegisterLoader("failing", func(key interface{}) (interface{}, time.Duration, error) {
+		return nil, 0, errors.New("backend unavailable")
+	})
+	sidecar := NewSidecar(cache)
+
+	serve := func(method, path, body string) int {
+		recorder := httptest.NewRecorder()
+		sidecar.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder.Code
+	}
+
+	if code := serve("PUT", "/cache/key1", strings.Repeat("x", 1024)); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Sidecar errors test failed. Expected: 413 for a large body, Got: %v", code)
+	}
+	if code := serve("GET", "/cache/missing", ""); code != http.StatusNotFound {
+		t.Errorf("Sidecar errors test failed. Expected: 404 for a miss, Got: %v", code)
+	}
+	if code := serve("GET", "/cache/failing", ""); code != http.StatusInternalServerError {
+		t.Errorf("Sidecar errors test failed. Expected: 500 for a failed load, Got: %v", code)
+	}
+
+	cache.Close()
+	if code := serve("GET", "/cache/missing", ""); code != http.StatusServiceUnavailable {
+		t.Errorf("Sidecar errors test failed. Expected: 503 once closed, Got: %v", code)
+	}
+}
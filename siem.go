@@ -0,0 +1,359 @@
+package bicache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SIEMFormat selects how a SIEMSink formats audit events.
+type SIEMFormat int
+
+const (
+	// SIEMFormatCEF formats events in ArcSight Common Event Format.
+	SIEMFormatCEF SIEMFormat = iota
+	// SIEMFormatLEEF formats events in IBM QRadar Log Event Extended Format 1.0.
+	SIEMFormatLEEF
+	// SIEMFormatJSON formats events as the JSON encoding of AuditEvent.
+	SIEMFormatJSON
+)
+
+// SIEMConfig configures a SIEMSink. Exactly one of SyslogAddress and URL must be set.
+type SIEMConfig struct {
+	Format SIEMFormat
+	// SyslogNetwork is "udp" or "tcp", "udp" if not set. TCP messages use octet-counting framing.
+	SyslogNetwork string
+	// SyslogAddress is the host:port of a syslog receiver. Events are sent as RFC 5424 messages.
+	SyslogAddress string
+	// URL receives each event in a POST request.
+	URL string
+	// Client sends the HTTP requests, a client with Timeout if not set.
+	Client *http.Client
+	// Timeout bounds connecting to the syslog receiver, each syslog write and, unless Client
+	// is set, each HTTP request, 5 seconds if not set.
+	Timeout time.Duration
+	// Vendor, Product and Version identify the device in CEF and LEEF headers,
+	// "bicache", "bicache" and "1.0" if not set.
+	Vendor  string
+	Product string
+	Version string
+	// Hostname is reported in syslog messages, os.Hostname() if not set.
+	Hostname string
+	// QueueSize is the number of events that may wait to be sent, 1024 if not set.
+	QueueSize int
+	// OnError is called with events that could not be sent.
+	OnError func(event AuditEvent, err error)
+}
+
+// SIEMSink forwards audit events to a SIEM over syslog or HTTP.
+type SIEMSink struct {
+	config    SIEMConfig
+	events    chan AuditEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+	dropped   int64
+	conn      net.Conn
+}
+
+// NewSIEMSink returns a sink forwarding events as configured.
+func NewSIEMSink(config SIEMConfig) (*SIEMSink, error) {
+	if (config.SyslogAddress == "") == (config.URL == "") {
+		return nil, fmt.Errorf("bicache: exactly one of SyslogAddress and URL must be set")
+	}
+	if config.SyslogNetwork == "" {
+		config.SyslogNetwork = "udp"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: config.Timeout}
+	}
+	if config.Vendor == "" {
+		config.Vendor = "bicache"
+	}
+	if config.Product == "" {
+		config.Product = "bicache"
+	}
+	if config.Version == "" {
+		config.Version = "1.0"
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+
+	sink := &SIEMSink{
+		config: config,
+		events: make(chan AuditEvent, config.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go sink.run()
+
+	return sink, nil
+}
+
+// Handle queues an audit event, it can be registered with SetAuditHandler.
+// Events are dropped if the queue is full.
+func (s *SIEMSink) Handle(event AuditEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		atomic.AddInt64(&s.dropped, 1)
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full or the sink was closed.
+func (s *SIEMSink) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Close stops accepting events and sends the queued ones.
+func (s *SIEMSink) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		s.mu.Unlock()
+		<-s.done
+
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	})
+}
+
+func (s *SIEMSink) run() {
+	defer close(s.done)
+
+	for event := range s.events {
+		if err := s.send(event); err != nil && s.config.OnError != nil {
+			s.config.OnError(event, err)
+		}
+	}
+}
+
+func (s *SIEMSink) send(event AuditEvent) error {
+	message, err := s.Format(event)
+	if err != nil {
+		return err
+	}
+	if s.config.URL != "" {
+		return s.post(message)
+	}
+	return s.syslog(event, message)
+}
+
+// Format returns event in the configured format.
+func (s *SIEMSink) Format(event AuditEvent) ([]byte, error) {
+	switch s.config.Format {
+	case SIEMFormatLEEF:
+		return []byte(s.leef(event)), nil
+	case SIEMFormatJSON:
+		return json.Marshal(event)
+	default:
+		return []byte(s.cef(event)), nil
+	}
+}
+
+// cef formats event as CEF:Version|Vendor|Product|Version|SignatureID|Name|Severity|Extension.
+func (s *SIEMSink) cef(event AuditEvent) string {
+	header := strings.Join([]string{
+		"CEF:0",
+		cefHeader(s.config.Vendor),
+		cefHeader(s.config.Product),
+		cefHeader(s.config.Version),
+		cefHeader(string(event.Type)),
+		cefHeader(auditName(event.Type)),
+		strconv.Itoa(auditSeverity(event.Type)),
+	}, "|")
+
+	extension := []string{"rt=" + strconv.FormatInt(event.Time.UnixMilli(), 10)}
+	if host := sourceHost(event.Source); host != "" {
+		extension = append(extension, "src="+cefExtension(host))
+	}
+	if event.Key != "" {
+		extension = append(extension, "cs1Label=key", "cs1="+cefExtension(event.Key))
+	}
+	if event.Reason != "" {
+		extension = append(extension, "msg="+cefExtension(event.Reason))
+	}
+	return header + "|" + strings.Join(extension, " ")
+}
+
+// leef formats event as LEEF:1.0|Vendor|Product|Version|EventID| followed by tab separated attributes.
+func (s *SIEMSink) leef(event AuditEvent) string {
+	header := strings.Join([]string{
+		"LEEF:1.0",
+		leefHeader(s.config.Vendor),
+		leefHeader(s.config.Product),
+		leefHeader(s.config.Version),
+		leefHeader(string(event.Type)),
+	}, "|")
+
+	attributes := []string{
+		"devTime=" + event.Time.UTC().Format("Jan 02 2006 15:04:05"),
+		"sev=" + strconv.Itoa(auditSeverity(event.Type)),
+	}
+	if host := sourceHost(event.Source); host != "" {
+		attributes = append(attributes, "src="+leefValue(host))
+	}
+	if event.Key != "" {
+		attributes = append(attributes, "key="+leefValue(event.Key))
+	}
+	if event.Reason != "" {
+		attributes = append(attributes, "reason="+leefValue(event.Reason))
+	}
+	return header + "|" + strings.Join(attributes, "\t")
+}
+
+// syslog sends message as an RFC 5424 message with facility log audit.
+func (s *SIEMSink) syslog(event AuditEvent, message []byte) error {
+	// Facility 13 (log audit), severity warning for failed authentication and notice otherwise
+	severity := 5
+	if event.Type == AuditAuthFailed {
+		severity = 4
+	}
+	hostname := s.config.Hostname
+	if hostname == "" {
+		hostname = "-"
+	}
+	line := fmt.Sprintf("<%d>1 %s %s bicache %d %s - %s",
+		13*8+severity, event.Time.UTC().Format(time.RFC3339Nano), hostname, os.Getpid(), event.Type, message)
+	if s.config.SyslogNetwork == "tcp" {
+		line = strconv.Itoa(len(line)) + " " + line
+	}
+
+	// A connection reused from earlier events may have been closed by the receiver,
+	// retry once on a new connection
+	reused := s.conn != nil
+	err := s.write([]byte(line))
+	if err != nil && reused {
+		err = s.write([]byte(line))
+	}
+	return err
+}
+
+// write sends data to the syslog receiver, connecting first if needed. The connection is
+// dropped when the write fails or does not complete within the timeout.
+func (s *SIEMSink) write(data []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.config.SyslogNetwork, s.config.SyslogAddress, s.config.Timeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(s.config.Timeout))
+	if _, err := s.conn.Write(data); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *SIEMSink) post(message []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(message))
+	if err != nil {
+		return err
+	}
+	contentType := "text/plain; charset=utf-8"
+	if s.config.Format == SIEMFormatJSON {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("bicache: SIEM endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// auditName returns a readable name of an audit event type.
+func auditName(auditType AuditType) string {
+	switch auditType {
+	case AuditPolicyRejected:
+		return "Write rejected by cache policy"
+	case AuditValidationFailed:
+		return "Write failed validation"
+	case AuditReadOnlyViolation:
+		return "Write to read-only entry"
+	case AuditValueTooLarge:
+		return "Oversized value"
+	case AuditAuthFailed:
+		return "Authentication failed"
+	default:
+		return string(auditType)
+	}
+}
+
+// auditSeverity returns the CEF and LEEF severity, from 0 to 10, of an audit event type.
+func auditSeverity(auditType AuditType) int {
+	switch auditType {
+	case AuditAuthFailed:
+		return 7
+	case AuditReadOnlyViolation, AuditValueTooLarge:
+		return 5
+	default:
+		return 3
+	}
+}
+
+// sourceHost returns the host of a remote address, or the address if it has no port.
+func sourceHost(source string) string {
+	if host, _, err := net.SplitHostPort(source); err == nil {
+		return host
+	}
+	return source
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+	leefHeaderEscaper   = strings.NewReplacer("|", " ", "\t", " ", "\n", " ", "\r", " ")
+	leefValueEscaper    = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+func cefHeader(value string) string {
+	return cefHeaderEscaper.Replace(value)
+}
+
+func cefExtension(value string) string {
+	return cefExtensionEscaper.Replace(value)
+}
+
+func leefHeader(value string) string {
+	return leefHeaderEscaper.Replace(value)
+}
+
+func leefValue(value string) string {
+	return leefValueEscaper.Replace(value)
+}
@@ -0,0 +1,241 @@
+package bicache
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBiCache_AuditHandler(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	events := make(chan AuditEvent, 4)
+	cache.SetAuditHandler(func(event AuditEvent) {
+		events <- event
+	})
+	cache.SetMaxValueSize(8)
+	cache.SetCachePolicy(func(key interface{}, entry CacheEntry) bool {
+		return key != "denied"
+	})
+
+	if err := cache.SetWithOptions("large", "more than eight bytes", 0); err != ErrValueTooLarge {
+		t.Errorf("Audit test failed. Expected: ErrValueTooLarge, Got: %v", err)
+	}
+	cache.Set("denied", "value", 0)
+
+	seen := make(map[AuditType]AuditEvent)
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-events:
+			seen[event.Type] = event
+		case <-time.After(time.Second):
+			t.Fatalf("Audit test failed. Expected: 2 events, Got: %v", seen)
+		}
+	}
+	if seen[AuditValueTooLarge].Key != "large" || seen[AuditPolicyRejected].Key != "denied" {
+		t.Errorf("Audit test failed. Got: %+v", seen)
+	}
+}
+
+func TestBiCache_AuditHandlerPanic(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	errs := make(chan error, 1)
+	cache.SetErrorHandler(func(op string, key interface{}, err error) {
+		if op == "event" {
+			errs <- err
+		}
+	})
+	release := make(chan struct{})
+	cache.SetAuditHandler(func(event AuditEvent) {
+		<-release
+		panic("handler failed")
+	})
+
+	cache.SetWithOptions("locked", "value", 0, WithReadOnly())
+	cache.Set("locked", "other", 0)
+
+	// Close waits for the handler, whose panic is reported instead of crashing the program
+	closed := make(chan struct{})
+	go func() {
+		cache.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatalf("Audit panic test failed. Expected: Close to wait for the handler")
+	case <-time.After(time.Millisecond * 20):
+	}
+	close(release)
+	<-closed
+
+	select {
+	case err := <-errs:
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Errorf("Audit panic test failed. Expected: a PanicError, Got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Audit panic test failed. Expected: the panic reported")
+	}
+}
+
+func TestSIEMSink_Format(t *testing.T) {
+	event := AuditEvent{
+		Type:   AuditAuthFailed,
+		Key:    "a=b",
+		Reason: "GET /cache/x: invalid token",
+		Source: "10.0.0.1:5555",
+		Time:   time.UnixMilli(1700000000000),
+	}
+
+	sink, _ := NewSIEMSink(SIEMConfig{URL: "http://localhost", Format: SIEMFormatCEF})
+	defer sink.Close()
+	cef, _ := sink.Format(event)
+	expected := `CEF:0|bicache|bicache|1.0|auth_failed|Authentication failed|7|rt=1700000000000 src=10.0.0.1 cs1Label=key cs1=a\=b msg=GET /cache/x: invalid token`
+	if string(cef) != expected {
+		t.Errorf("CEF format test failed.\nExpected: %s\nGot:      %s", expected, cef)
+	}
+
+	sink.config.Format = SIEMFormatLEEF
+	leef, _ := sink.Format(event)
+	if !strings.HasPrefix(string(leef), "LEEF:1.0|bicache|bicache|1.0|auth_failed|devTime=") || !strings.Contains(string(leef), "\tsrc=10.0.0.1\tkey=a=b\t") {
+		t.Errorf("LEEF format test failed. Got: %q", leef)
+	}
+}
+
+func TestSIEMSink_Syslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSIEMSink(SIEMConfig{SyslogAddress: conn.LocalAddr().String(), Format: SIEMFormatJSON, Hostname: "host"})
+	if err != nil {
+		t.Fatalf("SIEM sink test failed. Unexpected error: %v", err)
+	}
+	sink.Handle(AuditEvent{Type: AuditValueTooLarge, Key: "key1", Time: time.Now()})
+	sink.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 2048)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("SIEM sink test failed. Expected: a syslog message, Got: %v", err)
+	}
+
+	message := string(buf[:n])
+	if !strings.HasPrefix(message, "<109>1 ") || !strings.Contains(message, " host bicache ") || !strings.Contains(message, ` value_too_large - {"type":"value_too_large","key":"key1"`) {
+		t.Errorf("SIEM sink test failed. Got: %s", message)
+	}
+}
+
+func TestSIEMSink_HTTP(t *testing.T) {
+	received := make(chan AuditEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event AuditEvent
+		json.Unmarshal(body, &event)
+		received <- event
+	}))
+	defer server.Close()
+
+	sink, _ := NewSIEMSink(SIEMConfig{URL: server.URL, Format: SIEMFormatJSON})
+	sink.Handle(AuditEvent{Type: AuditReadOnlyViolation, Key: "key1", Time: time.Now()})
+	sink.Close()
+
+	select {
+	case event := <-received:
+		if event.Type != AuditReadOnlyViolation || event.Key != "key1" {
+			t.Errorf("SIEM sink test failed. Got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("SIEM sink test failed. Expected: a request")
+	}
+
+	if _, err := NewSIEMSink(SIEMConfig{}); err == nil {
+		t.Errorf("SIEM sink test failed. Expected: error without a destination")
+	}
+}
+
+func TestSIEMSink_SyslogReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("TCP not available: %v", err)
+	}
+	defer listener.Close()
+
+	messages := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 2048)
+				n, _ := conn.Read(buf)
+				messages <- string(buf[:n])
+			}()
+		}
+	}()
+
+	sink, _ := NewSIEMSink(SIEMConfig{SyslogNetwork: "tcp", SyslogAddress: listener.Addr().String(), Format: SIEMFormatJSON})
+	defer sink.Close()
+
+	if err := sink.send(AuditEvent{Type: AuditValueTooLarge, Key: "key1", Time: time.Now()}); err != nil {
+		t.Fatalf("SIEM reconnect test failed. Unexpected error: %v", err)
+	}
+	// A failed write must reconnect instead of losing the event
+	sink.conn.Close()
+	if err := sink.send(AuditEvent{Type: AuditValueTooLarge, Key: "key2", Time: time.Now()}); err != nil {
+		t.Fatalf("SIEM reconnect test failed. Expected: the event sent on a new connection, Got: %v", err)
+	}
+
+	// The messages arrive on different connections, in any order
+	var received string
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-messages:
+			received += message
+		case <-time.After(time.Second):
+			t.Fatalf("SIEM reconnect test failed. Expected: 2 messages, Got: %d", i)
+		}
+	}
+	if !strings.Contains(received, `"key":"key1"`) || !strings.Contains(received, `"key":"key2"`) {
+		t.Errorf("SIEM reconnect test failed. Got: %s", received)
+	}
+}
+
+func TestSIEMSink_HTTPTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	failed := make(chan error, 1)
+	sink, _ := NewSIEMSink(SIEMConfig{
+		URL:     server.URL,
+		Timeout: 50 * time.Millisecond,
+		OnError: func(event AuditEvent, err error) { failed <- err },
+	})
+	sink.Handle(AuditEvent{Type: AuditAuthFailed, Time: time.Now()})
+
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Errorf("SIEM timeout test failed. Expected: an error")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("SIEM timeout test failed. Expected: the stalled request to time out")
+	}
+	sink.Close()
+}
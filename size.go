@@ -0,0 +1,72 @@
+package bicache
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// maxSizeDepth bounds how deep estimateSize follows pointers and containers.
+const maxSizeDepth = 8
+
+// entryOverhead approximates the memory used per entry besides its key and contents:
+// the entry itself, its map slot and the eviction bookkeeping.
+const entryOverhead = int64(unsafe.Sizeof(CacheEntry{})) + 64
+
+// entryMemory estimates the memory used by a stored entry, including its key.
+func entryMemory(key interface{}, entry CacheEntry) int64 {
+	return entryOverhead + estimateSize(key) + estimateSize(entry.Value) +
+		estimateSize(entry.Metadata) + estimateSize(entry.Tags) + int64(len(entry.Token)+len(entry.Writer))
+}
+
+// estimateSize returns a rough estimate of the memory used by value in bytes.
+func estimateSize(value interface{}) int64 {
+	switch val := value.(type) {
+	case nil:
+		return 0
+	case []byte:
+		return int64(len(val))
+	case string:
+		return int64(len(val))
+	}
+	return estimateValueSize(reflect.ValueOf(value), 0)
+}
+
+func estimateValueSize(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+
+	size := int64(v.Type().Size())
+	if depth >= maxSizeDepth {
+		return size
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		size += int64(v.Len())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i), depth+1)
+		}
+	case reflect.Array:
+		size = 0
+		for i := 0; i < v.Len(); i++ {
+			size += estimateValueSize(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateValueSize(iter.Key(), depth+1) + estimateValueSize(iter.Value(), depth+1)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			size += estimateValueSize(v.Elem(), depth+1)
+		}
+	case reflect.Struct:
+		size = 0
+		for i := 0; i < v.NumField(); i++ {
+			size += estimateValueSize(v.Field(i), depth+1)
+		}
+	}
+	return size
+}
@@ -0,0 +1,179 @@
+package bicache
+
+import "sync"
+
+const (
+	// sketchDepth is the number of counter rows of the count-min sketch.
+	sketchDepth = 4
+	// maxSketchCount is the value at which counters saturate.
+	maxSketchCount = 15
+)
+
+// FrequencySketchStats describes the state of the frequency sketch.
+type FrequencySketchStats struct {
+	Width      int
+	Depth      int
+	Additions  int64
+	Resets     int64
+	FillRatio  float64
+	Saturation float64
+}
+
+// countMinSketch estimates access frequencies with saturating counters.
+// All counters are halved once the number of additions reaches the sample size, so old accesses age out.
+type countMinSketch struct {
+	mu         sync.Mutex
+	hasher     keyHasher
+	counters   [sketchDepth][]uint8
+	mask       uint64
+	sampleSize int64
+	additions  int64
+	resets     int64
+}
+
+func newCountMinSketch(width int, hasher keyHasher) *countMinSketch {
+	// Round the width up to a power of two so indexes can be masked
+	size := 16
+	for size < width {
+		size *= 2
+	}
+
+	sketch := &countMinSketch{
+		hasher:     hasher,
+		mask:       uint64(size - 1),
+		sampleSize: int64(size) * 10,
+	}
+	for i := range sketch.counters {
+		sketch.counters[i] = make([]uint8, size)
+	}
+	return sketch
+}
+
+// indexes returns the counter index of key in each row.
+func (s *countMinSketch) indexes(key interface{}) [sketchDepth]uint64 {
+	hash := s.hasher.hash(key)
+	h1, h2 := hash, hash>>32|hash<<32
+
+	var indexes [sketchDepth]uint64
+	for i := range indexes {
+		indexes[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+	return indexes
+}
+
+// add records an access of key.
+func (s *countMinSketch) add(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for row, index := range s.indexes(key) {
+		if s.counters[row][index] < maxSketchCount {
+			s.counters[row][index]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+// estimate returns the estimated access frequency of key.
+func (s *countMinSketch) estimate(key interface{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	min := uint8(maxSketchCount)
+	for row, index := range s.indexes(key) {
+		if s.counters[row][index] < min {
+			min = s.counters[row][index]
+		}
+	}
+	return int(min)
+}
+
+// reset halves all counters.
+func (s *countMinSketch) reset() {
+	for row := range s.counters {
+		for i := range s.counters[row] {
+			s.counters[row][i] /= 2
+		}
+	}
+	s.additions /= 2
+	s.resets++
+}
+
+func (s *countMinSketch) stats() FrequencySketchStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var used, saturated int
+	for row := range s.counters {
+		for _, count := range s.counters[row] {
+			if count > 0 {
+				used++
+			}
+			if count == maxSketchCount {
+				saturated++
+			}
+		}
+	}
+
+	total := float64(sketchDepth * len(s.counters[0]))
+	return FrequencySketchStats{
+		Width:      len(s.counters[0]),
+		Depth:      sketchDepth,
+		Additions:  s.additions,
+		Resets:     s.resets,
+		FillRatio:  float64(used) / total,
+		Saturation: float64(saturated) / total,
+	}
+}
+
+// EnableFrequencySketch starts counting key accesses in a count-min sketch with at least width counters per row.
+// A width of 0 or less disables it.
+func (c *BiCache) EnableFrequencySketch(width int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if width <= 0 {
+		c.sketch = nil
+		return
+	}
+	c.sketch = newCountMinSketch(width, c.hasher)
+}
+
+// EstimateFrequency returns the estimated access frequency of key, or false if no sketch is active.
+func (c *BiCache) EstimateFrequency(key interface{}) (int, bool) {
+	c.mu.RLock()
+	sketch := c.sketch
+	c.mu.RUnlock()
+
+	if sketch == nil {
+		return 0, false
+	}
+	return sketch.estimate(c.mapKey(key)), true
+}
+
+// GetFrequencySketchStats returns the fill and saturation of the sketch, or false if no sketch is active.
+func (c *BiCache) GetFrequencySketchStats() (FrequencySketchStats, bool) {
+	c.mu.RLock()
+	sketch := c.sketch
+	c.mu.RUnlock()
+
+	if sketch == nil {
+		return FrequencySketchStats{}, false
+	}
+	return sketch.stats(), true
+}
+
+// recordAccess counts an access of key in the sketch, if one is active.
+func (c *BiCache) recordAccess(key interface{}) {
+	c.mu.RLock()
+	sketch := c.sketch
+	c.mu.RUnlock()
+
+	if sketch != nil {
+		sketch.add(c.mapKey(key))
+	}
+}
@@ -0,0 +1,54 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_EstimateFrequency(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+
+	// Without a sketch no estimate is available
+	if _, ok := cache.EstimateFrequency("key1"); ok {
+		t.Errorf("EstimateFrequency test failed. Expected: no sketch")
+	}
+
+	cache.EnableFrequencySketch(64)
+	cache.Set("key1", "value1", time.Minute)
+	for i := 0; i < 5; i++ {
+		cache.Get("key1")
+	}
+	cache.Get("key2")
+
+	if frequency, ok := cache.EstimateFrequency("key1"); !ok || frequency < 5 {
+		t.Errorf("EstimateFrequency test failed. Expected: >=5, Got: %v", frequency)
+	}
+	if frequency, _ := cache.EstimateFrequency("key3"); frequency > 1 {
+		t.Errorf("EstimateFrequency test (unseen key) failed. Expected: ~0, Got: %v", frequency)
+	}
+
+	stats, ok := cache.GetFrequencySketchStats()
+	if !ok || stats.Width != 64 || stats.Depth != 4 || stats.Additions != 6 || stats.FillRatio == 0 {
+		t.Errorf("EstimateFrequency test (stats) failed. Got: %+v", stats)
+	}
+}
+
+func TestCountMinSketch_Reset(t *testing.T) {
+	sketch := newCountMinSketch(16, newKeyHasher())
+
+	// Counters saturate and are halved once the sample size is reached
+	for i := 0; i < 159; i++ {
+		sketch.add("hot")
+	}
+	if frequency := sketch.estimate("hot"); frequency != maxSketchCount {
+		t.Errorf("CountMinSketch reset test failed. Expected: %v, Got: %v", maxSketchCount, frequency)
+	}
+
+	sketch.add("hot")
+	if frequency := sketch.estimate("hot"); frequency != maxSketchCount/2 {
+		t.Errorf("CountMinSketch reset test failed. Expected: %v, Got: %v", maxSketchCount/2, frequency)
+	}
+	if stats := sketch.stats(); stats.Resets != 1 {
+		t.Errorf("CountMinSketch reset test failed. Expected: 1 reset, Got: %v", stats.Resets)
+	}
+}
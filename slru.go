@@ -0,0 +1,114 @@
+package bicache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// slru is a segmented LRU. New keys enter the probation segment and move to the
+// protected segment when read again. When the protected segment is full, its least
+// recently used key moves back to the front of probation.
+type slru struct {
+	mu           sync.Mutex
+	probation    *list.List
+	protected    *list.List
+	elements     map[interface{}]*list.Element
+	ratio        float64
+	maxProtected int
+}
+
+type slruItem struct {
+	key       interface{}
+	protected bool
+}
+
+func newSLRU(capacity int, ratio float64) *slru {
+	s := &slru{
+		probation: list.New(),
+		protected: list.New(),
+		elements:  make(map[interface{}]*list.Element),
+		ratio:     ratio,
+	}
+	s.setCapacity(capacity)
+	return s
+}
+
+func (s *slru) setCapacity(capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxProtected = int(float64(capacity) * s.ratio)
+	s.demote()
+}
+
+// add records a write of key. Writes of existing keys count as reads.
+func (s *slru) add(key interface{}) {
+	s.touch(key)
+}
+
+// touch puts new keys on probation and promotes keys that are already tracked.
+func (s *slru) touch(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.elements[key]; exists {
+		s.promote(key)
+		return
+	}
+	s.elements[key] = s.probation.PushFront(&slruItem{key: key})
+}
+
+// promote moves key to the front of the protected segment. The caller must hold the lock.
+func (s *slru) promote(key interface{}) {
+	element := s.elements[key]
+	item := element.Value.(*slruItem)
+	if item.protected {
+		s.protected.MoveToFront(element)
+		return
+	}
+
+	s.probation.Remove(element)
+	item.protected = true
+	s.elements[key] = s.protected.PushFront(item)
+	s.demote()
+}
+
+// demote moves keys from the back of the protected segment to probation until it fits.
+// The caller must hold the lock.
+func (s *slru) demote() {
+	for s.protected.Len() > s.maxProtected {
+		item := s.protected.Remove(s.protected.Back()).(*slruItem)
+		item.protected = false
+		s.elements[item.key] = s.probation.PushFront(item)
+	}
+}
+
+func (s *slru) remove(key interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, exists := s.elements[key]
+	if !exists {
+		return
+	}
+	if element.Value.(*slruItem).protected {
+		s.protected.Remove(element)
+	} else {
+		s.probation.Remove(element)
+	}
+	delete(s.elements, key)
+}
+
+// victims returns keys from the back of probation, then from the back of the protected segment.
+func (s *slru) victims(n int) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]interface{}, 0, n)
+	for _, segment := range []*list.List{s.probation, s.protected} {
+		for element := segment.Back(); element != nil && len(keys) < n; element = element.Prev() {
+			keys = append(keys, element.Value.(*slruItem).key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,350 @@
+package bicache
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotEntry is a cache entry as written by Export. Keys and values of types other than
+// the basic Go types must be registered with gob.Register to be exported.
+type SnapshotEntry struct {
+	Key        interface{}
+	Value      interface{}
+	Expiration time.Time
+	// SoftExpiration is when the entry became or becomes stale, zero without a soft TTL.
+	SoftExpiration time.Time
+	ReadOnly       bool
+	Metadata       map[string]string
+	Tags           []string
+	Token          string
+	Writer         string
+	Hits           int
+}
+
+// SnapshotOption selects the entries that are exported or imported. An entry has to match all options.
+type SnapshotOption func(*snapshotOptions)
+
+type snapshotOptions struct {
+	namespaces []string
+	tags       []string
+	keys       func(key interface{}) bool
+	minHits    int
+	metrics    bool
+}
+
+func newSnapshotOptions(opts []SnapshotOption) snapshotOptions {
+	var options snapshotOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// FilterNamespaces selects entries whose string keys start with one of the namespaces.
+func FilterNamespaces(namespaces ...string) SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.namespaces = append(o.namespaces, namespaces...)
+	}
+}
+
+// FilterTags selects entries labeled with at least one of the tags.
+func FilterTags(tags ...string) SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// FilterKeys selects entries whose keys satisfy predicate.
+func FilterKeys(predicate func(key interface{}) bool) SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.keys = predicate
+	}
+}
+
+// FilterMinHits selects entries read at least n times, as estimated by the frequency sketch
+// at export time. Entries of caches without a frequency sketch have no hits.
+func FilterMinHits(n int) SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.minHits = n
+	}
+}
+
+// IncludeMetrics makes Export write the cumulative metrics of the whole cache after the entries,
+// and Import add them to the metrics of the importing cache, so lifetime statistics such as the hit
+// ratio survive restarts. The writes of the imported entries are not counted again, as the
+// restored metrics include them, and gauges such as EntriesCount are not restored. Snapshots
+// written with it can still be read by ReadSnapshot, and imported without it.
+func IncludeMetrics() SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.metrics = true
+	}
+}
+
+// match reports whether entry is selected by the options.
+func (o snapshotOptions) match(entry SnapshotEntry) bool {
+	if len(o.namespaces) > 0 {
+		key, ok := entry.Key.(string)
+		if !ok || !hasAnyPrefix(key, o.namespaces) {
+			return false
+		}
+	}
+	if len(o.tags) > 0 && !hasAnyTag(entry.Tags, o.tags) {
+		return false
+	}
+	if o.keys != nil && !o.keys(entry.Key) {
+		return false
+	}
+	return entry.Hits >= o.minHits
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Export writes the decoded, non-expired entries of the cache selected by opts to w as a gob-encoded snapshot.
+func (c *BiCache) Export(w io.Writer, opts ...SnapshotOption) error {
+	options := newSnapshotOptions(opts)
+	entries, err := c.snapshot(options)
+	if err != nil {
+		return err
+	}
+
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(entries); err != nil {
+		return err
+	}
+	if options.metrics {
+		return encoder.Encode(c.GetMetrics())
+	}
+	return nil
+}
+
+// snapshot returns the decoded, non-expired entries of the cache selected by options.
+func (c *BiCache) snapshot(options snapshotOptions) ([]SnapshotEntry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	entries := make([]SnapshotEntry, 0, len(c.cacheMap))
+	for stored, entry := range c.cacheMap {
+		key := c.externalKey(stored)
+		if !c.live(key, entry, now) {
+			continue
+		}
+
+		snapshotEntry := SnapshotEntry{
+			Key:            key,
+			Expiration:     entry.Expiration,
+			SoftExpiration: entry.softExpiration,
+			ReadOnly:       entry.ReadOnly,
+			Metadata:       copyMetadata(entry.Metadata),
+			Tags:           copyTags(entry.Tags),
+			Token:          entry.Token,
+			Writer:         entry.Writer,
+		}
+		if c.sketch != nil {
+			snapshotEntry.Hits = c.sketch.estimate(stored)
+		}
+		if !options.match(snapshotEntry) {
+			continue
+		}
+
+		value, err := c.decodeStored(entry.Value)
+		if err != nil {
+			return nil, fmt.Errorf("bicache: decoding %v: %w", key, err)
+		}
+		snapshotEntry.Value = value
+		entries = append(entries, snapshotEntry)
+	}
+	return entries, nil
+}
+
+// Import stores the entries of a snapshot written by Export that are selected by opts with their
+// remaining TTL and soft expiration. Entries that expired since the export are skipped. Restored
+// entries are not passed to the writer, the write-behind store or the dual-write store, which
+// already hold them. Entries that can't be stored, for example because their key holds a
+// read-only entry, are skipped as well, and the returned error joins their errors.
+func (c *BiCache) Import(r io.Reader, opts ...SnapshotOption) error {
+	decoder := gob.NewDecoder(r)
+	entries, err := readSnapshot(decoder)
+	if err != nil {
+		return err
+	}
+
+	options := newSnapshotOptions(opts)
+	var metrics *CacheMetrics
+	if options.metrics {
+		// Snapshots written without metrics end after the entries
+		metrics = new(CacheMetrics)
+		if err := decoder.Decode(metrics); err == io.EOF {
+			metrics = nil
+		} else if err != nil {
+			return fmt.Errorf("bicache: reading snapshot metrics: %w", err)
+		}
+	}
+
+	var errs []error
+	now := time.Now()
+	for _, entry := range entries {
+		if !options.match(entry) {
+			continue
+		}
+
+		var ttl time.Duration
+		if !entry.Expiration.IsZero() {
+			if ttl = entry.Expiration.Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+
+		opts := []SetOption{WithMetadata(entry.Metadata), WithTags(entry.Tags...), WithToken(entry.Token), WithWriter(entry.Writer), withRestored, withSoftExpiration(entry.SoftExpiration)}
+		if entry.ReadOnly {
+			opts = append(opts, WithReadOnly())
+		}
+		// The restored metrics already count the writes of the entries
+		if metrics != nil {
+			opts = append(opts, withUncounted)
+		}
+		if err := c.set(context.Background(), entry.Key, entry.Value, ttl, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("bicache: importing %v: %w", entry.Key, err))
+		}
+	}
+
+	if metrics != nil {
+		c.metrics.restore(*metrics)
+	}
+	return errors.Join(errs...)
+}
+
+// withRestored marks an entry restored from a snapshot.
+func withRestored(o *setOptions) {
+	o.restored = true
+}
+
+// withUncounted keeps an entry restored together with the metrics out of the write metrics.
+func withUncounted(o *setOptions) {
+	o.uncounted = true
+}
+
+// withSoftExpiration restores the soft expiration of an entry from a snapshot.
+func withSoftExpiration(softExpiration time.Time) SetOption {
+	return func(o *setOptions) {
+		o.softExpiration = softExpiration
+	}
+}
+
+// ReadSnapshot reads the entries of a snapshot written by Export.
+func ReadSnapshot(r io.Reader) ([]SnapshotEntry, error) {
+	return readSnapshot(gob.NewDecoder(r))
+}
+
+func readSnapshot(decoder *gob.Decoder) ([]SnapshotEntry, error) {
+	var entries []SnapshotEntry
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("bicache: reading snapshot: %w", err)
+	}
+	return entries, nil
+}
+
+// KeyDiff is a key that differs between two snapshots, with its estimated size in each.
+type KeyDiff struct {
+	Key     interface{}
+	OldSize int64
+	NewSize int64
+}
+
+// SnapshotDiff lists the keys added, removed and changed between two snapshots,
+// each sorted by key, and the change of their total estimated size.
+type SnapshotDiff struct {
+	Added     []KeyDiff
+	Removed   []KeyDiff
+	Changed   []KeyDiff
+	SizeDelta int64
+}
+
+// DiffSnapshots compares two snapshots. An entry has changed if its value, read-only flag,
+// metadata, tags or token differ; expiration times are ignored.
+func DiffSnapshots(old, new []SnapshotEntry) SnapshotDiff {
+	oldEntries := make(map[interface{}]SnapshotEntry, len(old))
+	for _, entry := range old {
+		oldEntries[entry.Key] = entry
+	}
+
+	var diff SnapshotDiff
+	for _, entry := range new {
+		size := estimateSize(entry.Value)
+		diff.SizeDelta += size
+
+		previous, exists := oldEntries[entry.Key]
+		if !exists {
+			diff.Added = append(diff.Added, KeyDiff{Key: entry.Key, NewSize: size})
+			continue
+		}
+		delete(oldEntries, entry.Key)
+
+		if !sameSnapshotEntry(previous, entry) {
+			diff.Changed = append(diff.Changed, KeyDiff{Key: entry.Key, OldSize: estimateSize(previous.Value), NewSize: size})
+		}
+	}
+
+	for _, entry := range old {
+		diff.SizeDelta -= estimateSize(entry.Value)
+		if _, removed := oldEntries[entry.Key]; removed {
+			diff.Removed = append(diff.Removed, KeyDiff{Key: entry.Key, OldSize: estimateSize(entry.Value)})
+		}
+	}
+
+	sortKeyDiffs(diff.Added)
+	sortKeyDiffs(diff.Removed)
+	sortKeyDiffs(diff.Changed)
+	return diff
+}
+
+func sameSnapshotEntry(a, b SnapshotEntry) bool {
+	return a.ReadOnly == b.ReadOnly && a.Token == b.Token &&
+		reflect.DeepEqual(a.Value, b.Value) &&
+		sameMetadata(a.Metadata, b.Metadata) &&
+		reflect.DeepEqual(copyTags(a.Tags), copyTags(b.Tags))
+}
+
+// sameMetadata compares metadata, treating nil and empty maps as equal.
+func sameMetadata(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, exists := b[k]; !exists || other != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortKeyDiffs(diffs []KeyDiff) {
+	sort.Slice(diffs, func(i, j int) bool {
+		return fmt.Sprint(diffs[i].Key) < fmt.Sprint(diffs[j].Key)
+	})
+}
@@ -0,0 +1,206 @@
+package bicache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_ExportImport(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetWithOptions("key1", "value1", time.Minute, WithTags("tag"), WithReadOnly())
+	cache.Set("key2", 42, 0)
+	cache.Set("expired", "value", -time.Second)
+
+	var buf bytes.Buffer
+	if err := cache.Export(&buf); err != nil {
+		t.Fatalf("Export test failed. Error: %v", err)
+	}
+
+	restored := NewBiCache(10, time.Minute)
+	if err := restored.Import(&buf); err != nil {
+		t.Fatalf("Import test failed. Error: %v", err)
+	}
+
+	if result, found := restored.Get("key1"); !found || result != "value1" {
+		t.Errorf("Import test failed. Expected: 'value1', Got: '%v'", result)
+	}
+	if result, found := restored.Get("key2"); !found || result != 42 {
+		t.Errorf("Import test failed. Expected: 42, Got: '%v'", result)
+	}
+	if _, found := restored.Get("expired"); found {
+		t.Errorf("Import test failed. Expected: expired entry skipped")
+	}
+	if info, _ := restored.GetEntryInfo("key1"); !info.ReadOnly || len(info.Tags) != 1 || info.Expiration.IsZero() {
+		t.Errorf("Import test failed. Expected: read-only tagged entry with expiration, Got: %+v", info)
+	}
+}
+
+func TestBiCache_ExportImportMetrics(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", 0)
+	cache.Get("key1")
+	cache.Get("key1")
+	cache.Get("missing")
+
+	var buf bytes.Buffer
+	if err := cache.Export(&buf, IncludeMetrics()); err != nil {
+		t.Fatalf("ExportMetrics test failed. Error: %v", err)
+	}
+	data := buf.Bytes()
+
+	// Readers of plain snapshots still read the entries
+	if entries, err := ReadSnapshot(bytes.NewReader(data)); err != nil || len(entries) != 1 {
+		t.Errorf("ExportMetrics test failed. Expected: 1 entry, Got: %v (%v)", entries, err)
+	}
+
+	restored := NewBiCache(10, time.Minute)
+	if err := restored.Import(bytes.NewReader(data), IncludeMetrics()); err != nil {
+		t.Fatalf("ImportMetrics test failed. Error: %v", err)
+	}
+	exported := cache.GetMetrics()
+	metrics := restored.GetMetrics()
+	if metrics.SetSuccess != exported.SetSuccess || metrics.Inserts != exported.Inserts || metrics.Hits != exported.Hits {
+		t.Errorf("ImportMetrics test failed. Expected: the exported metrics %+v, Got: %+v", exported, metrics)
+	}
+	restored.Get("key1")
+	metrics = restored.GetMetrics()
+	if metrics.Hits != 3 || metrics.Misses != 1 || metrics.EntriesCount != 1 {
+		t.Errorf("ImportMetrics test failed. Expected: 3 hits, 1 miss and 1 entry, Got: %d, %d, %d", metrics.Hits, metrics.Misses, metrics.EntriesCount)
+	}
+
+	// Metrics are only restored when asked for, and missing metrics are not an error
+	plain := NewBiCache(10, time.Minute)
+	if err := plain.Import(bytes.NewReader(data)); err != nil || plain.GetMetrics().Hits != 0 {
+		t.Errorf("ImportMetrics test failed. Expected: no hits restored, Got: %d (%v)", plain.GetMetrics().Hits, err)
+	}
+	buf.Reset()
+	cache.Export(&buf)
+	if err := plain.Import(&buf, IncludeMetrics()); err != nil {
+		t.Errorf("ImportMetrics test failed. Expected: no error, Got: %v", err)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	old := []SnapshotEntry{
+		{Key: "same", Value: "value"},
+		{Key: "changed", Value: "old"},
+		{Key: "removed", Value: "value"},
+	}
+	new := []SnapshotEntry{
+		{Key: "same", Value: "value", Expiration: time.Now()},
+		{Key: "changed", Value: "newer"},
+		{Key: "added", Value: "value"},
+	}
+
+	diff := DiffSnapshots(old, new)
+	if len(diff.Added) != 1 || diff.Added[0].Key != "added" {
+		t.Errorf("DiffSnapshots test failed. Expected: [added], Got: %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "removed" {
+		t.Errorf("DiffSnapshots test failed. Expected: [removed], Got: %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Key != "changed" || diff.Changed[0].NewSize-diff.Changed[0].OldSize != 2 {
+		t.Errorf("DiffSnapshots test failed. Expected: [changed] growing by 2 bytes, Got: %v", diff.Changed)
+	}
+	if diff.SizeDelta != 2 {
+		t.Errorf("DiffSnapshots test failed. Expected: size delta 2, Got: %v", diff.SizeDelta)
+	}
+}
+
+func TestBiCache_ExportImportFiltered(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.EnableFrequencySketch(64)
+	cache.Set("ref:countries", "value", 0)
+	cache.SetWithOptions("ref:currencies", "value", 0, WithTags("static"))
+	cache.SetWithOptions("user:1", "value", 0, WithTags("static"))
+	for i := 0; i < 3; i++ {
+		cache.Get("ref:countries")
+	}
+
+	var buf bytes.Buffer
+	if err := cache.Export(&buf, FilterNamespaces("ref:")); err != nil {
+		t.Fatalf("ExportFiltered test failed. Error: %v", err)
+	}
+	entries, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ExportFiltered test (namespace) failed. Expected: 2 entries, Got: %v (%v)", len(entries), err)
+	}
+
+	// Import can narrow the snapshot further
+	restored := NewBiCache(10, time.Minute)
+	if err := restored.Import(&buf, FilterTags("static")); err != nil {
+		t.Fatalf("ImportFiltered test failed. Error: %v", err)
+	}
+	if _, found := restored.Get("ref:currencies"); !found {
+		t.Errorf("ImportFiltered test failed. Expected: ref:currencies imported")
+	}
+	if _, found := restored.Get("ref:countries"); found {
+		t.Errorf("ImportFiltered test failed. Expected: ref:countries skipped")
+	}
+
+	buf.Reset()
+	cache.Export(&buf, FilterMinHits(3), FilterKeys(func(key interface{}) bool { return key != "user:1" }))
+	if entries, _ := ReadSnapshot(&buf); len(entries) != 1 || entries[0].Key != "ref:countries" {
+		t.Errorf("ExportFiltered test (min hits) failed. Expected: [ref:countries], Got: %v", entries)
+	}
+}
+
+func TestBiCache_ImportSkipsWriters(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("a", "value", 0)
+	var buf bytes.Buffer
+	if err := cache.Export(&buf); err != nil {
+		t.Fatalf("Import writers test failed. Error: %v", err)
+	}
+
+	restored := NewBiCache(10, time.Minute)
+	written := 0
+	restored.SetWriter(func(key, value interface{}) error {
+		written++
+		return nil
+	})
+	store := &mapStore{entries: make(map[interface{}]interface{})}
+	restored.SetDualWrite(store)
+
+	if err := restored.Import(&buf); err != nil {
+		t.Fatalf("Import writers test failed. Error: %v", err)
+	}
+	restored.Close()
+	if written != 0 || len(store.entries) != 0 {
+		t.Errorf("Import writers test failed. Expected: nothing written, Got: %d writes, %v", written, store.entries)
+	}
+}
+
+func TestBiCache_ImportErrors(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("a", "value", 0)
+	cache.Set("b", "value", 0)
+	cache.SetWithOptions("stale", "value", time.Minute, WithSoftTTL(time.Millisecond))
+	var buf bytes.Buffer
+	if err := cache.Export(&buf); err != nil {
+		t.Fatalf("Import errors test failed. Error: %v", err)
+	}
+	time.Sleep(time.Millisecond * 5)
+
+	// Keys that can't be imported are skipped and reported, the others are imported
+	restored := NewBiCache(10, time.Minute)
+	restored.SetWithOptions("a", "local", 0, WithReadOnly())
+	err := restored.Import(&buf)
+	var readOnly *ReadOnlyError
+	if !errors.As(err, &readOnly) || readOnly.Key != "a" {
+		t.Errorf("Import errors test failed. Expected: a ReadOnlyError for a, Got: %v", err)
+	}
+	if value, _ := restored.Get("a"); value != "local" {
+		t.Errorf("Import errors test failed. Expected: a kept, Got: %v", value)
+	}
+	if value, found := restored.Get("b"); !found || value != "value" {
+		t.Errorf("Import errors test failed. Expected: b imported, Got: %v", value)
+	}
+
+	// Soft expirations survive the round trip
+	if info, found := restored.GetEntryInfo("stale"); !found || !info.Stale {
+		t.Errorf("Import errors test failed. Expected: stale entry restored as stale, Got: %+v", info)
+	}
+}
@@ -0,0 +1,71 @@
+package bicache
+
+import "time"
+
+// SetMaxStaleness bounds how old a served value can be, regardless of the TTL of its entry:
+// entries written more than maxStaleness ago are treated as expired by reads and removed by cleanup.
+// Refreshing an entry resets its age, touching it doesn't. A maxStaleness of 0 disables the bound.
+func (c *BiCache) SetMaxStaleness(maxStaleness time.Duration) {
+	c.maxStaleness.Store(int64(maxStaleness))
+}
+
+// staleAt returns when entry becomes too old to be served, or zero if there is no bound.
+func (c *BiCache) staleAt(entry CacheEntry) time.Time {
+	maxStaleness := time.Duration(c.maxStaleness.Load())
+	if maxStaleness <= 0 {
+		return time.Time{}
+	}
+	return entry.created.Add(maxStaleness)
+}
+
+// tooStale reports whether entry is older than the maximum staleness.
+func (c *BiCache) tooStale(entry CacheEntry, now time.Time) bool {
+	at := c.staleAt(entry)
+	return !at.IsZero() && !now.Before(at)
+}
+
+// expiresAt returns when entry expires, taking the maximum staleness into account,
+// or zero if it never expires.
+func (c *BiCache) expiresAt(entry CacheEntry) time.Time {
+	return earliest(entry.Expiration, c.staleAt(entry))
+}
+
+// earliest returns the earlier of two times, a zero time meaning never.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// SetStaleWhileRevalidate lets Get serve entries that expired less than maxStale ago while
+// a background refresh replaces them, for keys that have a loader. Such values are reported as
+// stale by GetWithInfo and counted in the StaleServed metric. Entries are kept until they are too
+// old to be served stale, but never served beyond the maximum staleness. A maxStale of 0 disables it.
+func (c *BiCache) SetStaleWhileRevalidate(maxStale time.Duration) {
+	c.maxStale.Store(int64(maxStale))
+}
+
+// servesStale reports whether the expired entry stored under key can be served while it is
+// refreshed. The caller must hold the lock.
+func (c *BiCache) servesStale(key interface{}, entry CacheEntry, now time.Time) bool {
+	maxStale := time.Duration(c.maxStale.Load())
+	if maxStale <= 0 || !expired(entry, now) || !now.Before(entry.Expiration.Add(maxStale)) {
+		return false
+	}
+	return !c.tooStale(entry, now) && !c.invalidated(key, entry) && c.registeredLoader(key) != nil
+}
+
+// SetMaxStaleness sets the maximum staleness of every shard.
+func (c *ShardedBiCache) SetMaxStaleness(maxStaleness time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetMaxStaleness(maxStaleness)
+	}
+}
+
+// SetStaleWhileRevalidate sets the maximum staleness of expired values served by every shard.
+func (c *ShardedBiCache) SetStaleWhileRevalidate(maxStale time.Duration) {
+	for _, shard := range c.shards {
+		shard.SetStaleWhileRevalidate(maxStale)
+	}
+}
@@ -0,0 +1,110 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_MaxStaleness(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetMaxStaleness(time.Millisecond * 50)
+
+	cache.Set("price", 10, 0)
+	cache.Set("quote", 20, time.Hour)
+	if ttl, _ := cache.TTL("quote"); ttl <= 0 || ttl > time.Millisecond*50 {
+		t.Errorf("MaxStaleness test failed. Expected: TTL bounded by the maximum staleness, Got: %v", ttl)
+	}
+	if _, expiresAt, _ := cache.GetWithExpiration("price"); expiresAt.IsZero() {
+		t.Errorf("MaxStaleness test failed. Expected: an expiration for a non-expiring entry")
+	}
+
+	// Touching doesn't make the value fresher
+	time.Sleep(time.Millisecond * 30)
+	cache.Touch("quote")
+	cache.Set("fresh", 30, 0)
+	time.Sleep(time.Millisecond * 30)
+
+	if _, found := cache.Get("price"); found {
+		t.Errorf("MaxStaleness test failed. Expected: stale entry without TTL not served")
+	}
+	if _, found := cache.Get("quote"); found {
+		t.Errorf("MaxStaleness test failed. Expected: stale touched entry not served")
+	}
+	if value, found := cache.Get("fresh"); !found || value != 30 {
+		t.Errorf("MaxStaleness test failed. Expected: fresh entry served, Got: %v", value)
+	}
+	if metrics := cache.GetMetrics(); metrics.Expirations != 2 {
+		t.Errorf("MaxStaleness test failed. Expected: 2 expirations, Got: %v", metrics.Expirations)
+	}
+
+	cache.SetMaxStaleness(0)
+	cache.Set("price", 10, 0)
+	time.Sleep(time.Millisecond * 60)
+	if _, found := cache.Get("price"); !found {
+		t.Errorf("MaxStaleness test failed. Expected: bound disabled")
+	}
+}
+
+func TestBiCache_MaxStalenessCleanup(t *testing.T) {
+	cache := NewBiCache(10, time.Millisecond*20)
+	defer cache.Close()
+	cache.SetMaxStaleness(time.Millisecond * 30)
+
+	cache.Set("key1", "value1", 0)
+	time.Sleep(time.Millisecond * 100)
+
+	if n := cache.Len(); n != 0 {
+		t.Errorf("MaxStaleness cleanup test failed. Expected: stale entry removed by cleanup, Got: %v entries", n)
+	}
+}
+
+func TestBiCache_StaleWhileRevalidate(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetStaleWhileRevalidate(time.Millisecond * 100)
+
+	loaded := make(chan struct{}, 1)
+	release := make(chan struct{})
+	cache.RegisterLoader("price:", func(key interface{}) (interface{}, time.Duration, error) {
+		<-release
+		loaded <- struct{}{}
+		return "new", time.Minute, nil
+	})
+	cache.Set("price:1", "old", time.Millisecond*20)
+	cache.Set("other", "old", time.Millisecond*20)
+	time.Sleep(time.Millisecond * 30)
+
+	// The expired value is served, marked stale, while the slow refresh runs
+	value, info, found := cache.GetWithInfo("price:1")
+	if !found || value != "old" || !info.Stale {
+		t.Errorf("StaleWhileRevalidate test failed. Expected: stale old value, Got: %v, %+v, %v", value, info, found)
+	}
+	if _, found := cache.Get("other"); found {
+		t.Errorf("StaleWhileRevalidate test failed. Expected: no stale value without a loader")
+	}
+
+	close(release)
+	<-loaded
+	time.Sleep(time.Millisecond * 10)
+	if value, info, _ := cache.GetWithInfo("price:1"); value != "new" || info.Stale {
+		t.Errorf("StaleWhileRevalidate test failed. Expected: refreshed value, Got: %v, %+v", value, info)
+	}
+	if metrics := cache.GetMetrics(); metrics.StaleServed != 1 {
+		t.Errorf("StaleWhileRevalidate test failed. Expected: StaleServed=1, Got: %v", metrics.StaleServed)
+	}
+}
+
+func TestBiCache_StaleWhileRevalidateLimit(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetStaleWhileRevalidate(time.Millisecond * 20)
+	cache.SetLoader(func(key interface{}) (interface{}, time.Duration, error) {
+		return "new", time.Minute, nil
+	})
+
+	cache.Set("key1", "old", time.Millisecond*10)
+	time.Sleep(time.Millisecond * 40)
+
+	// Past the stale window the value is loaded instead
+	if value, info, _ := cache.GetWithInfo("key1"); value != "new" || info.Stale {
+		t.Errorf("StaleWhileRevalidate limit test failed. Expected: loaded value, Got: %v, %+v", value, info)
+	}
+}
@@ -0,0 +1,75 @@
+package bicache
+
+import "time"
+
+// ageBuckets are the upper bounds of the age and time-to-expiry histograms.
+var ageBuckets = []time.Duration{
+	time.Second,
+	time.Second * 10,
+	time.Minute,
+	time.Minute * 10,
+	time.Hour,
+	time.Hour * 6,
+	time.Hour * 24,
+}
+
+// DurationHistogram counts durations into buckets.
+// Counts[i] holds the durations up to Bounds[i], the last count holds the longer ones.
+type DurationHistogram struct {
+	Bounds []time.Duration
+	Counts []int64
+}
+
+func newDurationHistogram(bounds []time.Duration) DurationHistogram {
+	return DurationHistogram{
+		Bounds: append([]time.Duration(nil), bounds...),
+		Counts: make([]int64, len(bounds)+1),
+	}
+}
+
+func (h DurationHistogram) observe(d time.Duration) {
+	for i, bound := range h.Bounds {
+		if d <= bound {
+			h.Counts[i]++
+			return
+		}
+	}
+	h.Counts[len(h.Bounds)]++
+}
+
+// AgeStats describes how old the cached entries are and how long they have left.
+type AgeStats struct {
+	Entries      int
+	Age          DurationHistogram
+	TimeToExpiry DurationHistogram
+	NoExpiry     int
+	Expired      int
+}
+
+// GetAgeStats returns histograms of the age and remaining lifetime of the cached entries.
+func (c *BiCache) GetAgeStats() AgeStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	stats := AgeStats{
+		Entries:      len(c.cacheMap),
+		Age:          newDurationHistogram(ageBuckets),
+		TimeToExpiry: newDurationHistogram(ageBuckets),
+	}
+
+	for _, entry := range c.cacheMap {
+		stats.Age.observe(now.Sub(entry.created))
+
+		expiration := c.cleanupExpiration(entry)
+		switch {
+		case expiration.IsZero():
+			stats.NoExpiry++
+		case !expiration.After(now):
+			stats.Expired++
+		default:
+			stats.TimeToExpiry.observe(expiration.Sub(now))
+		}
+	}
+	return stats
+}
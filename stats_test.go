@@ -0,0 +1,30 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_GetAgeStats(t *testing.T) {
+	cache := NewBiCache(5, time.Minute)
+
+	cache.Set("short", "value", time.Second*5)
+	cache.Set("long", "value", time.Hour*2)
+	cache.Set("forever", "value", 0)
+	cache.Set("expired", "value", -time.Second)
+
+	stats := cache.GetAgeStats()
+	if stats.Entries != 4 || stats.NoExpiry != 1 || stats.Expired != 1 {
+		t.Errorf("GetAgeStats test failed. Expected: Entries=4, NoExpiry=1, Expired=1. Got: %+v", stats)
+	}
+
+	// All entries were just created
+	if stats.Age.Counts[0] != 4 {
+		t.Errorf("GetAgeStats test (age) failed. Expected: 4 entries in the first bucket, Got: %v", stats.Age.Counts)
+	}
+
+	// One entry expires within 10s, one within 6h
+	if stats.TimeToExpiry.Counts[1] != 1 || stats.TimeToExpiry.Counts[5] != 1 {
+		t.Errorf("GetAgeStats test (time to expiry) failed. Got: %v", stats.TimeToExpiry.Counts)
+	}
+}
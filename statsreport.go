@@ -0,0 +1,268 @@
+package bicache
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NamespaceStats are the statistics of the live entries of one namespace at one point in time.
+type NamespaceStats struct {
+	Time time.Time `json:"time"`
+	// Namespace is the prefix of the keys up to and including the separator. Keys without
+	// the separator and keys that are not strings are counted in the empty namespace.
+	Namespace   string `json:"namespace"`
+	Entries     int    `json:"entries"`
+	MemoryBytes int64  `json:"memory_bytes"`
+	Weight      int64  `json:"weight"`
+	// Expiring is the number of entries with an expiration.
+	Expiring int `json:"expiring"`
+	// MeanAge is the mean time since the entries were written.
+	MeanAge time.Duration `json:"mean_age_ns"`
+}
+
+// NamespaceStats returns the statistics of each namespace of the live entries, sorted by namespace.
+// The namespace of a key is its prefix up to and including the first separator, as for NamespacesSeq.
+func (c *BiCache) NamespaceStats(separator string) []NamespaceStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	namespaces := make(map[string]*NamespaceStats)
+	ages := make(map[string]time.Duration)
+	for stored, entry := range c.cacheMap {
+		key := c.externalKey(stored)
+		if !c.live(key, entry, now) {
+			continue
+		}
+
+		var namespace string
+		if s, ok := key.(string); ok && separator != "" {
+			if i := strings.Index(s, separator); i >= 0 {
+				namespace = s[:i+len(separator)]
+			}
+		}
+
+		stats := namespaces[namespace]
+		if stats == nil {
+			stats = &NamespaceStats{Time: now, Namespace: namespace}
+			namespaces[namespace] = stats
+		}
+		stats.Entries++
+		if entry.memory != 0 {
+			stats.MemoryBytes += entry.memory
+		} else {
+			stats.MemoryBytes += entryMemory(stored, entry)
+		}
+		stats.Weight += entry.weight
+		if !entry.Expiration.IsZero() {
+			stats.Expiring++
+		}
+		ages[namespace] += now.Sub(entry.created)
+	}
+
+	result := make([]NamespaceStats, 0, len(namespaces))
+	for namespace, stats := range namespaces {
+		stats.MeanAge = ages[namespace] / time.Duration(stats.Entries)
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result
+}
+
+// StatsFormat is the format of the reports written by a StatsReporter.
+type StatsFormat int
+
+const (
+	// StatsJSON writes one JSON object per namespace and line.
+	StatsJSON StatsFormat = iota
+	// StatsCSV writes one row per namespace, after a header row at the start of each file.
+	StatsCSV
+)
+
+// statsHeader names the CSV columns of NamespaceStats.
+var statsHeader = []string{"time", "namespace", "entries", "memory_bytes", "weight", "expiring", "mean_age_ns"}
+
+// StatsReporterConfig configures a StatsReporter.
+type StatsReporterConfig struct {
+	// Separator ends the namespace of a key, ":" if not set.
+	Separator string
+	// Interval is how often the statistics are written, one minute if not set.
+	Interval time.Duration
+	// Format is the format of the reports, StatsJSON if not set.
+	Format StatsFormat
+	// Writer receives the reports. If it is nil, they are appended to the file at Path instead.
+	Writer io.Writer
+	// Path is the file the reports are appended to. Rotated files are renamed to Path.1, Path.2
+	// and so on, Path.1 being the most recent.
+	Path string
+	// MaxBytes is the size after which the file is rotated, 64 MiB if not set.
+	MaxBytes int64
+	// MaxFiles is the number of rotated files kept, 5 if not set.
+	MaxFiles int
+	// MaxAge is how long rotated files are kept, as long as MaxFiles allows if not set.
+	MaxAge time.Duration
+	// OnError is called with errors writing the reports.
+	OnError func(err error)
+}
+
+// StatsReporter periodically writes the statistics of each namespace of a cache to a file or
+// writer, for environments without a metrics system.
+type StatsReporter struct {
+	cache  *BiCache
+	config StatsReporterConfig
+
+	// mu guards file and wroteHeader.
+	mu          sync.Mutex
+	file        *rotatingFile
+	wroteHeader bool
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStatsReporter opens or creates the file at config.Path, unless config.Writer is set, and
+// starts writing the statistics of cache every config.Interval. Close stops it.
+func NewStatsReporter(cache *BiCache, config StatsReporterConfig) (*StatsReporter, error) {
+	if config.Separator == "" {
+		config.Separator = ":"
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 64 << 20
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = 5
+	}
+
+	r := &StatsReporter{
+		cache:  cache,
+		config: config,
+		done:   make(chan struct{}),
+	}
+	if config.Writer == nil {
+		file, err := openRotatingFile(config.Path, config.MaxBytes, config.MaxFiles, config.MaxAge)
+		if err != nil {
+			return nil, err
+		}
+		r.file = file
+	}
+	go r.run()
+
+	return r, nil
+}
+
+// Report writes the current statistics right away.
+func (r *StatsReporter) Report() error {
+	stats := r.cache.NamespaceStats(r.config.Separator)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return ErrClosed
+	default:
+	}
+
+	if r.file == nil {
+		data, err := r.encode(stats, !r.wroteHeader)
+		if err != nil {
+			return err
+		}
+		r.wroteHeader = true
+		_, err = r.config.Writer.Write(data)
+		return err
+	}
+
+	// Rotated files get their own CSV header
+	data, err := r.encode(stats, false)
+	if err != nil {
+		return err
+	}
+	rotateErr := r.file.rotateIfFull(len(data))
+	if r.file.size == 0 {
+		if data, err = r.encode(stats, true); err != nil {
+			return err
+		}
+	}
+	if err := r.file.write(data); err != nil {
+		return err
+	}
+	if err := r.file.flush(); err != nil {
+		return err
+	}
+	return rotateErr
+}
+
+// Close stops the reporter and closes its file. The writer is not closed.
+func (r *StatsReporter) Close() error {
+	var err error
+	r.stopOnce.Do(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		close(r.done)
+		if r.file != nil {
+			err = r.file.close()
+		}
+	})
+	return err
+}
+
+func (r *StatsReporter) run() {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if err := r.Report(); err != nil && err != ErrClosed && r.config.OnError != nil {
+				r.config.OnError(err)
+			}
+		}
+	}
+}
+
+// encode formats stats in the configured format, with a CSV header if header is set.
+func (r *StatsReporter) encode(stats []NamespaceStats, header bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if r.config.Format != StatsCSV {
+		encoder := json.NewEncoder(&buf)
+		for _, s := range stats {
+			if err := encoder.Encode(s); err != nil {
+				return nil, err
+			}
+		}
+		return buf.Bytes(), nil
+	}
+
+	writer := csv.NewWriter(&buf)
+	if header {
+		writer.Write(statsHeader)
+	}
+	for _, s := range stats {
+		writer.Write([]string{
+			s.Time.Format(time.RFC3339Nano),
+			s.Namespace,
+			strconv.Itoa(s.Entries),
+			strconv.FormatInt(s.MemoryBytes, 10),
+			strconv.FormatInt(s.Weight, 10),
+			strconv.Itoa(s.Expiring),
+			strconv.FormatInt(int64(s.MeanAge), 10),
+		})
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
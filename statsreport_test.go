@@ -0,0 +1,83 @@
+package bicache
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBiCache_NamespaceStats(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("user:1", "a", time.Hour)
+	cache.Set("user:2", "b", 0)
+	cache.Set("ref:countries", "c", 0)
+	cache.Set("plain", "d", 0)
+	cache.Set("user:expired", "e", -time.Second)
+
+	stats := cache.NamespaceStats(":")
+	if len(stats) != 3 || stats[0].Namespace != "" || stats[1].Namespace != "ref:" || stats[2].Namespace != "user:" {
+		t.Fatalf("NamespaceStats test failed. Expected: '', ref: and user:, Got: %+v", stats)
+	}
+	if user := stats[2]; user.Entries != 2 || user.Expiring != 1 || user.Weight != 2 || user.MemoryBytes <= 0 {
+		t.Errorf("NamespaceStats test failed. Expected: 2 user entries, 1 expiring, Got: %+v", user)
+	}
+}
+
+func TestStatsReporter(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("user:1", "a", 0)
+	cache.Set("ref:countries", "b", 0)
+
+	// Reports to a writer
+	var buf bytes.Buffer
+	reporter, err := NewStatsReporter(cache, StatsReporterConfig{Writer: &buf, Interval: time.Millisecond * 10})
+	if err != nil {
+		t.Fatalf("StatsReporter test failed. Unexpected error: %v", err)
+	}
+	time.Sleep(time.Millisecond * 35)
+	reporter.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 2 || len(lines)%2 != 0 {
+		t.Fatalf("StatsReporter test failed. Expected: 2 lines per report, Got: %v", lines)
+	}
+	var stats NamespaceStats
+	if err := json.Unmarshal([]byte(lines[0]), &stats); err != nil || stats.Namespace != "ref:" || stats.Entries != 1 {
+		t.Errorf("StatsReporter test failed. Expected: ref: stats, Got: %+v (%v)", stats, err)
+	}
+	if err := reporter.Report(); err != ErrClosed {
+		t.Errorf("StatsReporter test failed. Expected: %v, Got: %v", ErrClosed, err)
+	}
+
+	// Reports to rotated CSV files, each with a header
+	path := filepath.Join(t.TempDir(), "stats.csv")
+	reporter, err = NewStatsReporter(cache, StatsReporterConfig{Path: path, Format: StatsCSV, Interval: time.Hour, MaxBytes: 200, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("StatsReporter test failed. Unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := reporter.Report(); err != nil {
+			t.Fatalf("StatsReporter test failed. Unexpected error: %v", err)
+		}
+	}
+	if err := reporter.Close(); err != nil {
+		t.Fatalf("StatsReporter test failed. Unexpected error: %v", err)
+	}
+
+	for _, name := range []string{path, path + ".1"} {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("StatsReporter test failed. Expected: %s, Got: %v", name, err)
+		}
+		records, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil || len(records) < 3 || records[0][0] != "time" || records[1][1] != "ref:" || records[2][2] != "1" {
+			t.Errorf("StatsReporter test failed. Expected: header and 2 rows in %s, Got: %v (%v)", name, records, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package bicache
+
+import "time"
+
+// KeysByTag returns the keys of the non-expired entries labeled with tag, in no particular order.
+func (c *BiCache) KeysByTag(tag string) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	var keys []interface{}
+	for stored, entry := range c.cacheMap {
+		if key := c.externalKey(stored); c.live(key, entry, now) && hasAnyTag(entry.Tags, []string{tag}) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Tags returns the distinct tags of the non-expired entries with the number of entries labeled
+// with each, for example to check how many entries a tag covers before acting on all of them.
+func (c *BiCache) Tags() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	tags := make(map[string]int)
+	for key, entry := range c.cacheMap {
+		if !c.live(c.externalKey(key), entry, now) {
+			continue
+		}
+		for _, tag := range entry.Tags {
+			tags[tag]++
+		}
+	}
+	return tags
+}
+
+// KeysByTag returns the keys labeled with tag across all shards.
+func (c *ShardedBiCache) KeysByTag(tag string) []interface{} {
+	var keys []interface{}
+	for _, shard := range c.shards {
+		keys = append(keys, shard.KeysByTag(tag)...)
+	}
+	return keys
+}
+
+// Tags returns the distinct tags across all shards with the number of entries labeled with each.
+func (c *ShardedBiCache) Tags() map[string]int {
+	tags := make(map[string]int)
+	for _, shard := range c.shards {
+		for tag, count := range shard.Tags() {
+			tags[tag] += count
+		}
+	}
+	return tags
+}
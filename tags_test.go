@@ -0,0 +1,30 @@
+package bicache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBiCache_KeysByTag(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetWithOptions("ref:a", 1, 0, WithTags("static"))
+	cache.SetWithOptions("ref:b", 2, 0, WithTags("static", "hot"))
+	cache.SetWithOptions("expired", 3, -time.Second, WithTags("static"))
+	cache.Set("user:1", 4, 0)
+
+	if keys := cache.KeysByTag("hot"); !reflect.DeepEqual(keys, []interface{}{"ref:b"}) {
+		t.Errorf("KeysByTag test failed. Expected: [ref:b], Got: %v", keys)
+	}
+	if keys := cache.KeysByTag("static"); len(keys) != 2 {
+		t.Errorf("KeysByTag test failed. Expected: 2 keys, Got: %v", keys)
+	}
+	if keys := cache.KeysByTag("missing"); len(keys) != 0 {
+		t.Errorf("KeysByTag test failed. Expected: no keys, Got: %v", keys)
+	}
+
+	expected := map[string]int{"static": 2, "hot": 1}
+	if tags := cache.Tags(); !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Tags test failed. Expected: %v, Got: %v", expected, tags)
+	}
+}
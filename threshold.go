@@ -0,0 +1,145 @@
+package bicache
+
+import "time"
+
+// ThresholdMetric is a metric that thresholds are checked against.
+type ThresholdMetric int
+
+const (
+	// FillRatio is the share of the capacity in use, or of the maximum weight or memory
+	// budget if one is set and more of it is used.
+	FillRatio ThresholdMetric = iota
+	// HitRatio is the share of lookups since the previous check that were hits.
+	HitRatio
+	// EvictionRate is the number of evictions per second since the previous check.
+	EvictionRate
+)
+
+func (m ThresholdMetric) String() string {
+	switch m {
+	case FillRatio:
+		return "fill ratio"
+	case HitRatio:
+		return "hit ratio"
+	case EvictionRate:
+		return "eviction rate"
+	default:
+		return "unknown"
+	}
+}
+
+// Threshold is a limit on a metric, breached when the metric is above it or, with Below, under it.
+type Threshold struct {
+	Metric ThresholdMetric
+	Value  float64
+	Below  bool
+}
+
+// FillRatioAbove is breached when more than ratio of the capacity is in use.
+func FillRatioAbove(ratio float64) Threshold {
+	return Threshold{Metric: FillRatio, Value: ratio}
+}
+
+// HitRatioBelow is breached when less than ratio of the lookups are hits.
+func HitRatioBelow(ratio float64) Threshold {
+	return Threshold{Metric: HitRatio, Value: ratio, Below: true}
+}
+
+// EvictionRateAbove is breached when more than perSecond entries are evicted per second.
+func EvictionRateAbove(perSecond float64) Threshold {
+	return Threshold{Metric: EvictionRate, Value: perSecond}
+}
+
+// ThresholdEvent reports that a threshold was breached or recovered.
+type ThresholdEvent struct {
+	Threshold Threshold
+	Value     float64
+	Breached  bool
+	Metrics   CacheMetrics
+}
+
+// ThresholdFunc is called when a threshold is breached and when it recovers.
+type ThresholdFunc func(event ThresholdEvent)
+
+type thresholdWatch struct {
+	threshold Threshold
+	callback  ThresholdFunc
+	breached  bool
+}
+
+// thresholdBaseline holds the counters at the previous check, for ratios and rates over the interval.
+type thresholdBaseline struct {
+	at        time.Time
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// OnThreshold registers callback to be called asynchronously when threshold is breached
+// and again when it recovers. Thresholds are checked on every periodic cleanup.
+func (c *BiCache) OnThreshold(threshold Threshold, callback ThresholdFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.thresholds = append(c.thresholds, &thresholdWatch{threshold: threshold, callback: callback})
+}
+
+// checkThresholds calls the callbacks of thresholds that were breached or recovered since
+// the previous check. The caller must hold the write lock.
+func (c *BiCache) checkThresholds(now time.Time) {
+	metrics := c.metrics.snapshot()
+	baseline := c.thresholdBaseline
+	c.warmthBaseline = baseline
+	c.thresholdBaseline = thresholdBaseline{
+		at:        now,
+		hits:      metrics.Hits,
+		misses:    metrics.Misses,
+		evictions: metrics.Evictions,
+	}
+	if len(c.thresholds) == 0 || baseline.at.IsZero() {
+		return
+	}
+
+	values := map[ThresholdMetric]float64{FillRatio: c.fillRatio()}
+	if lookups := metrics.Hits + metrics.Misses - baseline.hits - baseline.misses; lookups > 0 {
+		values[HitRatio] = float64(metrics.Hits-baseline.hits) / float64(lookups)
+	}
+	if elapsed := now.Sub(baseline.at).Seconds(); elapsed > 0 {
+		values[EvictionRate] = float64(metrics.Evictions-baseline.evictions) / elapsed
+	}
+
+	for _, watch := range c.thresholds {
+		value, measured := values[watch.threshold.Metric]
+		if !measured {
+			continue
+		}
+
+		breached := value > watch.threshold.Value
+		if watch.threshold.Below {
+			breached = value < watch.threshold.Value
+		}
+		if breached == watch.breached {
+			continue
+		}
+
+		watch.breached = breached
+		go watch.callback(ThresholdEvent{Threshold: watch.threshold, Value: value, Breached: breached, Metrics: metrics})
+	}
+}
+
+// fillRatio returns the share of the capacity, maximum weight or memory budget in use,
+// whichever is highest. The caller must hold the lock.
+func (c *BiCache) fillRatio() float64 {
+	var ratio float64
+	if c.maxWeight > 0 {
+		ratio = float64(c.metrics.TotalWeight.Load()) / float64(c.maxWeight)
+	} else if c.capacity > 0 {
+		ratio = float64(len(c.cacheMap)) / float64(c.capacity)
+	}
+	if c.maxMemory > 0 {
+		if memory := float64(c.metrics.MemoryBytes.Load()) / float64(c.maxMemory); memory > ratio {
+			ratio = memory
+		}
+	}
+	return ratio
+}
@@ -0,0 +1,72 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_OnThreshold(t *testing.T) {
+	cache := NewBiCache(4, 20*time.Millisecond)
+
+	events := make(chan ThresholdEvent, 10)
+	cache.OnThreshold(FillRatioAbove(0.5), func(event ThresholdEvent) {
+		events <- event
+	})
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+
+	select {
+	case event := <-events:
+		if !event.Breached || event.Value != 0.75 || event.Threshold.Metric != FillRatio {
+			t.Errorf("OnThreshold test failed. Expected: fill ratio 0.75 breached, Got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnThreshold test failed. Expected: breach reported")
+	}
+
+	// The callback is not repeated while the threshold stays breached
+	time.Sleep(60 * time.Millisecond)
+	select {
+	case event := <-events:
+		t.Errorf("OnThreshold test failed. Expected: no repeated event, Got: %+v", event)
+	default:
+	}
+
+	cache.Delete("key3")
+	cache.Delete("key2")
+	select {
+	case event := <-events:
+		if event.Breached || event.Value != 0.25 {
+			t.Errorf("OnThreshold test failed. Expected: recovery at 0.25, Got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnThreshold test failed. Expected: recovery reported")
+	}
+}
+
+func TestBiCache_OnThresholdHitRatio(t *testing.T) {
+	cache := NewBiCache(10, 20*time.Millisecond)
+
+	events := make(chan ThresholdEvent, 10)
+	cache.OnThreshold(HitRatioBelow(0.5), func(event ThresholdEvent) {
+		events <- event
+	})
+
+	// Let the first check set the baseline before the lookups
+	time.Sleep(30 * time.Millisecond)
+	cache.Set("key1", "value1", 0)
+	cache.Get("key1")
+	cache.Get("missing1")
+	cache.Get("missing2")
+
+	select {
+	case event := <-events:
+		if !event.Breached || event.Threshold.Metric != HitRatio || event.Value >= 0.5 {
+			t.Errorf("OnThresholdHitRatio test failed. Expected: hit ratio below 0.5, Got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnThresholdHitRatio test failed. Expected: breach reported")
+	}
+}
@@ -0,0 +1,50 @@
+package bicache
+
+// tinyLFUWindowRatio is the share of the capacity used as TinyLFU admission window.
+const tinyLFUWindowRatio = 0.01
+
+// admitTinyLFU admits a new key into a full cache through a small admission window. New keys
+// always enter the window; when it overflows, its oldest key competes with the eviction
+// policy's victim, and whichever was accessed less often according to the frequency sketch
+// is evicted. The caller must hold the write lock.
+func (c *BiCache) admitTinyLFU(key interface{}) bool {
+	if c.sketch == nil {
+		return true
+	}
+
+	maxWindow := int(float64(c.capacity) * tinyLFUWindowRatio)
+	if maxWindow < 1 {
+		maxWindow = 1
+	}
+
+	key = c.mapKey(key)
+	c.window.add(key)
+	if c.window.len() <= maxWindow {
+		// The new key takes the place of the main victim
+		if victim, found := c.tinyLFUVictim(key); found {
+			c.evictEntry(victim)
+		}
+		return true
+	}
+
+	candidate := c.window.victims(1)[0]
+	c.window.remove(candidate)
+
+	victim, found := c.tinyLFUVictim(key)
+	if found && c.sketch.estimate(candidate) > c.sketch.estimate(victim) {
+		candidate = victim
+	}
+
+	c.evictEntry(candidate)
+	return true
+}
+
+// tinyLFUVictim returns the next key the eviction policy removes that is outside the admission window.
+func (c *BiCache) tinyLFUVictim(key interface{}) (interface{}, bool) {
+	for _, victim := range c.victims(c.window.len() + 2) {
+		if victim != key && !c.window.contains(victim) {
+			return victim, true
+		}
+	}
+	return nil, false
+}
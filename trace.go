@@ -0,0 +1,67 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// Span describes a traced Get, including the tier that served it.
+type Span struct {
+	// Context is the context of the traced call, for parenting the span.
+	Context   context.Context
+	Operation string
+	Key       interface{}
+	Tier      Tier
+	Hit       bool
+	Start     time.Time
+	Duration  time.Duration
+}
+
+// TraceFunc receives a span for every traced operation, for example to record it
+// with a tracing library using Tier as an attribute.
+type TraceFunc func(span Span)
+
+// TierMetrics holds the hits served by a tier and the time spent serving them.
+type TierMetrics struct {
+	Hits    int64
+	Latency time.Duration
+}
+
+// SetTracer registers a function that is called with a span after every Get.
+func (c *BiCache) SetTracer(trace TraceFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tracer = trace
+}
+
+// Tier returns the hits served by tier and their total latency.
+func (m CacheMetrics) Tier(tier Tier) TierMetrics {
+	switch tier {
+	case TierMemory:
+		return TierMetrics{Hits: m.Hits, Latency: m.HitLatency}
+	case TierLoader:
+		return TierMetrics{Hits: m.LoadSuccess, Latency: m.LoadLatency}
+	default:
+		return TierMetrics{}
+	}
+}
+
+// trace reports a finished Get to the tracer, if one is registered.
+func (c *BiCache) trace(ctx context.Context, key interface{}, tier Tier, hit bool, start time.Time) {
+	c.mu.RLock()
+	tracer := c.tracer
+	c.mu.RUnlock()
+
+	if tracer != nil {
+		tracer(Span{
+			Context:   ctx,
+			Operation: "get",
+			Key:       key,
+			Tier:      tier,
+			Hit:       hit,
+			Start:     start,
+			Duration:  time.Since(start),
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_Tracer(t *testing.T) {
+	cache := NewBiCache(5, time.Second)
+	cache.RegisterLoader("", func(key interface{}) (interface{}, time.Duration, error) {
+		time.Sleep(time.Millisecond * 10)
+		return "value", time.Minute, nil
+	})
+
+	var spans []Span
+	cache.SetTracer(func(span Span) {
+		spans = append(spans, span)
+	})
+
+	// The first Get is served by the loader, the second from memory
+	cache.Get("key1")
+	cache.Get("key1")
+
+	if len(spans) != 2 || spans[0].Tier != TierLoader || spans[1].Tier != TierMemory || !spans[0].Hit || !spans[1].Hit {
+		t.Fatalf("Tracer test failed. Expected: loader then memory hits, Got: %+v", spans)
+	}
+	if spans[0].Duration < time.Millisecond*10 {
+		t.Errorf("Tracer test failed. Expected: loader span >=10ms, Got: %v", spans[0].Duration)
+	}
+
+	metrics := cache.GetMetrics()
+	if loader := metrics.Tier(TierLoader); loader.Hits != 1 || loader.Latency < time.Millisecond*10 {
+		t.Errorf("Tracer test (loader metrics) failed. Got: %+v", loader)
+	}
+	if memory := metrics.Tier(TierMemory); memory.Hits != 1 {
+		t.Errorf("Tracer test (memory metrics) failed. Got: %+v", memory)
+	}
+}
@@ -0,0 +1,58 @@
+package bicache
+
+import (
+	"context"
+	"time"
+)
+
+// GetWithExpiration is like Get and also returns when the entry expires, or becomes older than
+// the maximum staleness if that is earlier. The expiration time is zero for entries that never
+// expire.
+func (c *BiCache) GetWithExpiration(key interface{}) (interface{}, time.Time, bool) {
+	entry, _, err := c.lookup(context.Background(), key)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return entry.Value, c.expiresAt(entry), true
+}
+
+// TTL returns the remaining lifetime of the entry stored under key, bounded by the maximum
+// staleness, or 0 if it never expires. Like Peek, it doesn't record an access or count a hit
+// or miss. The global expiration, which depends on the access time, is not taken into account.
+func (c *BiCache) TTL(key interface{}) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	entry, exists := c.cacheMap[c.mapKey(key)]
+	if !exists || !c.live(key, entry, now) {
+		return 0, false
+	}
+	expiresAt := c.expiresAt(entry)
+	if expiresAt.IsZero() {
+		return 0, true
+	}
+	return expiresAt.Sub(now), true
+}
+
+// GetWithExpiration is like Get and also returns when the entry expires.
+func (c *ShardedBiCache) GetWithExpiration(key interface{}) (interface{}, time.Time, bool) {
+	return c.Shard(key).GetWithExpiration(key)
+}
+
+// TTL returns the remaining lifetime of the entry stored under key, or 0 if it never expires.
+func (c *ShardedBiCache) TTL(key interface{}) (time.Duration, bool) {
+	return c.Shard(key).TTL(key)
+}
+
+// GetWithExpiration is the typed variant of BiCache.GetWithExpiration.
+func (c *TypedBiCache[K, V]) GetWithExpiration(key K) (V, time.Time, bool) {
+	value, expiresAt, found := c.cache.GetWithExpiration(key)
+	typed, found := typedValue[V](value, found)
+	return typed, expiresAt, found
+}
+
+// TTL returns the remaining lifetime of the entry stored under key, or 0 if it never expires.
+func (c *TypedBiCache[K, V]) TTL(key K) (time.Duration, bool) {
+	return c.cache.TTL(key)
+}
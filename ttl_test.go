@@ -0,0 +1,30 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_TTL(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.Set("key1", "value1", time.Hour)
+	cache.Set("key2", "value2", 0)
+
+	value, expiresAt, found := cache.GetWithExpiration("key1")
+	if !found || value != "value1" || time.Until(expiresAt) <= 59*time.Minute {
+		t.Errorf("GetWithExpiration test failed. Got: %v, %v, %v", value, expiresAt, found)
+	}
+	if _, expiresAt, _ := cache.GetWithExpiration("key2"); !expiresAt.IsZero() {
+		t.Errorf("GetWithExpiration test failed. Expected: no expiration, Got: %v", expiresAt)
+	}
+
+	if ttl, found := cache.TTL("key1"); !found || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("TTL test failed. Expected: about an hour, Got: %v", ttl)
+	}
+	if ttl, found := cache.TTL("key2"); !found || ttl != 0 {
+		t.Errorf("TTL test failed. Expected: 0 for no expiration, Got: %v", ttl)
+	}
+	if _, found := cache.TTL("missing"); found {
+		t.Errorf("TTL test failed. Expected: missing key not found")
+	}
+}
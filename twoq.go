@@ -0,0 +1,143 @@
+package bicache
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// twoQInRatio is the share of the capacity the 2Q A1in queue may hold before it is evicted from first.
+	twoQInRatio = 0.25
+	// twoQOutRatio is the number of evicted A1in keys 2Q remembers, as a share of the capacity.
+	twoQOutRatio = 0.5
+)
+
+// 2Q queues: new keys, ghosts of keys evicted from A1in, and keys seen again.
+const (
+	twoQA1in = iota
+	twoQA1out
+	twoQAm
+)
+
+// twoQ is the full 2Q algorithm. New keys enter the FIFO queue A1in, where repeated reads
+// don't count. Keys removed from A1in are remembered in the ghost queue A1out, and only a key
+// written again while in A1out enters the LRU queue Am, so scans never reach Am.
+type twoQ struct {
+	mu       sync.Mutex
+	queues   [3]*list.List
+	elements map[interface{}]*list.Element
+	maxIn    int
+	maxOut   int
+}
+
+type twoQItem struct {
+	key   interface{}
+	queue int
+}
+
+func newTwoQ(capacity int) *twoQ {
+	q := &twoQ{elements: make(map[interface{}]*list.Element)}
+	for i := range q.queues {
+		q.queues[i] = list.New()
+	}
+	q.setCapacity(capacity)
+	return q
+}
+
+func (q *twoQ) setCapacity(capacity int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.maxIn = maxInt(int(float64(capacity)*twoQInRatio), 1)
+	q.maxOut = maxInt(int(float64(capacity)*twoQOutRatio), 1)
+	q.trimOut()
+}
+
+// add puts new keys in A1in, keys remembered in A1out in Am, and refreshes keys in Am.
+func (q *twoQ) add(key interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	element, exists := q.elements[key]
+	if !exists {
+		q.move(key, twoQA1in)
+		return
+	}
+
+	switch element.Value.(*twoQItem).queue {
+	case twoQA1out:
+		q.move(key, twoQAm)
+	case twoQAm:
+		q.queues[twoQAm].MoveToFront(element)
+	}
+}
+
+// touch refreshes keys in Am. Reads of keys in A1in are treated as correlated and ignored.
+func (q *twoQ) touch(key interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	element, exists := q.elements[key]
+	if !exists {
+		q.move(key, twoQA1in)
+		return
+	}
+	if element.Value.(*twoQItem).queue == twoQAm {
+		q.queues[twoQAm].MoveToFront(element)
+	}
+}
+
+// remove remembers keys removed from A1in in A1out and forgets keys removed from Am.
+func (q *twoQ) remove(key interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	element, exists := q.elements[key]
+	if !exists {
+		return
+	}
+
+	switch element.Value.(*twoQItem).queue {
+	case twoQA1in:
+		q.move(key, twoQA1out)
+		q.trimOut()
+	case twoQAm:
+		q.queues[twoQAm].Remove(element)
+		delete(q.elements, key)
+	}
+}
+
+// victims returns keys from the back of A1in while it is over its share, otherwise from Am first.
+func (q *twoQ) victims(n int) []interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	order := []int{twoQAm, twoQA1in}
+	if q.queues[twoQA1in].Len() > q.maxIn || q.queues[twoQAm].Len() == 0 {
+		order = []int{twoQA1in, twoQAm}
+	}
+
+	keys := make([]interface{}, 0, n)
+	for _, queue := range order {
+		for element := q.queues[queue].Back(); element != nil && len(keys) < n; element = element.Prev() {
+			keys = append(keys, element.Value.(*twoQItem).key)
+		}
+	}
+	return keys
+}
+
+// move puts key at the front of queue. The caller must hold the lock.
+func (q *twoQ) move(key interface{}, queue int) {
+	if element, exists := q.elements[key]; exists {
+		q.queues[element.Value.(*twoQItem).queue].Remove(element)
+	}
+	q.elements[key] = q.queues[queue].PushFront(&twoQItem{key: key, queue: queue})
+}
+
+// trimOut forgets the oldest ghosts beyond the size of A1out. The caller must hold the lock.
+func (q *twoQ) trimOut() {
+	for q.queues[twoQA1out].Len() > q.maxOut {
+		item := q.queues[twoQA1out].Remove(q.queues[twoQA1out].Back()).(*twoQItem)
+		delete(q.elements, item.key)
+	}
+}
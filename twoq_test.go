@@ -0,0 +1,33 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_TwoQEviction(t *testing.T) {
+	cache := NewBiCache(8, time.Minute)
+	cache.SetEvictionPolicy(TwoQEviction)
+
+	// Keys written again after leaving A1in are promoted to Am
+	for i := 0; i < 8; i++ {
+		cache.Set(i, "value", 0)
+	}
+	cache.Set("hot", "value", 0)
+	cache.Set(0, "value", 0)
+
+	// A scan only cycles through A1in
+	for i := 100; i < 120; i++ {
+		cache.Set(i, "value", 0)
+	}
+
+	if _, found := cache.Get(0); !found {
+		t.Errorf("TwoQEviction test failed. Expected: key 0 kept in Am")
+	}
+	if _, found := cache.Get(119); !found {
+		t.Errorf("TwoQEviction test failed. Expected: last scanned key kept")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 8 {
+		t.Errorf("TwoQEviction test failed. Expected: 8 entries, Got: %v", metrics.EntriesCount)
+	}
+}
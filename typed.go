@@ -0,0 +1,69 @@
+package bicache
+
+import "time"
+
+// TypedBiCache is a type-safe view of a BiCache for keys of type K and values of type V.
+type TypedBiCache[K comparable, V any] struct {
+	cache *BiCache
+}
+
+func NewTypedBiCache[K comparable, V any](capacity int, cleanupInterval time.Duration) *TypedBiCache[K, V] {
+	return &TypedBiCache[K, V]{cache: NewBiCache(capacity, cleanupInterval)}
+}
+
+// Untyped returns the underlying cache, for configuration and features without a typed variant.
+func (c *TypedBiCache[K, V]) Untyped() *BiCache {
+	return c.cache
+}
+
+func (c *TypedBiCache[K, V]) Get(key K) (V, bool) {
+	value, found := c.cache.Get(key)
+	return typedValue[V](value, found)
+}
+
+// GetWithInfo is the typed variant of BiCache.GetWithInfo.
+func (c *TypedBiCache[K, V]) GetWithInfo(key K) (V, EntryInfo, bool) {
+	value, info, found := c.cache.GetWithInfo(key)
+	typed, found := typedValue[V](value, found)
+	return typed, info, found
+}
+
+func (c *TypedBiCache[K, V]) Set(key K, value V, expiration time.Duration) {
+	c.cache.Set(key, value, expiration)
+}
+
+// SetWithOptions is the typed variant of BiCache.SetWithOptions.
+func (c *TypedBiCache[K, V]) SetWithOptions(key K, value V, expiration time.Duration, opts ...SetOption) error {
+	return c.cache.SetWithOptions(key, value, expiration, opts...)
+}
+
+func (c *TypedBiCache[K, V]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+// RegisterLoader is the typed variant of BiCache.RegisterLoader.
+func (c *TypedBiCache[K, V]) RegisterLoader(prefix string, loader func(key K) (V, time.Duration, error)) {
+	c.cache.RegisterLoader(prefix, func(key interface{}) (interface{}, time.Duration, error) {
+		return loader(key.(K))
+	})
+}
+
+// SetLoader is the typed variant of BiCache.SetLoader.
+func (c *TypedBiCache[K, V]) SetLoader(loader func(key K) (V, time.Duration, error)) {
+	c.RegisterLoader("", loader)
+}
+
+// typedValue converts a value returned by the untyped cache to V.
+// Values of another type, for example after compression, are reported as not found.
+func typedValue[V any](value interface{}, found bool) (V, bool) {
+	var zero V
+	if !found || value == nil {
+		return zero, found
+	}
+
+	typed, ok := value.(V)
+	if !ok {
+		return zero, false
+	}
+	return typed, true
+}
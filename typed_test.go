@@ -0,0 +1,41 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypedBiCache_SetGet(t *testing.T) {
+	type user struct {
+		Name string
+	}
+
+	cache := NewTypedBiCache[string, user](5, time.Second)
+
+	// Set a value in the cache
+	cache.Set("user:1", user{Name: "alice"}, time.Minute)
+
+	// Get the value without a type assertion
+	result, found := cache.Get("user:1")
+	if !found || result.Name != "alice" {
+		t.Errorf("TypedBiCache test failed. Expected: 'alice', Got: '%v'", result)
+	}
+
+	// A missing key returns the zero value
+	if result, found := cache.Get("user:2"); found || result != (user{}) {
+		t.Errorf("TypedBiCache test (missing key) failed. Expected: zero value, Got: '%v'", result)
+	}
+
+	// Typed loaders receive typed keys
+	cache.RegisterLoader("admin:", func(key string) (user, time.Duration, error) {
+		return user{Name: key}, time.Minute, nil
+	})
+	if result, _, found := cache.GetWithInfo("admin:root"); !found || result.Name != "admin:root" {
+		t.Errorf("TypedBiCache test (loader) failed. Expected: 'admin:root', Got: '%v'", result)
+	}
+
+	cache.Delete("user:1")
+	if _, found := cache.Get("user:1"); found {
+		t.Errorf("TypedBiCache test (delete) failed. Expected: not found")
+	}
+}
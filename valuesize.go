@@ -0,0 +1,15 @@
+package bicache
+
+import "errors"
+
+// ErrValueTooLarge is returned when a value exceeds the size set with SetMaxValueSize.
+var ErrValueTooLarge = errors.New("bicache: value too large")
+
+// SetMaxValueSize makes writes of values whose estimated size exceeds max bytes, after encoding
+// and compression, fail with ErrValueTooLarge. A max of 0 or less removes the limit.
+func (c *BiCache) SetMaxValueSize(max int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxValueSize = max
+}
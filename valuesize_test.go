@@ -0,0 +1,27 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_MaxValueSize(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	cache.SetMaxValueSize(8)
+
+	if err := cache.SetWithOptions("small", "12345678", 0); err != nil {
+		t.Errorf("SetMaxValueSize test failed. Expected: nil, Got: %v", err)
+	}
+	if err := cache.SetWithOptions("large", "123456789", 0); !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("SetMaxValueSize test failed. Expected: %v, Got: %v", ErrValueTooLarge, err)
+	}
+	if _, found := cache.Get("large"); found {
+		t.Errorf("SetMaxValueSize test failed. Expected: the large value not to be stored")
+	}
+
+	cache.SetMaxValueSize(0)
+	if err := cache.SetWithOptions("large", "123456789", 0); err != nil {
+		t.Errorf("SetMaxValueSize test failed. Expected: no limit, Got: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLoadShed is returned by ShedWhileCold when a load is shed because the cache is still cold.
+var ErrLoadShed = errors.New("bicache: load shed while the cache is cold")
+
+// Warmth tells how warmed up a cache is, for example to shed or slow down requests after a start
+// instead of sending every miss to the backend.
+type Warmth struct {
+	// FillRatio is the share of the capacity, maximum weight or memory budget in use.
+	FillRatio float64
+	// HitRatio is the share of lookups that were hits over the last one to two cleanup
+	// intervals, or since the cache was created during the first ones.
+	HitRatio float64
+	// Lookups is the number of lookups HitRatio was measured over.
+	Lookups int64
+}
+
+// ShedFunc decides whether a miss of key may be loaded given the warmth of the cache.
+// It can block to slow the caller down; a non-nil error skips the load and is returned
+// to the caller instead.
+type ShedFunc func(ctx context.Context, key interface{}, warmth Warmth) error
+
+// Warmth returns the current fill ratio and recent hit ratio of the cache.
+func (c *BiCache) Warmth() Warmth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	warmth := Warmth{FillRatio: c.fillRatio()}
+	baseline := c.warmthBaseline
+	hits := c.metrics.Hits.Load() - baseline.hits
+	warmth.Lookups = hits + c.metrics.Misses.Load() - baseline.misses
+	if warmth.Lookups > 0 {
+		warmth.HitRatio = float64(hits) / float64(warmth.Lookups)
+	}
+	return warmth
+}
+
+// SetShedder registers a function that is called before a miss is loaded, so the application
+// can shed or slow down requests while the cache is cold. Background loads, such as refreshes
+// and predictions, are not passed to it. A nil shed removes it.
+func (c *BiCache) SetShedder(shed ShedFunc) {
+	c.shedder.Store(shed)
+}
+
+// ShedWhileCold returns a ShedFunc that sheds loads with ErrLoadShed until the fill ratio
+// reaches minFill or the recent hit ratio reaches minHitRatio.
+func ShedWhileCold(minFill, minHitRatio float64) ShedFunc {
+	return func(ctx context.Context, key interface{}, warmth Warmth) error {
+		if warmth.FillRatio >= minFill || warmth.HitRatio >= minHitRatio {
+			return nil
+		}
+		return ErrLoadShed
+	}
+}
+
+// shed passes a load of key to the shedder, if one is registered, and counts shed loads.
+func (c *BiCache) shed(ctx context.Context, key interface{}) error {
+	shed, _ := c.shedder.Load().(ShedFunc)
+	if shed == nil {
+		return nil
+	}
+	if err := shed(ctx, key, c.Warmth()); err != nil {
+		c.metrics.LoadShed.Add(1)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_Shedder(t *testing.T) {
+	cache := NewBiCache(4, time.Minute)
+	loads := 0
+	cache.RegisterLoader("user:", func(key interface{}) (interface{}, time.Duration, error) {
+		loads++
+		return "loaded", 0, nil
+	})
+	cache.SetShedder(ShedWhileCold(0.5, 0.9))
+
+	if _, err := cache.GetWithError("user:1"); !errors.Is(err, ErrLoadShed) {
+		t.Errorf("Shedder test failed. Expected: ErrLoadShed, Got: %v", err)
+	}
+	if loads != 0 {
+		t.Errorf("Shedder test failed. Expected: no loads, Got: %d", loads)
+	}
+
+	// Half full, the cache is warm enough
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	if warmth := cache.Warmth(); warmth.FillRatio != 0.5 || warmth.Lookups != 1 || warmth.HitRatio != 0 {
+		t.Errorf("Warmth test failed. Got: %+v", warmth)
+	}
+	if value, err := cache.GetWithError("user:1"); err != nil || value != "loaded" {
+		t.Errorf("Shedder test failed. Expected: loaded, Got: %v, %v", value, err)
+	}
+
+	if shed := cache.GetMetrics().LoadShed; shed != 1 {
+		t.Errorf("Shedder test failed. Expected: 1 shed load, Got: %d", shed)
+	}
+}
@@ -0,0 +1,204 @@
+package bicache
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request body.
+const WebhookSignatureHeader = "X-Bicache-Signature"
+
+// WebhookConfig configures a WebhookSink.
+type WebhookConfig struct {
+	URL string
+	// Secret signs each request body with HMAC-SHA256 when set.
+	Secret []byte
+	// BatchSize is the maximum number of events per request, 100 if not set.
+	BatchSize int
+	// FlushInterval is how long events may wait for a batch to fill, 1s if not set.
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries of a failed request.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on each retry, 100ms if not set.
+	RetryBackoff time.Duration
+	// Client sends the requests, http.DefaultClient if not set.
+	Client *http.Client
+	// OnError is called with the events that could not be delivered.
+	OnError func(events []WebhookEvent, err error)
+}
+
+// WebhookEvent is the JSON representation of a cache event sent to the webhook.
+type WebhookEvent struct {
+	Event string `json:"event"`
+	Key   string `json:"key"`
+	// Expiration is nil for entries that never expire and for events without an entry.
+	Expiration *time.Time `json:"expiration,omitempty"`
+	Time       time.Time  `json:"time"`
+}
+
+// WebhookSink batches cache events and POSTs them as JSON arrays to a URL.
+type WebhookSink struct {
+	config    WebhookConfig
+	events    chan WebhookEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+	dropped   int64
+}
+
+func NewWebhookSink(config WebhookConfig) *WebhookSink {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Millisecond * 100
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	sink := &WebhookSink{
+		config: config,
+		events: make(chan WebhookEvent, config.BatchSize*10),
+		done:   make(chan struct{}),
+	}
+	go sink.run()
+
+	return sink
+}
+
+// Handle queues a cache event, it can be registered with AddCacheEventHandler.
+// Events are dropped if the queue is full.
+func (s *WebhookSink) Handle(event CacheEvent, key interface{}, entry CacheEntry) {
+	webhookEvent := WebhookEvent{
+		Event: event.String(),
+		Key:   fmt.Sprint(key),
+		Time:  time.Now(),
+	}
+	if !entry.Expiration.IsZero() {
+		expiration := entry.Expiration
+		webhookEvent.Expiration = &expiration
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		atomic.AddInt64(&s.dropped, 1)
+		return
+	}
+
+	select {
+	case s.events <- webhookEvent:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full or the sink was closed.
+func (s *WebhookSink) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Close stops accepting events and delivers the queued ones. Events handled afterwards are dropped.
+func (s *WebhookSink) Close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.events)
+		s.mu.Unlock()
+		<-s.done
+	})
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]WebhookEvent, 0, s.config.BatchSize)
+	for {
+		select {
+		case event, ok := <-s.events:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= s.config.BatchSize {
+				s.flush(batch)
+				batch = make([]WebhookEvent, 0, s.config.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = make([]WebhookEvent, 0, s.config.BatchSize)
+			}
+		}
+	}
+}
+
+// flush delivers a batch, retrying with exponential backoff.
+func (s *WebhookSink) flush(batch []WebhookEvent) {
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(batch)
+	if err == nil {
+		backoff := s.config.RetryBackoff
+		for attempt := 0; ; attempt++ {
+			if err = s.post(body); err == nil || attempt >= s.config.MaxRetries {
+				break
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	if err != nil && s.config.OnError != nil {
+		s.config.OnError(batch, err)
+	}
+}
+
+func (s *WebhookSink) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.config.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookBody(s.config.Secret, body))
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("bicache: webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookBody returns the hex encoded HMAC-SHA256 of body, for verifying webhook requests.
+func SignWebhookBody(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
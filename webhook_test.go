@@ -0,0 +1,88 @@
+package bicache
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookSink(t *testing.T) {
+	secret := []byte("secret")
+
+	var mu sync.Mutex
+	var requests int
+	var received []WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+
+		// Fail the first request to exercise the retry
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.Header.Get(WebhookSignatureHeader) != "sha256="+SignWebhookBody(secret, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var events []WebhookEvent
+		json.Unmarshal(body, &events)
+		received = append(received, events...)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(WebhookConfig{
+		URL:          server.URL,
+		Secret:       secret,
+		BatchSize:    10,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+
+	cache := NewBiCache(5, time.Second)
+	cache.AddCacheEventHandler(sink.Handle)
+
+	cache.Set("key1", "value1", time.Minute)
+	cache.Delete("key1")
+
+	// Wait for the asynchronous handlers and deliver the batch
+	time.Sleep(time.Millisecond * 50)
+	sink.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 2 || len(received) != 2 {
+		t.Fatalf("WebhookSink test failed. Expected: 2 requests and 2 events, Got: %v requests and %v events", requests, len(received))
+	}
+
+	// Handlers run asynchronously, so the events may arrive in any order
+	events := map[string]WebhookEvent{}
+	for _, event := range received {
+		events[event.Event] = event
+	}
+	if events["set"].Key != "key1" || events["delete"].Key != "key1" {
+		t.Errorf("WebhookSink test failed. Expected: set and delete of key1, Got: %+v", received)
+	}
+
+	// Only events of expiring entries carry an expiration
+	if events["set"].Expiration == nil || events["delete"].Expiration != nil {
+		t.Errorf("WebhookSink test failed. Expected: an expiration only for the set, Got: %+v", received)
+	}
+}
+
+func TestWebhookEvent_OmitsExpiration(t *testing.T) {
+	data, _ := json.Marshal(WebhookEvent{Event: "delete", Key: "key1"})
+	if strings.Contains(string(data), "expiration") {
+		t.Errorf("WebhookEvent test failed. Expected: no expiration, Got: %s", data)
+	}
+}
@@ -0,0 +1,106 @@
+package bicache
+
+// WeigherFunc returns the weight of a value, for example its size in bytes.
+type WeigherFunc func(key interface{}, value interface{}) int64
+
+// SetWeigher sets the function that weighs values written from now on. Without one, every entry weighs 1.
+func (c *BiCache) SetWeigher(weigher WeigherFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.weigher = weigher
+}
+
+// reweigh weighs the stored entries again with the current weigher. Entries whose values can't
+// be decoded keep their weight. The caller must hold the write lock.
+func (c *BiCache) reweigh() {
+	for stored, entry := range c.cacheMap {
+		weight := int64(1)
+		if c.weigher != nil {
+			value, err := c.decodeStored(entry.Value)
+			if err != nil {
+				continue
+			}
+			weight = c.weigher(c.externalKey(stored), value)
+		}
+		c.metrics.TotalWeight.Add(weight - entry.weight)
+		entry.weight = weight
+		c.cacheMap[stored] = entry
+	}
+}
+
+// measuresMemory reports whether entries are measured, which takes a walk over their values.
+// The caller must hold the lock.
+func (c *BiCache) measuresMemory() bool {
+	return c.maxMemory > 0 || c.admission == CostBenefitAdmission
+}
+
+// measure estimates the memory of the entries stored while it was not measured, if it is now.
+// The caller must hold the write lock.
+func (c *BiCache) measure() {
+	if !c.measuresMemory() {
+		return
+	}
+	for stored, entry := range c.cacheMap {
+		if entry.memory == 0 {
+			entry.memory = entryMemory(stored, entry)
+			c.metrics.MemoryBytes.Add(entry.memory)
+			c.cacheMap[stored] = entry
+		}
+	}
+}
+
+// SetMaxWeight limits the cache by the total weight of its entries instead of their number,
+// evicting entries until the total is within maxWeight. A value of 0 or less restores the
+// limit by entry count.
+func (c *BiCache) SetMaxWeight(maxWeight int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxWeight = maxWeight
+	if c.overCapacity() {
+		c.cleanup()
+		c.evict(nil)
+	}
+}
+
+// SetMaxMemory limits the estimated memory used by the entries, including keys and per-entry
+// overhead, to maxMemory bytes in addition to the limit by entry count or weight. Estimates are
+// rough, based on the stored, possibly compressed values. A value of 0 or less removes the limit.
+// Entries are only measured while a limit or cost-benefit admission is set, so the first limit
+// measures the stored entries.
+func (c *BiCache) SetMaxMemory(maxMemory int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxMemory = maxMemory
+	c.measure()
+	if c.overCapacity() {
+		c.cleanup()
+		c.evict(nil)
+	}
+}
+
+// overCapacity reports whether the cache holds more than its capacity or maximum weight,
+// or uses more than its memory budget. The caller must hold the lock.
+func (c *BiCache) overCapacity() bool {
+	if c.maxMemory > 0 && c.metrics.MemoryBytes.Load() > c.maxMemory {
+		return true
+	}
+	if c.maxWeight > 0 {
+		return c.metrics.TotalWeight.Load() > c.maxWeight
+	}
+	return c.capacity > 0 && len(c.cacheMap) > c.capacity
+}
+
+// fits reports whether a new entry can be stored without evicting another one.
+// The caller must hold the lock.
+func (c *BiCache) fits(entry CacheEntry) bool {
+	if c.maxMemory > 0 && c.metrics.MemoryBytes.Load()+entry.memory > c.maxMemory {
+		return false
+	}
+	if c.maxWeight > 0 {
+		return c.metrics.TotalWeight.Load()+entry.weight <= c.maxWeight
+	}
+	return c.capacity <= 0 || len(c.cacheMap) < c.capacity
+}
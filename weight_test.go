@@ -0,0 +1,73 @@
+package bicache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBiCache_MaxWeight(t *testing.T) {
+	cache := NewBiCache(100, time.Minute)
+	cache.SetWeigher(func(key interface{}, value interface{}) int64 {
+		return int64(len(value.(string)))
+	})
+	cache.SetMaxWeight(10)
+
+	cache.Set("key1", "aaaa", 0)
+	cache.Set("key2", "bbbb", 0)
+	if metrics := cache.GetMetrics(); metrics.TotalWeight != 8 {
+		t.Errorf("MaxWeight test failed. Expected: weight 8, Got: %v", metrics.TotalWeight)
+	}
+
+	// A large value evicts as many entries as needed
+	cache.Set("key3", "cccccccc", 0)
+	if _, found := cache.Get("key1"); found {
+		t.Errorf("MaxWeight test failed. Expected: key1 evicted")
+	}
+	if _, found := cache.Get("key2"); found {
+		t.Errorf("MaxWeight test failed. Expected: key2 evicted")
+	}
+	if metrics := cache.GetMetrics(); metrics.TotalWeight != 8 || metrics.EntriesCount != 1 {
+		t.Errorf("MaxWeight test failed. Expected: weight 8 in 1 entry, Got: %v in %v", metrics.TotalWeight, metrics.EntriesCount)
+	}
+
+	// Overwrites and deletes release the old weight
+	cache.Set("key3", "c", 0)
+	cache.Set("key4", "d", 0)
+	cache.Delete("key4")
+	if metrics := cache.GetMetrics(); metrics.TotalWeight != 1 {
+		t.Errorf("MaxWeight test failed. Expected: weight 1, Got: %v", metrics.TotalWeight)
+	}
+}
+
+func TestBiCache_MaxMemory(t *testing.T) {
+	cache := NewBiCache(100, time.Minute)
+
+	// Entries are not measured without a limit, and are when the first limit is set
+	cache.Set("key1", make([]byte, 1000), 0)
+	if memory := cache.GetMetrics().MemoryBytes; memory != 0 {
+		t.Errorf("MaxMemory test failed. Expected: 0 bytes without a limit, Got: %v", memory)
+	}
+	cache.SetMaxMemory(1 << 30)
+	perEntry := cache.GetMetrics().MemoryBytes
+	if perEntry < 1000 {
+		t.Fatalf("MaxMemory test failed. Expected: at least 1000 bytes, Got: %v", perEntry)
+	}
+
+	// Room for two entries of the same size
+	cache.SetMaxMemory(perEntry*2 + perEntry/2)
+	cache.Set("key2", make([]byte, 1000), 0)
+	cache.Set("key3", make([]byte, 1000), 0)
+
+	if _, found := cache.Get("key1"); found {
+		t.Errorf("MaxMemory test failed. Expected: key1 evicted")
+	}
+	if metrics := cache.GetMetrics(); metrics.EntriesCount != 2 || metrics.MemoryBytes != perEntry*2 {
+		t.Errorf("MaxMemory test failed. Expected: 2 entries using %v bytes, Got: %v using %v", perEntry*2, metrics.EntriesCount, metrics.MemoryBytes)
+	}
+
+	cache.Delete("key2")
+	cache.Delete("key3")
+	if metrics := cache.GetMetrics(); metrics.MemoryBytes != 0 {
+		t.Errorf("MaxMemory test failed. Expected: 0 bytes, Got: %v", metrics.MemoryBytes)
+	}
+}
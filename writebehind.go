@@ -0,0 +1,199 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrWriteBehindQueueFull is returned by writes that could not be queued for the write-behind
+// store because too many are pending. The value is not cached.
+var ErrWriteBehindQueueFull = errors.New("bicache: write-behind queue is full")
+
+// Mutation is a write or delete waiting to be flushed to the write-behind store.
+type Mutation struct {
+	Key        interface{}
+	Value      interface{}
+	Expiration time.Duration
+	Delete     bool
+}
+
+// WriteBehindStore persists batches of mutations, for example in one database transaction.
+// Mutations of the same key are flushed in the order they were made.
+type WriteBehindStore interface {
+	WriteBatch(ctx context.Context, mutations []Mutation) error
+}
+
+// WriteBehindConfig configures write-behind.
+type WriteBehindConfig struct {
+	// Store receives the batches. A nil Store disables write-behind.
+	Store WriteBehindStore
+	// QueueSize is the number of mutations that may wait to be flushed, 1024 if not set.
+	QueueSize int
+	// BatchSize is the maximum number of mutations flushed at once, 100 if not set.
+	BatchSize int
+	// FlushInterval is how long a mutation may wait for its batch to fill up, one second if not set.
+	FlushInterval time.Duration
+	// Workers is the number of batches flushed concurrently, 1 if not set.
+	// Keys are spread over the workers so the mutations of each key stay in order.
+	Workers int
+	// MaxRetries is how often a failed batch is retried before it is dropped, 3 if not set
+	// and no retries if negative.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled after each retry, 100ms if not set.
+	RetryBackoff time.Duration
+}
+
+type writeBehind struct {
+	config  WriteBehindConfig
+	workers []chan Mutation
+	done    chan struct{}
+}
+
+// SetWriteBehind makes Set, SetWithOptions, SetContext and Delete queue their mutations, which
+// background workers flush to config.Store in batches. Writes fail with ErrWriteBehindQueueFull
+// instead of being cached when the queue is full. Batches that still fail after the retries are
+// passed to the error handler; their mutations and the ones that could not be queued are counted
+// in the WriteBehindErrors metric. Values loaded by loaders and entries rejected by the cache
+// policy or the validator are not written. Replacing or disabling write-behind, and Close, flush
+// the pending mutations first.
+func (c *BiCache) SetWriteBehind(config WriteBehindConfig) {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 100 * time.Millisecond
+	}
+
+	c.mu.Lock()
+	previous := c.writeBehind
+	c.writeBehind = nil
+	if config.Store != nil && !c.closed.Load() {
+		w := &writeBehind{
+			config:  config,
+			workers: make([]chan Mutation, config.Workers),
+			done:    make(chan struct{}, config.Workers),
+		}
+		queueSize := (config.QueueSize + config.Workers - 1) / config.Workers
+		for i := range w.workers {
+			w.workers[i] = make(chan Mutation, queueSize)
+			go w.run(c, w.workers[i])
+		}
+		c.writeBehind = w
+	}
+	c.mu.Unlock()
+
+	previous.stop()
+}
+
+// queueWriteBehind queues a mutation for the write-behind store, if one is set.
+// The caller must hold the write lock.
+func (c *BiCache) queueWriteBehind(mutation Mutation) error {
+	if c.writeBehind == nil {
+		return nil
+	}
+
+	queue := c.writeBehind.workers[c.hasher.hash(mutation.Key)%uint64(len(c.writeBehind.workers))]
+	select {
+	case queue <- mutation:
+		return nil
+	default:
+		c.metrics.WriteBehindErrors.Add(1)
+		return ErrWriteBehindQueueFull
+	}
+}
+
+// run flushes the mutations of one worker in batches until its queue is closed.
+func (w *writeBehind) run(c *BiCache, queue chan Mutation) {
+	defer func() { w.done <- struct{}{} }()
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Mutation, 0, w.config.BatchSize)
+	for {
+		select {
+		case mutation, ok := <-queue:
+			if !ok {
+				w.flush(c, batch)
+				return
+			}
+			batch = append(batch, mutation)
+			if len(batch) < w.config.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+
+		w.flush(c, batch)
+		batch = make([]Mutation, 0, w.config.BatchSize)
+	}
+}
+
+// flush writes a batch to the store, retrying with backoff if it fails.
+func (w *writeBehind) flush(c *BiCache, batch []Mutation) {
+	if len(batch) == 0 {
+		return
+	}
+
+	backoff := w.config.RetryBackoff
+	err := w.config.Store.WriteBatch(context.Background(), batch)
+	for retry := 0; err != nil && retry < w.config.MaxRetries; retry++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = w.config.Store.WriteBatch(context.Background(), batch)
+	}
+
+	if err != nil {
+		c.metrics.WriteBehindErrors.Add(int64(len(batch)))
+		for _, mutation := range batch {
+			c.reportError("writebehind", mutation.Key, err)
+		}
+		return
+	}
+	c.metrics.WriteBehinds.Add(int64(len(batch)))
+}
+
+// stop flushes the queued mutations and stops the workers.
+func (w *writeBehind) stop() {
+	if w == nil {
+		return
+	}
+	for _, queue := range w.workers {
+		close(queue)
+	}
+	for range w.workers {
+		<-w.done
+	}
+}
+
+// pending returns the number of mutations not flushed yet.
+func (w *writeBehind) pending() int64 {
+	if w == nil {
+		return 0
+	}
+	var pending int64
+	for _, queue := range w.workers {
+		pending += int64(len(queue))
+	}
+	return pending
+}
+
+// SetWriteBehind enables write-behind on every shard, each with its own workers and queue.
+func (c *ShardedBiCache) SetWriteBehind(config WriteBehindConfig) {
+	for _, shard := range c.shards {
+		shard.SetWriteBehind(config)
+	}
+}
@@ -0,0 +1,108 @@
+package bicache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testWriteBehindStore struct {
+	mu        sync.Mutex
+	batches   [][]Mutation
+	failures  int
+	mutations map[interface{}]interface{}
+}
+
+func (s *testWriteBehindStore) WriteBatch(ctx context.Context, mutations []Mutation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("store unavailable")
+	}
+	s.batches = append(s.batches, append([]Mutation(nil), mutations...))
+	for _, mutation := range mutations {
+		if mutation.Delete {
+			delete(s.mutations, mutation.Key)
+		} else {
+			s.mutations[mutation.Key] = mutation.Value
+		}
+	}
+	return nil
+}
+
+func TestBiCache_WriteBehind(t *testing.T) {
+	cache := NewBiCache(100, time.Minute)
+	store := &testWriteBehindStore{failures: 1, mutations: make(map[interface{}]interface{})}
+	cache.SetWriteBehind(WriteBehindConfig{
+		Store:         store,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	})
+
+	cache.Set("key1", "value1", 0)
+	cache.Set("key2", "value2", 0)
+	cache.Set("key3", "value3", 0)
+	cache.Delete("key1")
+	cache.Set("key4", "value4", 0)
+	cache.GetOrLoad("loaded", 0, func() (interface{}, error) {
+		return "value", nil
+	})
+
+	if value, found := cache.Get("key2"); !found || value != "value2" {
+		t.Errorf("Write-behind test failed. Expected: value2 cached, Got: %v", value)
+	}
+
+	// Close flushes the last, partial batch
+	cache.Close()
+
+	if len(store.batches) != 3 || len(store.batches[0]) != 2 || len(store.batches[2]) != 1 {
+		t.Errorf("Write-behind test failed. Expected: 2 full batches and 1 partial batch, Got: %v", store.batches)
+	}
+	if len(store.mutations) != 3 || store.mutations["key2"] != "value2" || store.mutations["key4"] != "value4" {
+		t.Errorf("Write-behind test failed. Expected: key2 to key4 stored, Got: %v", store.mutations)
+	}
+	if metrics := cache.GetMetrics(); metrics.WriteBehinds != 5 || metrics.WriteBehindErrors != 0 || metrics.WriteBehindQueued != 0 {
+		t.Errorf("Write-behind test failed. Got: %+v", metrics)
+	}
+}
+
+func TestBiCache_WriteBehindQueueFull(t *testing.T) {
+	cache := NewBiCache(100, time.Minute)
+	defer cache.Close()
+
+	block := make(chan struct{})
+	cache.SetWriteBehind(WriteBehindConfig{
+		Store:      blockingWriteBehindStore(block),
+		QueueSize:  1,
+		BatchSize:  1,
+		MaxRetries: -1,
+	})
+	defer close(block)
+
+	// The first write is taken by the worker, the second fills the queue
+	cache.Set("key1", "value1", 0)
+	time.Sleep(10 * time.Millisecond)
+	cache.Set("key2", "value2", 0)
+
+	if err := cache.SetWithOptions("key3", "value3", 0); err != ErrWriteBehindQueueFull {
+		t.Errorf("Write-behind queue test failed. Expected: %v, Got: %v", ErrWriteBehindQueueFull, err)
+	}
+	if _, found := cache.Get("key3"); found {
+		t.Errorf("Write-behind queue test failed. Expected: key3 not cached")
+	}
+	if metrics := cache.GetMetrics(); metrics.WriteBehindQueued != 1 {
+		t.Errorf("Write-behind queue test failed. Expected: 1 queued, Got: %v", metrics.WriteBehindQueued)
+	}
+}
+
+type blockingWriteBehindStore chan struct{}
+
+func (s blockingWriteBehindStore) WriteBatch(ctx context.Context, mutations []Mutation) error {
+	<-s
+	return nil
+}
@@ -0,0 +1,97 @@
+package bicache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WriterFunc persists a value to the backing store of the cache, such as a database or Redis.
+type WriterFunc func(key, value interface{}) error
+
+// WriteError is returned by writes whose value the writer set with SetWriter failed to persist.
+// The value is not cached.
+type WriteError struct {
+	Key interface{}
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("bicache: writing entry %v through: %v", e.Key, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// SetWriter makes Set, SetWithOptions, SetContext and SetMany call writer with the key and value
+// before caching the value, so writes go through to a backing store. Values the cache refuses,
+// because an entry is read-only, a fencing token is stale, the value is too large or the cache policy
+// or the validator rejects it, are not written. If writer fails, the value is not cached and the write
+// fails with a *WriteError, which Set passes to the error handler. Values loaded by loaders are
+// not written, and neither are the conditional writes GetOrSet, Add, Replace, CompareAndSwap,
+// CompareAndDelete, Append and Prepend, which hold the lock while they write; callers using them
+// with a backing store must write to it themselves. A nil writer disables write-through.
+func (c *BiCache) SetWriter(writer WriterFunc) {
+	c.writer.Store(writer)
+}
+
+// setThrough persists a value with the writer, if one is set, and then stores it like set.
+// The lock is not held while the writer runs.
+func (c *BiCache) setThrough(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, opts ...SetOption) error {
+	opts = append(opts[:len(opts):len(opts)], withThrough)
+	if ok, err := c.writeThrough(ctx, key, value, expiration, opts); !ok {
+		return err
+	}
+	return c.set(ctx, key, value, expiration, opts...)
+}
+
+// writeThrough checks a write like store and persists it with the writer, if one is set, so the
+// backing store never receives a value the cache refuses. It reports whether the value should still
+// be stored. The caller must not hold the lock.
+func (c *BiCache) writeThrough(ctx context.Context, key interface{}, value interface{}, expiration time.Duration, opts []SetOption) (bool, error) {
+	writer, _ := c.writer.Load().(WriterFunc)
+	if writer == nil {
+		return true, nil
+	}
+	if c.closed.Load() {
+		return false, ErrClosed
+	}
+
+	c.mu.RLock()
+	_, ok, err := c.prepare(ctx, key, value, expiration, newSetOptions(opts))
+	c.mu.RUnlock()
+	if !ok {
+		return false, err
+	}
+
+	if err := writer(key, value); err != nil {
+		c.metrics.SetError.Add(1)
+		return false, &WriteError{Key: key, Err: err}
+	}
+	return true, nil
+}
+
+// withThrough marks a write made by a caller, which is passed on to the write-behind store.
+func withThrough(o *setOptions) {
+	o.through = true
+}
+
+// SetWriter sets the writer of every shard.
+func (c *ShardedBiCache) SetWriter(writer WriterFunc) {
+	for _, shard := range c.shards {
+		shard.SetWriter(writer)
+	}
+}
+
+// SetWriter is the typed variant of BiCache.SetWriter.
+func (c *TypedBiCache[K, V]) SetWriter(writer func(key K, value V) error) {
+	if writer == nil {
+		c.cache.SetWriter(nil)
+		return
+	}
+	c.cache.SetWriter(func(key, value interface{}) error {
+		typed, _ := value.(V)
+		return writer(key.(K), typed)
+	})
+}
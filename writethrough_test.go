@@ -0,0 +1,124 @@
+package bicache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBiCache_SetWriter(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	backend := make(map[interface{}]interface{})
+	errBackend := errors.New("backend unavailable")
+	cache.SetWriter(func(key, value interface{}) error {
+		if key == "down" {
+			return errBackend
+		}
+		backend[key] = value
+		return nil
+	})
+
+	cache.Set("key1", "value1", 0)
+	if backend["key1"] != "value1" {
+		t.Errorf("SetWriter test failed. Expected: value1 written through, Got: %v", backend["key1"])
+	}
+	if value, found := cache.Get("key1"); !found || value != "value1" {
+		t.Errorf("SetWriter test failed. Expected: value1 cached, Got: %v", value)
+	}
+
+	err := cache.SetWithOptions("down", "value", 0)
+	var writeErr *WriteError
+	if !errors.As(err, &writeErr) || !errors.Is(err, errBackend) || writeErr.Key != "down" {
+		t.Errorf("SetWriter test failed. Expected: a WriteError, Got: %v", err)
+	}
+	if _, found := cache.Get("down"); found {
+		t.Errorf("SetWriter test failed. Expected: failed write not cached")
+	}
+	if metrics := cache.GetMetrics(); metrics.SetError != 1 {
+		t.Errorf("SetWriter test failed. Expected: 1 set error, Got: %v", metrics.SetError)
+	}
+
+	reported := make(chan error, 1)
+	cache.SetErrorHandler(func(op string, key interface{}, err error) {
+		reported <- err
+	})
+	cache.Set("down", "value", 0)
+	select {
+	case err := <-reported:
+		if !errors.Is(err, errBackend) {
+			t.Errorf("SetWriter test failed. Expected: backend error reported, Got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("SetWriter test failed. Expected: Set to report the writer error")
+	}
+
+	cache.SetWriter(nil)
+	cache.Set("down", "value", 0)
+	if _, found := cache.Get("down"); !found {
+		t.Errorf("SetWriter test failed. Expected: write-through disabled")
+	}
+}
+
+func TestTypedBiCache_SetWriter(t *testing.T) {
+	cache := NewTypedBiCache[string, int](10, time.Minute)
+	written := 0
+	cache.SetWriter(func(key string, value int) error {
+		written += value
+		return nil
+	})
+
+	cache.Set("a", 2, 0)
+	cache.Set("b", 3, 0)
+	if written != 5 {
+		t.Errorf("Typed SetWriter test failed. Expected: 5, Got: %v", written)
+	}
+}
+
+func TestBiCache_SetWriterSkipsLoads(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	written := 0
+	cache.SetWriter(func(key, value interface{}) error {
+		written++
+		return nil
+	})
+
+	cache.GetOrLoad("key1", time.Minute, func() (interface{}, error) {
+		return "value1", nil
+	})
+	if written != 0 {
+		t.Errorf("SetWriter loads test failed. Expected: loaded values not written, Got: %v writes", written)
+	}
+}
+
+func TestBiCache_SetWriterSkipsRefusedWrites(t *testing.T) {
+	cache := NewBiCache(10, time.Minute)
+	var written []interface{}
+	cache.SetWriter(func(key, value interface{}) error {
+		written = append(written, value)
+		return nil
+	})
+	errInvalid := errors.New("invalid")
+	cache.SetValidator(func(key, value interface{}) error {
+		if value == "bad" {
+			return errInvalid
+		}
+		return nil
+	})
+
+	if err := cache.SetWithOptions("key1", "bad", 0); err != errInvalid {
+		t.Errorf("SetWriter refused writes test failed. Expected: %v, Got: %v", errInvalid, err)
+	}
+
+	cache.SetWithOptions("locked", "v1", 0, WithReadOnly())
+	var readOnlyErr *ReadOnlyError
+	if err := cache.SetWithOptions("locked", "v2", 0); !errors.As(err, &readOnlyErr) {
+		t.Errorf("SetWriter refused writes test failed. Expected: a ReadOnlyError, Got: %v", err)
+	}
+
+	if len(written) != 1 || written[0] != "v1" {
+		t.Errorf("SetWriter refused writes test failed. Expected: [v1] written, Got: %v", written)
+	}
+	if value, _ := cache.Get("locked"); value != "v1" {
+		t.Errorf("SetWriter refused writes test failed. Expected: v1, Got: %v", value)
+	}
+}